@@ -0,0 +1,79 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	account_types "github.com/fr0ster/go-trading-utils/types/account"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	orders_types "github.com/fr0ster/go-trading-utils/types/orders"
+	"github.com/fr0ster/go-trading-utils/utils"
+)
+
+// InitCreator завантажує баланси активів ф'ючерсного рахунку через REST,
+// Free - доступний баланс, Locked - решта балансу гаманця
+func InitCreator(client *futures.Client) func(a *account_types.Account) types.InitFunction {
+	return func(a *account_types.Account) types.InitFunction {
+		return func() (err error) {
+			account, err := client.NewGetAccountService().Do(context.Background())
+			if err != nil {
+				return
+			}
+			balances := make([]account_types.Balance, 0, len(account.Assets))
+			for _, asset := range account.Assets {
+				wallet := items_types.ValueType(utils.ConvStrToFloat64(asset.WalletBalance))
+				available := items_types.ValueType(utils.ConvStrToFloat64(asset.AvailableBalance))
+				balance := account_types.Balance{Asset: asset.Asset, Free: available, Locked: wallet - available}
+				if asset.UpdateTime > 0 {
+					balance.UpdatedAt = time.UnixMilli(asset.UpdateTime)
+				}
+				balances = append(balances, balance)
+			}
+			a.SetBalances(balances)
+			return
+		}
+	}
+}
+
+// FillRefreshInterval - мінімальний вік знімку, після якого виконання ордера позначає кеш застарілим
+const FillRefreshInterval = 10 * time.Second
+
+// UserDataHandler оновлює баланс гаманця з ACCOUNT_UPDATE.
+// Free між завантаженнями знімку - доступний баланс з останнього знімку плюс зміни гаманця з подій,
+// маржа нових і скасованих ордерів та позицій у ньому не враховується.
+// Виконання ордера змінює маржу позиції, тому позначає кеш застарілим, але не частіше за FillRefreshInterval,
+// решта подій ордерів кеш не змінює.
+func UserDataHandler(a *account_types.Account) futures.WsUserDataHandler {
+	return func(event *futures.WsUserDataEvent) {
+		switch event.Event {
+		case futures.UserDataEventTypeAccountUpdate:
+			at := time.UnixMilli(event.TransactionTime)
+			for _, balance := range event.AccountUpdate.Balances {
+				a.UpdateTotal(balance.Asset, items_types.ValueType(utils.ConvStrToFloat64(balance.Balance)), at)
+			}
+		case futures.UserDataEventTypeOrderTradeUpdate:
+			if event.OrderTradeUpdate.ExecutionType == futures.OrderExecutionTypeTrade {
+				a.InvalidateOlderThan(FillRefreshInterval)
+			}
+		}
+	}
+}
+
+// UserDataHandlerCreator підключає кеш до CallBackCreator стріму ордерів
+func UserDataHandlerCreator(a *account_types.Account) func(*orders_types.Orders) futures.WsUserDataHandler {
+	return func(*orders_types.Orders) futures.WsUserDataHandler {
+		return UserDataHandler(a)
+	}
+}
+
+// ErrHandlerCreator позначає кеш застарілим після помилки стріму, бо події могли бути втрачені
+func ErrHandlerCreator(a *account_types.Account) func(*orders_types.Orders) futures.ErrHandler {
+	return func(*orders_types.Orders) futures.ErrHandler {
+		return func(err error) {
+			a.Invalidate()
+		}
+	}
+}
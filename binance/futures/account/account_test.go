@@ -0,0 +1,44 @@
+package account_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/stretchr/testify/assert"
+
+	futures_account "github.com/fr0ster/go-trading-utils/binance/futures/account"
+	account_types "github.com/fr0ster/go-trading-utils/types/account"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func TestUserDataHandler(t *testing.T) {
+	a := account_types.New(0, nil)
+	a.SetBalances([]account_types.Balance{{Asset: "USDT", Free: 80, Locked: 20, UpdatedAt: time.UnixMilli(1000)}})
+	handler := futures_account.UserDataHandler(a)
+
+	handler(&futures.WsUserDataEvent{
+		Event:           futures.UserDataEventTypeAccountUpdate,
+		TransactionTime: 2000,
+		AccountUpdate: futures.WsAccountUpdate{
+			Balances: []futures.WsBalance{{Asset: "USDT", Balance: "110.5"}},
+		},
+	})
+	assert.False(t, a.IsStale())
+	balance, err := a.GetBalance("USDT")
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.ValueType(90.5), balance.Free)
+	assert.Equal(t, items_types.ValueType(110.5), balance.GetTotal())
+
+	// Нові та скасовані ордери і виконання одразу після знімку не викликають завантаження знімку
+	order := func(executionType futures.OrderExecutionType) *futures.WsUserDataEvent {
+		return &futures.WsUserDataEvent{
+			Event:            futures.UserDataEventTypeOrderTradeUpdate,
+			OrderTradeUpdate: futures.WsOrderTradeUpdate{ExecutionType: executionType},
+		}
+	}
+	handler(order(futures.OrderExecutionTypeNew))
+	handler(order(futures.OrderExecutionTypeCanceled))
+	handler(order(futures.OrderExecutionTypeTrade))
+	assert.False(t, a.IsStale())
+}
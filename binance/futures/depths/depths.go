@@ -2,6 +2,7 @@ package depths
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/adshao/go-binance/v2/futures"
@@ -15,6 +16,7 @@ import (
 func InitCreator(limit depth_types.DepthAPILimit, client *futures.Client) func(d *depth_types.Depths) types.InitFunction {
 	return func(d *depth_types.Depths) types.InitFunction {
 		return func() (err error) {
+			// Запит знімку виконуємо без блокування, події стріму тим часом буферизуються
 			res, err :=
 				client.NewDepthService().
 					Symbol(string(d.Symbol())).
@@ -23,29 +25,85 @@ func InitCreator(limit depth_types.DepthAPILimit, client *futures.Client) func(d
 			if err != nil {
 				return err
 			}
-			d.GetBids().Clear()
-			for _, bid := range res.Bids {
-				price, quantity, _ := bid.Parse()
-				d.GetBids().Update(items_types.NewBid(items_types.PriceType(price), items_types.QuantityType(quantity)))
+			bids, err := parseBids(res.Bids)
+			if err != nil {
+				return err
 			}
-			d.GetAsks().Clear()
-			for _, ask := range res.Asks {
-				price, quantity, _ := ask.Parse()
-				d.GetAsks().Update(items_types.NewAsk(items_types.PriceType(price), items_types.QuantityType(quantity)))
+			asks, err := parseAsks(res.Asks)
+			if err != nil {
+				return err
 			}
-			d.LastUpdateID = res.LastUpdateID
+			d.Lock()         // Locking the depths
+			defer d.Unlock() // Unlocking the depths
+			d.ApplySnapshot(res.LastUpdateID, bids, asks)
 			return nil
 		}
 	}
 }
 
+func parseBids(levels []futures.Bid) (bids []*items_types.Bid, err error) {
+	bids = make([]*items_types.Bid, 0, len(levels))
+	for _, bid := range levels {
+		level, err := items_types.ParsePriceLevel(bid.Price, bid.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		bids = append(bids, items_types.NewBidFromLevel(level))
+	}
+	return
+}
+
+func parseAsks(levels []futures.Ask) (asks []*items_types.Ask, err error) {
+	asks = make([]*items_types.Ask, 0, len(levels))
+	for _, ask := range levels {
+		level, err := items_types.ParsePriceLevel(ask.Price, ask.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		asks = append(asks, items_types.NewAskFromLevel(level))
+	}
+	return
+}
+
+// parseLevels розбирає рівні події в buffer, при достатній ємності буфера без алокацій
+func parseLevels(levels []futures.Bid, buffer []items_types.PriceLevel) ([]items_types.PriceLevel, error) {
+	buffer = buffer[:0]
+	for _, level := range levels {
+		parsed, err := items_types.ParsePriceLevel(level.Price, level.Quantity)
+		if err != nil {
+			return buffer, err
+		}
+		buffer = append(buffer, parsed)
+	}
+	return buffer, nil
+}
+
+// DepthStreamCreator - стрім часткового стакану, див. PartialDepthStreamCreator
 func DepthStreamCreator(
+	levels depth_types.DepthStreamLevel,
+	rate depth_types.DepthStreamRate,
+	handlerCreator func(d *depth_types.Depths) futures.WsDepthHandler,
+	errHandlerCreator func(d *depth_types.Depths) futures.ErrHandler) func(d *depth_types.Depths) types.StreamFunction {
+	return PartialDepthStreamCreator(levels, rate, handlerCreator, errHandlerCreator)
+}
+
+// PartialDepthStreamCreator - стрім топ-N рівнів стакану, для нього потрібен PartialDepthCallBackCreator
+func PartialDepthStreamCreator(
 	levels depth_types.DepthStreamLevel,
 	rate depth_types.DepthStreamRate,
 	handlerCreator func(d *depth_types.Depths) futures.WsDepthHandler,
 	errHandlerCreator func(d *depth_types.Depths) futures.ErrHandler) func(d *depth_types.Depths) types.StreamFunction {
 	return func(d *depth_types.Depths) types.StreamFunction {
+		// Кожне повідомлення - повний знімок, тому StreamStart не вимагає Init
+		d.SetPartialDepth(true)
 		return func() (doneC, stopC chan struct{}, err error) {
+			if err = levels.Validate(); err != nil {
+				return
+			}
+			if err = rate.Validate(); err != nil || rate == depth_types.DepthStreamRate1000ms {
+				err = fmt.Errorf("futures partial depth stream supports only 100ms, 250ms and 500ms, got %v", time.Duration(rate))
+				return
+			}
 			// Запускаємо стрім подій користувача
 			doneC, stopC, err = futures.WsPartialDepthServeWithRate(
 				d.Symbol(),
@@ -62,34 +120,46 @@ func DepthStreamCreator(
 	}
 }
 
-func eventHandlerCreator(d *depth_types.Depths) futures.WsDepthHandler {
-	return func(event *futures.WsDepthEvent) {
-		func() {
-			d.Lock()         // Locking the depths
-			defer d.Unlock() // Unlocking the depths
-			if event.LastUpdateID < d.LastUpdateID {
+func DiffDepthStreamCreator(
+	rate depth_types.DepthStreamRate,
+	handlerCreator func(d *depth_types.Depths) futures.WsDepthHandler,
+	errHandlerCreator func(d *depth_types.Depths) futures.ErrHandler) func(d *depth_types.Depths) types.StreamFunction {
+	return func(d *depth_types.Depths) types.StreamFunction {
+		return func() (doneC, stopC chan struct{}, err error) {
+			// Запускаємо стрім змін стакану
+			doneC, stopC, err = futures.WsDiffDepthServeWithRate(
+				d.Symbol(),
+				time.Duration(rate),
+				handlerCreator(d),
+				errHandlerCreator(d))
+			if err != nil {
 				return
 			}
-			if event.PrevLastUpdateID != int64(d.LastUpdateID) {
-				d.Init()
-			} else if event.PrevLastUpdateID == int64(d.LastUpdateID) {
-				for _, bid := range event.Bids {
-					price, quantity, err := bid.Parse()
-					if err != nil {
-						return
-					}
-					d.GetBids().Update(items_types.NewBid(items_types.PriceType(price), items_types.QuantityType(quantity)))
-				}
-				for _, ask := range event.Asks {
-					price, quantity, err := ask.Parse()
-					if err != nil {
-						return
-					}
-					d.GetAsks().Update(items_types.NewAsk(items_types.PriceType(price), items_types.QuantityType(quantity)))
-				}
-				d.LastUpdateID = event.LastUpdateID
-			}
-		}()
+			d.MarkStreamAsStarted()
+			return
+		}
+	}
+}
+
+func eventHandlerCreator(d *depth_types.Depths) futures.WsDepthHandler {
+	// Стакан не зберігає подію, тому одна подія з буферами рівнів використовується для всього стріму
+	update := &depth_types.DepthUpdate{}
+	return func(event *futures.WsDepthEvent) {
+		var err error
+		if update.Bids, err = parseLevels(event.Bids, update.Bids); err != nil {
+			logrus.Errorf("Futures %v depth event parse error: %v", d.Symbol(), err)
+			return
+		}
+		if update.Asks, err = parseLevels(event.Asks, update.Asks); err != nil {
+			logrus.Errorf("Futures %v depth event parse error: %v", d.Symbol(), err)
+			return
+		}
+		update.FirstUpdateID = event.FirstUpdateID
+		update.LastUpdateID = event.LastUpdateID
+		update.PrevLastUpdateID = event.PrevLastUpdateID
+		d.Lock()         // Locking the depths
+		defer d.Unlock() // Unlocking the depths
+		d.ProcessUpdate(update)
 	}
 }
 
@@ -97,7 +167,7 @@ func CallBackCreator(
 	handlers ...func(d *depth_types.Depths) futures.WsDepthHandler) func(d *depth_types.Depths) futures.WsDepthHandler {
 	return func(d *depth_types.Depths) futures.WsDepthHandler {
 		var stack []futures.WsDepthHandler
-		d.Init()
+		// Знімок стакану завантажується у фоні після першої події стріму
 		standardHandler := eventHandlerCreator(d)
 		for _, handler := range handlers {
 			stack = append(stack, handler(d))
@@ -111,6 +181,42 @@ func CallBackCreator(
 	}
 }
 
+func PartialDepthHandlerCreator(d *depth_types.Depths) futures.WsDepthHandler {
+	return func(event *futures.WsDepthEvent) {
+		bids, err := parseBids(event.Bids)
+		if err != nil {
+			logrus.Errorf("Futures %v partial depth event parse error: %v", d.Symbol(), err)
+			return
+		}
+		asks, err := parseAsks(event.Asks)
+		if err != nil {
+			logrus.Errorf("Futures %v partial depth event parse error: %v", d.Symbol(), err)
+			return
+		}
+		d.Lock()         // Locking the depths
+		defer d.Unlock() // Unlocking the depths
+		// Кожна подія - повний топ-N знімок, тому обидві сторони замінюються
+		d.ApplyPartialDepth(event.LastUpdateID, bids, asks)
+	}
+}
+
+func PartialDepthCallBackCreator(
+	handlers ...func(d *depth_types.Depths) futures.WsDepthHandler) func(d *depth_types.Depths) futures.WsDepthHandler {
+	return func(d *depth_types.Depths) futures.WsDepthHandler {
+		var stack []futures.WsDepthHandler
+		standardHandler := PartialDepthHandlerCreator(d)
+		for _, handler := range handlers {
+			stack = append(stack, handler(d))
+		}
+		return func(event *futures.WsDepthEvent) {
+			standardHandler(event)
+			for _, handler := range stack {
+				handler(event)
+			}
+		}
+	}
+}
+
 func WsErrorHandlerCreator(handlers ...func(*depth_types.Depths) futures.ErrHandler) func(*depth_types.Depths) futures.ErrHandler {
 	return func(d *depth_types.Depths) futures.ErrHandler {
 		var stack []futures.ErrHandler
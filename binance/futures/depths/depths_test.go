@@ -27,8 +27,7 @@ func TestEvents(t *testing.T) {
 	depths := depth_types.New(
 		degree,
 		symbol,
-		futures_depth.DepthStreamCreator(
-			depth_types.DepthStreamLevel20,
+		futures_depth.DiffDepthStreamCreator(
 			depth_types.DepthStreamRate100ms,
 			futures_depth.CallBackCreator(),
 			futures_depth.WsErrorHandlerCreator()),
@@ -0,0 +1,72 @@
+package depths
+
+import (
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/sirupsen/logrus"
+
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+)
+
+func RegistryStreamCreator(
+	handlerCreator func(r *depth_types.Registry) futures.WsDepthHandler,
+	errHandlerCreator func(r *depth_types.Registry) futures.ErrHandler) func(r *depth_types.Registry) depth_types.RegistryStreamFunction {
+	return func(r *depth_types.Registry) depth_types.RegistryStreamFunction {
+		return func(symbols []string) (doneC, stopC chan struct{}, err error) {
+			// Один комбінований стрім на всі передані символи
+			return futures.WsCombinedDiffDepthServe(symbols, handlerCreator(r), errHandlerCreator(r))
+		}
+	}
+}
+
+func registryEventHandlerCreator(r *depth_types.Registry) futures.WsDepthHandler {
+	// Події одного з'єднання обробляються послідовно, тому буфер події спільний для всіх його символів
+	update := &depth_types.DepthUpdate{}
+	return func(event *futures.WsDepthEvent) {
+		var err error
+		if update.Bids, err = parseLevels(event.Bids, update.Bids); err != nil {
+			logrus.Errorf("Futures %v depth event parse error: %v", event.Symbol, err)
+			return
+		}
+		if update.Asks, err = parseLevels(event.Asks, update.Asks); err != nil {
+			logrus.Errorf("Futures %v depth event parse error: %v", event.Symbol, err)
+			return
+		}
+		update.FirstUpdateID = event.FirstUpdateID
+		update.LastUpdateID = event.LastUpdateID
+		update.PrevLastUpdateID = event.PrevLastUpdateID
+		r.ProcessUpdate(event.Symbol, update)
+	}
+}
+
+func RegistryCallBackCreator(
+	handlers ...func(r *depth_types.Registry) futures.WsDepthHandler) func(r *depth_types.Registry) futures.WsDepthHandler {
+	return func(r *depth_types.Registry) futures.WsDepthHandler {
+		var stack []futures.WsDepthHandler
+		standardHandlers := registryEventHandlerCreator(r)
+		for _, handler := range handlers {
+			stack = append(stack, handler(r))
+		}
+		return func(event *futures.WsDepthEvent) {
+			standardHandlers(event)
+			for _, handler := range stack {
+				handler(event)
+			}
+		}
+	}
+}
+
+func RegistryWsErrorHandlerCreator(handlers ...func(r *depth_types.Registry) futures.ErrHandler) func(*depth_types.Registry) futures.ErrHandler {
+	return func(r *depth_types.Registry) futures.ErrHandler {
+		var stack []futures.ErrHandler
+		for _, handler := range handlers {
+			stack = append(stack, handler(r))
+		}
+		return func(err error) {
+			// Перепідключення виконує сам реєстр після завершення стріму
+			logrus.Errorf("Futures Depths registry error: %v", err)
+			for _, handler := range stack {
+				handler(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package margin
+
+import (
+	"context"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/futures"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	margin_types "github.com/fr0ster/go-trading-utils/types/margin"
+	"github.com/fr0ster/go-trading-utils/utils"
+)
+
+// InitCreator завантажує рівні номіналу для symbol, порожній symbol - для всіх пар
+func InitCreator(client *futures.Client, symbol string) func(c *margin_types.Calculator) types.InitFunction {
+	return func(c *margin_types.Calculator) types.InitFunction {
+		return func() (err error) {
+			service := client.NewGetLeverageBracketService()
+			if symbol != "" {
+				service.Symbol(symbol)
+			}
+			res, err := service.Do(context.Background())
+			if err != nil {
+				return
+			}
+			for _, leverageBracket := range res {
+				c.SetBrackets(leverageBracket.Symbol, ConvertBrackets(leverageBracket.Brackets))
+			}
+			return
+		}
+	}
+}
+
+func ConvertBrackets(brackets []futures.Bracket) margin_types.Brackets {
+	res := make([]margin_types.Bracket, 0, len(brackets))
+	for _, bracket := range brackets {
+		res = append(res, margin_types.Bracket{
+			Bracket:          bracket.Bracket,
+			InitialLeverage:  bracket.InitialLeverage,
+			NotionalFloor:    items_types.ValueType(bracket.NotionalFloor),
+			NotionalCap:      items_types.ValueType(bracket.NotionalCap),
+			MaintMarginRatio: bracket.MaintMarginRatio,
+			Cum:              items_types.ValueType(bracket.Cum),
+		})
+	}
+	return margin_types.NewBrackets(res...)
+}
+
+// ConvertPositionRisk перетворює ризик позиції з REST у позицію калькулятора
+func ConvertPositionRisk(risk *futures.PositionRisk) margin_types.Position {
+	marginType := types.CrossMarginType
+	if strings.EqualFold(risk.MarginType, string(types.IsolatedMarginType)) {
+		marginType = types.IsolatedMarginType
+	}
+	return margin_types.Position{
+		Symbol:         risk.Symbol,
+		Quantity:       items_types.QuantityType(utils.ConvStrToFloat64(risk.PositionAmt)),
+		EntryPrice:     items_types.PriceType(utils.ConvStrToFloat64(risk.EntryPrice)),
+		MarkPrice:      items_types.PriceType(utils.ConvStrToFloat64(risk.MarkPrice)),
+		Leverage:       int(utils.ConvStrToFloat64(risk.Leverage)),
+		MarginType:     marginType,
+		IsolatedMargin: items_types.ValueType(utils.ConvStrToFloat64(risk.IsolatedWallet)),
+	}
+}
@@ -78,23 +78,23 @@ func createOrder(
 	// Type	Additional mandatory parameters
 	if orderType == futures.OrderTypeMarket {
 		// MARKET	quantity
-		service = service.Quantity(utils.ConvFloat64ToStr(float64(quantity), quantityRound))
+		service = service.Quantity(quantity.Format(quantityRound))
 	} else if orderType == futures.OrderTypeLimit {
 		// LIMIT	timeInForce, quantity, price
 		service = service.
 			TimeInForce(timeInForce).
-			Quantity(utils.ConvFloat64ToStr(float64(quantity), quantityRound)).
-			Price(utils.ConvFloat64ToStr(float64(price), priceRound))
+			Quantity(quantity.Format(quantityRound)).
+			Price(price.Format(priceRound))
 	} else if orderType == futures.OrderTypeStop || orderType == futures.OrderTypeTakeProfit {
 		// STOP/TAKE_PROFIT	quantity, price, stopPrice
 		service = service.
-			Quantity(utils.ConvFloat64ToStr(float64(quantity), quantityRound)).
-			Price(utils.ConvFloat64ToStr(float64(price), priceRound)).
-			StopPrice(utils.ConvFloat64ToStr(float64(stopPrice), priceRound))
+			Quantity(quantity.Format(quantityRound)).
+			Price(price.Format(priceRound)).
+			StopPrice(stopPrice.Format(priceRound))
 	} else if orderType == futures.OrderTypeStopMarket || orderType == futures.OrderTypeTakeProfitMarket {
 		// STOP_MARKET/TAKE_PROFIT_MARKET	stopPrice
 		service = service.
-			StopPrice(utils.ConvFloat64ToStr(float64(stopPrice), priceRound))
+			StopPrice(stopPrice.Format(priceRound))
 		if closePosition {
 			service = service.ClosePosition(closePosition)
 		}
@@ -102,11 +102,11 @@ func createOrder(
 		// TRAILING_STOP_MARKET	quantity,callbackRate
 		service = service.
 			TimeInForce(futures.TimeInForceTypeGTC).
-			Quantity(utils.ConvFloat64ToStr(float64(quantity), quantityRound)).
+			Quantity(quantity.Format(quantityRound)).
 			CallbackRate(utils.ConvFloat64ToStr(float64(callbackRate), priceRound))
 		if stopPrice != 0 {
 			service = service.
-				ActivationPrice(utils.ConvFloat64ToStr(float64(activationPrice), priceRound))
+				ActivationPrice(activationPrice.Format(priceRound))
 		}
 	}
 	order, err = service.Do(context.Background())
@@ -0,0 +1,151 @@
+package position
+
+import (
+	"context"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	orders_types "github.com/fr0ster/go-trading-utils/types/orders"
+	position_types "github.com/fr0ster/go-trading-utils/types/position"
+	"github.com/fr0ster/go-trading-utils/utils"
+)
+
+const (
+	// Найбільша кількість запитів історії угод при перебудові позиції
+	maxHistoryRequests = 30
+	// Найбільше вікно startTime/endTime, яке приймає userTrades
+	historyWindow = 7 * 24 * time.Hour
+)
+
+// InitCreator перебудовує позицію від поточної позиції з ризику позиції.
+// Спочатку завантажуються останні limit угод рахунку, далі історія читається назад вікнами по сім діб
+// до найстаршої відомої угоди, поки позиція в історії не стане закритою, але не більше maxHistoryRequests запитів.
+// Сторінки рахуються за часом, бо ListAccountTradeService в go-binance v2.6.0 передає fromId як fromID.
+// Позиція, відкрита до завантажених угод, враховується як початкова за ціною входу EntryPrice.
+func InitCreator(client *futures.Client, limit int) func(p *position_types.Position) types.InitFunction {
+	return func(p *position_types.Position) types.InitFunction {
+		return func() (err error) {
+			risks, err := client.NewGetPositionRiskService().Symbol(p.GetSymbol()).Do(context.Background())
+			if err != nil {
+				return
+			}
+			var (
+				quantity   items_types.QuantityType
+				entryPrice items_types.PriceType
+			)
+			for _, risk := range risks {
+				if amount := items_types.QuantityType(utils.ConvStrToFloat64(risk.PositionAmt)); amount != 0 {
+					quantity += amount
+					entryPrice = items_types.PriceType(utils.ConvStrToFloat64(risk.EntryPrice))
+				}
+			}
+			trades, err := client.NewListAccountTradeService().Symbol(p.GetSymbol()).Limit(limit).Do(context.Background())
+			if err != nil {
+				return
+			}
+			fills := make([]position_types.Fill, 0, len(trades))
+			for _, trade := range trades {
+				fills = append(fills, ConvertTrade(trade))
+			}
+			requests := 1
+			if _, flat := p.Opening(quantity, fills); !flat && len(fills) > 0 && len(trades) >= limit {
+				end := fills[0].Time.Add(-time.Millisecond)
+				for requests < maxHistoryRequests {
+					start := end.Add(-historyWindow + time.Millisecond)
+					var window []position_types.Fill
+					if window, err = listWindow(client, p.GetSymbol(), limit, start, end, &requests); err != nil {
+						return
+					}
+					fills = append(window, fills...)
+					if _, flat = p.Opening(quantity, fills); flat {
+						break
+					}
+					end = start.Add(-time.Millisecond)
+				}
+			}
+			p.RebuildFrom(quantity, entryPrice, fills)
+			return
+		}
+	}
+}
+
+// listWindow завантажує угоди з start по end включно, вікно довше за limit угод читається кількома запитами
+func listWindow(
+	client *futures.Client,
+	symbol string,
+	limit int,
+	start, end time.Time,
+	requests *int) (fills []position_types.Fill, err error) {
+	from := start
+	for *requests < maxHistoryRequests {
+		trades, err := client.NewListAccountTradeService().
+			Symbol(symbol).
+			StartTime(from.UnixMilli()).
+			EndTime(end.UnixMilli()).
+			Limit(limit).
+			Do(context.Background())
+		*requests++
+		if err != nil {
+			return nil, err
+		}
+		added := 0
+		for _, trade := range trades {
+			// Наступна сторінка починається з часу останньої угоди, повтори пропускаються
+			if len(fills) == 0 || trade.ID > fills[len(fills)-1].ID {
+				fills = append(fills, ConvertTrade(trade))
+				added++
+			}
+		}
+		if len(trades) < limit || added == 0 {
+			break
+		}
+		from = time.UnixMilli(trades[len(trades)-1].Time)
+	}
+	return
+}
+
+// ConvertTrade перетворює угоду рахунку у виконання
+func ConvertTrade(trade *futures.AccountTrade) position_types.Fill {
+	return position_types.Fill{
+		ID:              trade.ID,
+		OrderID:         trade.OrderID,
+		Side:            types.OrderSide(trade.Side),
+		Price:           items_types.PriceType(utils.ConvStrToFloat64(trade.Price)),
+		Quantity:        items_types.QuantityType(utils.ConvStrToFloat64(trade.Quantity)),
+		Commission:      items_types.ValueType(utils.ConvStrToFloat64(trade.Commission)),
+		CommissionAsset: trade.CommissionAsset,
+		Time:            time.UnixMilli(trade.Time),
+	}
+}
+
+// UserDataHandler враховує виконання з ORDER_TRADE_UPDATE з типом TRADE для пари позиції
+func UserDataHandler(p *position_types.Position) futures.WsUserDataHandler {
+	return func(event *futures.WsUserDataEvent) {
+		update := event.OrderTradeUpdate
+		if event.Event != futures.UserDataEventTypeOrderTradeUpdate ||
+			update.ExecutionType != futures.OrderExecutionTypeTrade ||
+			update.Symbol != p.GetSymbol() {
+			return
+		}
+		p.AddFill(position_types.Fill{
+			ID:              update.TradeID,
+			OrderID:         update.ID,
+			Side:            types.OrderSide(update.Side),
+			Price:           items_types.PriceType(utils.ConvStrToFloat64(update.LastFilledPrice)),
+			Quantity:        items_types.QuantityType(utils.ConvStrToFloat64(update.LastFilledQty)),
+			Commission:      items_types.ValueType(utils.ConvStrToFloat64(update.Commission)),
+			CommissionAsset: update.CommissionAsset,
+			Time:            time.UnixMilli(update.TradeTime),
+		})
+	}
+}
+
+// UserDataHandlerCreator підключає облік позиції до CallBackCreator стріму ордерів
+func UserDataHandlerCreator(p *position_types.Position) func(*orders_types.Orders) futures.WsUserDataHandler {
+	return func(*orders_types.Orders) futures.WsUserDataHandler {
+		return UserDataHandler(p)
+	}
+}
@@ -0,0 +1,125 @@
+package position_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/stretchr/testify/assert"
+
+	futures_position "github.com/fr0ster/go-trading-utils/binance/futures/position"
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	position_types "github.com/fr0ster/go-trading-utils/types/position"
+)
+
+func TestUserDataHandler(t *testing.T) {
+	p := position_types.New("BTCUSDT", "BTC", "USDT", false, nil)
+	p.Rebuild([]position_types.Fill{futures_position.ConvertTrade(&futures.AccountTrade{
+		ID: 1, Side: futures.SideTypeSell, Price: "100", Quantity: "2",
+		Commission: "0.08", CommissionAsset: "USDT", Symbol: "BTCUSDT", Time: 1000,
+	})})
+	handler := futures_position.UserDataHandler(p)
+	trade := func(id int64, symbol string, executionType futures.OrderExecutionType) *futures.WsUserDataEvent {
+		return &futures.WsUserDataEvent{
+			Event: futures.UserDataEventTypeOrderTradeUpdate,
+			OrderTradeUpdate: futures.WsOrderTradeUpdate{
+				Symbol: symbol, Side: futures.SideTypeBuy, ExecutionType: executionType,
+				TradeID: id, LastFilledPrice: "90", LastFilledQty: "1",
+				Commission: "0.036", CommissionAsset: "USDT", TradeTime: 2000,
+			},
+		}
+	}
+	handler(trade(2, "BTCUSDT", futures.OrderExecutionTypeTrade))
+	handler(trade(2, "BTCUSDT", futures.OrderExecutionTypeTrade))
+	handler(trade(3, "ETHUSDT", futures.OrderExecutionTypeTrade))
+	handler(trade(4, "BTCUSDT", futures.OrderExecutionTypeNew))
+
+	assert.Equal(t, items_types.QuantityType(-1), p.GetQuantity())
+	assert.Equal(t, items_types.PriceType(100), p.GetEntryPrice())
+	assert.Equal(t, items_types.ValueType(10), p.GetRealizedPnL())
+	assert.InDelta(t, 0.116, float64(p.GetFees()["USDT"]), 1e-9)
+}
+
+func TestInitCreatorSeedsFromPositionRisk(t *testing.T) {
+	// Позицію long 1 за 100 відкрито до останніх двох угод
+	trades := []*futures.AccountTrade{
+		{ID: 4, Side: futures.SideTypeSell, Price: "100", Quantity: "3", Time: 4000},
+		{ID: 5, Side: futures.SideTypeBuy, Price: "90", Quantity: "1", Time: 5000},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fapi/v2/positionRisk":
+			_ = json.NewEncoder(w).Encode([]futures.PositionRisk{{Symbol: "BTCUSDT", PositionAmt: "-1", EntryPrice: "100"}})
+		case "/fapi/v1/userTrades":
+			_ = json.NewEncoder(w).Encode(listTrades(t, trades, r))
+		}
+	}))
+	defer server.Close()
+	client := futures.NewClient("", "")
+	client.BaseURL = server.URL
+
+	// Старших угод немає, позиція до них береться з ризику позиції
+	p := position_types.New("BTCUSDT", "BTC", "USDT", false, futures_position.InitCreator(client, 2))
+	assert.NoError(t, p.Refresh())
+	assert.Equal(t, items_types.QuantityType(-1), p.GetQuantity())
+	assert.Equal(t, items_types.PriceType(100), p.GetEntryPrice())
+	assert.Equal(t, items_types.ValueType(10), p.GetRealizedPnL())
+	assert.Equal(t, types.SideTypeSell, p.GetSide())
+}
+
+// listTrades відповідає як userTrades: останні limit угод або угоди вікна startTime/endTime
+func listTrades(t *testing.T, trades []*futures.AccountTrade, r *http.Request) (page []*futures.AccountTrade) {
+	query := r.URL.Query()
+	assert.Empty(t, query.Get("fromID"))
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	if query.Get("startTime") == "" {
+		return trades[max(len(trades)-limit, 0):]
+	}
+	startTime, _ := strconv.ParseInt(query.Get("startTime"), 10, 64)
+	endTime, _ := strconv.ParseInt(query.Get("endTime"), 10, 64)
+	assert.Less(t, endTime-startTime, int64(7*24*time.Hour/time.Millisecond))
+	for _, trade := range trades {
+		if trade.Time >= startTime && trade.Time <= endTime && len(page) < limit {
+			page = append(page, trade)
+		}
+	}
+	return
+}
+
+func TestInitCreatorPaginatesUntilFlat(t *testing.T) {
+	day := int64(24 * time.Hour / time.Millisecond)
+	// Позицію відкрито за 9 діб до останніх угод, вікно з її відкриттям має більше limit угод
+	trades := []*futures.AccountTrade{
+		{ID: 1000, Side: futures.SideTypeBuy, Price: "100", Quantity: "1", Time: 20 * day},
+		{ID: 5000, Side: futures.SideTypeBuy, Price: "110", Quantity: "1", Time: 20*day + 1000},
+		{ID: 9000, Side: futures.SideTypeSell, Price: "120", Quantity: "1", Time: 20*day + 2000},
+		{ID: 20000, Side: futures.SideTypeBuy, Price: "100", Quantity: "1", Time: 29 * day},
+		{ID: 30000, Side: futures.SideTypeBuy, Price: "90", Quantity: "1", Time: 29*day + 1000},
+	}
+	pages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fapi/v2/positionRisk":
+			_ = json.NewEncoder(w).Encode([]futures.PositionRisk{{Symbol: "BTCUSDT", PositionAmt: "3", EntryPrice: "1"}})
+		case "/fapi/v1/userTrades":
+			pages++
+			_ = json.NewEncoder(w).Encode(listTrades(t, trades, r))
+		}
+	}))
+	defer server.Close()
+	client := futures.NewClient("", "")
+	client.BaseURL = server.URL
+
+	p := position_types.New("BTCUSDT", "BTC", "USDT", false, futures_position.InitCreator(client, 2))
+	assert.NoError(t, p.Refresh())
+	// Остання сторінка, порожнє вікно та три сторінки вікна з відкриттям позиції
+	assert.Equal(t, 5, pages)
+	assert.Equal(t, items_types.QuantityType(3), p.GetQuantity())
+	assert.InDelta(t, 295.0/3, float64(p.GetEntryPrice()), 1e-9)
+	assert.InDelta(t, 15, float64(p.GetRealizedPnL()), 1e-9)
+}
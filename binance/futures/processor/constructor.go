@@ -2,19 +2,27 @@ package processor
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/adshao/go-binance/v2/futures"
 	"github.com/fr0ster/go-trading-utils/types"
 	"github.com/fr0ster/go-trading-utils/utils"
 	"github.com/sirupsen/logrus"
 
+	futures_account "github.com/fr0ster/go-trading-utils/binance/futures/account"
 	futures_exchangeinfo "github.com/fr0ster/go-trading-utils/binance/futures/exchangeinfo"
 
+	account_types "github.com/fr0ster/go-trading-utils/types/account"
 	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
 	exchangeinfo_types "github.com/fr0ster/go-trading-utils/types/exchangeinfo"
 	processor_types "github.com/fr0ster/go-trading-utils/types/processor"
 )
 
+// DefaultAccountMaxAge - вік знімку балансів, після якого кеш завантажується знову,
+// навіть якщо стрім користувача не підключено
+const DefaultAccountMaxAge = 1 * time.Minute
+
 func New(
 	client *futures.Client,
 	degree int,
@@ -38,28 +46,20 @@ func New(
 	}
 	exchange := exchangeinfo_types.New(futures_exchangeinfo.InitCreator(client, degree, symbol))
 	symbolInfo := exchange.GetSymbol(symbol)
-	baseSymbol := string(exchange.GetSymbol(symbol).GetBaseSymbol())
-	targetSymbol := string(exchange.GetSymbol(symbol).GetTargetSymbol())
+	if symbolInfo == nil {
+		return nil, fmt.Errorf("symbol %v not found", symbol)
+	}
+	// Баланси читаються з кешу, обробник стріму користувача підключається до pairProcessor.GetAccount()
+	account := account_types.New(DefaultAccountMaxAge, futures_account.InitCreator(client))
+	getBaseBalance, getTargetBalance, getFreeBalance, getLockedBalance := processor_types.AccountBalanceGetters(account, symbolInfo)
 	pairProcessor, err = processor_types.New(
-		quit,       // quit
-		symbol,     // pair
-		symbolInfo, // symbolInfo
-		getBaseBalance(
-			client,     // client
-			baseSymbol, // symbol
-		), // getBaseBalance
-		getTargetBalance(
-			client,       // client
-			targetSymbol, // symbol
-		), // getTargetBalance
-		getFreeBalance(
-			client,     // client
-			baseSymbol, // symbol
-		), // getFreeBalance
-		getLockedBalance(
-			client,     // client
-			baseSymbol, // symbol
-		), // getLockedBalance
+		quit,                            // quit
+		symbol,                          // pair
+		symbolInfo,                      // symbolInfo
+		getBaseBalance,                  // getBaseBalance
+		getTargetBalance,                // getTargetBalance
+		getFreeBalance,                  // getFreeBalance
+		getLockedBalance,                // getLockedBalance
 		getCurrentPrice(client, symbol), // getCurrentPrice
 		getPositionRisk(client),         // getPositionRisk
 		func() int {
@@ -91,69 +91,37 @@ func New(
 			return callbackRate
 		}, // getCallbackRate
 		debug)
+	pairProcessor.SetAccount(account)
 	return
 } // New
 
-func getBaseBalance(client *futures.Client, symbol string) processor_types.GetBaseBalanceFunction {
-	return func() items_types.ValueType {
-		account, err := client.NewGetAccountService().Do(context.Background())
-		if err != nil {
-			logrus.Errorf("Can't get account: %v", err)
-			return 0
-		}
-		for _, asset := range account.Assets {
-			if asset.Asset == symbol {
-				return items_types.ValueType(utils.ConvStrToFloat64(asset.WalletBalance))
-			}
-		}
-		return 0.0
-	}
-} // getBaseBalance
-func getTargetBalance(client *futures.Client, symbol string) processor_types.GetTargetBalanceFunction {
-	return func() items_types.QuantityType {
-		account, err := client.NewGetAccountService().Do(context.Background())
-		if err != nil {
-			logrus.Errorf("Can't get account: %v", err)
-			return 0
-		}
-		for _, asset := range account.Assets {
-			if asset.Asset == symbol {
-				return items_types.QuantityType(utils.ConvStrToFloat64(asset.WalletBalance))
-			}
-		}
-		return 0.0
-	}
-} // getTargetBalance
-func getFreeBalance(client *futures.Client, symbol string) processor_types.GetFreeBalanceFunction {
-	return func() items_types.ValueType {
-		account, err := client.NewGetAccountService().Do(context.Background())
-		if err != nil {
-			logrus.Errorf("Can't get account: %v", err)
-			return 0
-		}
-		for _, asset := range account.Assets {
-			if asset.Asset == symbol {
-				return items_types.ValueType(utils.ConvStrToFloat64(asset.AvailableBalance))
-			}
-		}
-		return 0.0
+// NewFromConfig створює процесор пари з config, геттери балансів, ціни, ризику позиції
+// та встановлення плеча і маржі беруться з client, options застосовуються після них і можуть їх перевизначити.
+// Баланси читаються з власного кешу рахунку, спільний кеш передається через processor_types.WithAccount.
+func NewFromConfig(
+	client *futures.Client,
+	config processor_types.Config,
+	options ...processor_types.Option,
+) (pairProcessor *processor_types.Processor, err error) {
+	config.SetDefaults()
+	if err = config.Validate(); err != nil {
+		return nil, fmt.Errorf("processor %v config: %w", config.Symbol, err)
 	}
-} // getFreeBalance
-func getLockedBalance(client *futures.Client, symbol string) processor_types.GetLockedBalanceFunction {
-	return func() items_types.ValueType {
-		account, err := client.NewGetAccountService().Do(context.Background())
-		if err != nil {
-			logrus.Errorf("Can't get account: %v", err)
-			return 0
-		}
-		for _, asset := range account.Assets {
-			if asset.Asset == symbol {
-				return items_types.ValueType(utils.ConvStrToFloat64(asset.WalletBalance) - utils.ConvStrToFloat64(asset.AvailableBalance))
-			}
-		}
-		return 0.0
+	exchange := exchangeinfo_types.New(futures_exchangeinfo.InitCreator(client, config.Degree, config.Symbol))
+	symbolInfo := exchange.GetSymbol(config.Symbol)
+	if symbolInfo == nil {
+		return nil, fmt.Errorf("symbol %v not found", config.Symbol)
 	}
-} // getLockedBalance
+	return processor_types.NewFromConfig(config, symbolInfo, append([]processor_types.Option{
+		processor_types.WithAccount(account_types.New(DefaultAccountMaxAge, futures_account.InitCreator(client))),
+		processor_types.WithCurrentPrice(getCurrentPrice(client, config.Symbol)),
+		processor_types.WithPositionRisk(getPositionRisk(client)),
+		processor_types.WithSetLeverage(setLeverage(client)),
+		processor_types.WithSetMarginType(setMarginType(client)),
+		processor_types.WithSetPositionMargin(setPositionMargin(client)),
+	}, options...)...)
+} // NewFromConfig
+
 func getCurrentPrice(client *futures.Client, symbol string) processor_types.GetCurrentPriceFunction {
 	return func() items_types.PriceType {
 		price, err := client.NewListPricesService().Symbol(symbol).Do(context.Background())
@@ -0,0 +1,39 @@
+package processor_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/stretchr/testify/assert"
+
+	futures_processor "github.com/fr0ster/go-trading-utils/binance/futures/processor"
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func TestNewReadsBalancesBeforeSetup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fapi/v1/exchangeInfo":
+			_, _ = w.Write([]byte(`{"symbols":[{"symbol":"BTCUSDT","baseAsset":"BTC","quoteAsset":"USDT","filters":[
+				{"filterType":"PRICE_FILTER","tickSize":"0.1","maxPrice":"1000000","minPrice":"0.1"},
+				{"filterType":"LOT_SIZE","stepSize":"0.001","maxQty":"1000","minQty":"0.001"},
+				{"filterType":"MIN_NOTIONAL","notional":"5"}]}]}`))
+		case "/fapi/v2/account":
+			_, _ = w.Write([]byte(`{"assets":[{"asset":"USDT","walletBalance":"1200","availableBalance":"1000"}]}`))
+		case "/fapi/v2/ticker/price":
+			_, _ = w.Write([]byte(`[{"symbol":"BTCUSDT","price":"60000"}]`))
+		}
+	}))
+	defer server.Close()
+	client := futures.NewClient("", "")
+	client.BaseURL = server.URL
+
+	pp, err := futures_processor.New(client, 3, "BTCUSDT", 500, 10, 10, 1, 1, 10, types.CrossMarginType, 0.1, true)
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.ValueType(1000), pp.GetFreeBalance())
+	assert.Equal(t, items_types.ValueType(50), pp.GetLimitOnTransaction())
+	assert.NotNil(t, pp.GetAccount())
+}
@@ -0,0 +1,74 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	account_types "github.com/fr0ster/go-trading-utils/types/account"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	orders_types "github.com/fr0ster/go-trading-utils/types/orders"
+	"github.com/fr0ster/go-trading-utils/utils"
+)
+
+// InitCreator завантажує баланси спотового рахунку через REST
+func InitCreator(client *binance.Client) func(a *account_types.Account) types.InitFunction {
+	return func(a *account_types.Account) types.InitFunction {
+		return func() (err error) {
+			account, err := client.NewGetAccountService().Do(context.Background())
+			if err != nil {
+				return
+			}
+			var updatedAt time.Time
+			if account.UpdateTime > 0 {
+				updatedAt = time.UnixMilli(int64(account.UpdateTime))
+			}
+			balances := make([]account_types.Balance, 0, len(account.Balances))
+			for _, balance := range account.Balances {
+				balances = append(balances, account_types.Balance{
+					Asset:     balance.Asset,
+					Free:      items_types.ValueType(utils.ConvStrToFloat64(balance.Free)),
+					Locked:    items_types.ValueType(utils.ConvStrToFloat64(balance.Locked)),
+					UpdatedAt: updatedAt,
+				})
+			}
+			a.SetBalances(balances)
+			return
+		}
+	}
+}
+
+// UserDataHandler оновлює баланси з outboundAccountPosition.
+// balanceUpdate не обробляється, бо за ним приходить outboundAccountPosition з новим балансом.
+func UserDataHandler(a *account_types.Account) binance.WsUserDataHandler {
+	return func(event *binance.WsUserDataEvent) {
+		if event.Event != binance.UserDataEventTypeOutboundAccountPosition {
+			return
+		}
+		at := time.UnixMilli(event.AccountUpdate.AccountUpdateTime)
+		for _, balance := range event.AccountUpdate.WsAccountUpdates {
+			a.UpdateBalance(balance.Asset,
+				items_types.ValueType(utils.ConvStrToFloat64(balance.Free)),
+				items_types.ValueType(utils.ConvStrToFloat64(balance.Locked)),
+				at)
+		}
+	}
+}
+
+// UserDataHandlerCreator підключає кеш до CallBackCreator стріму ордерів
+func UserDataHandlerCreator(a *account_types.Account) func(*orders_types.Orders) binance.WsUserDataHandler {
+	return func(*orders_types.Orders) binance.WsUserDataHandler {
+		return UserDataHandler(a)
+	}
+}
+
+// ErrHandlerCreator позначає кеш застарілим після помилки стріму, бо події могли бути втрачені
+func ErrHandlerCreator(a *account_types.Account) func(*orders_types.Orders) binance.ErrHandler {
+	return func(*orders_types.Orders) binance.ErrHandler {
+		return func(err error) {
+			a.Invalidate()
+		}
+	}
+}
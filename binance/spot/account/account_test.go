@@ -0,0 +1,37 @@
+package account_test
+
+import (
+	"testing"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/stretchr/testify/assert"
+
+	spot_account "github.com/fr0ster/go-trading-utils/binance/spot/account"
+	account_types "github.com/fr0ster/go-trading-utils/types/account"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func TestUserDataHandler(t *testing.T) {
+	a := account_types.New(0, nil)
+	a.SetBalances([]account_types.Balance{{Asset: "BTC", Free: 1}})
+	handler := spot_account.UserDataHandler(a)
+
+	handler(&binance.WsUserDataEvent{
+		Event:         binance.UserDataEventTypeBalanceUpdate,
+		BalanceUpdate: binance.WsBalanceUpdate{Asset: "BTC", Change: "5"},
+	})
+	handler(&binance.WsUserDataEvent{
+		Event: binance.UserDataEventTypeOutboundAccountPosition,
+		AccountUpdate: binance.WsAccountUpdateList{
+			AccountUpdateTime: 4102444800000,
+			WsAccountUpdates:  []binance.WsAccountUpdate{{Asset: "BTC", Free: "0.75", Locked: "0.25"}},
+		},
+	})
+	balance, err := a.GetBalance("BTC")
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.ValueType(0.75), balance.Free)
+	assert.Equal(t, items_types.ValueType(0.25), balance.Locked)
+
+	spot_account.ErrHandlerCreator(a)(nil)(nil)
+	assert.True(t, a.IsStale())
+}
@@ -2,6 +2,9 @@ package depths
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"time"
 
 	"github.com/adshao/go-binance/v2"
 
@@ -15,6 +18,7 @@ import (
 func InitCreator(limit depths_types.DepthAPILimit, client *binance.Client) func(d *depths_types.Depths) types.InitFunction {
 	return func(d *depths_types.Depths) types.InitFunction {
 		return func() (err error) {
+			// Запит знімку виконуємо без блокування, події стріму тим часом буферизуються
 			res, err :=
 				client.NewDepthService().
 					Symbol(string(d.Symbol())).
@@ -23,22 +27,59 @@ func InitCreator(limit depths_types.DepthAPILimit, client *binance.Client) func(
 			if err != nil {
 				return err
 			}
-			d.GetBids().Clear()
-			for _, bid := range res.Bids {
-				price, quantity, _ := bid.Parse()
-				d.GetBids().Update(items_types.NewBid(items_types.PriceType(price), items_types.QuantityType(quantity)))
+			bids, err := parseBids(res.Bids)
+			if err != nil {
+				return err
 			}
-			d.GetAsks().Clear()
-			for _, ask := range res.Asks {
-				price, quantity, _ := ask.Parse()
-				d.GetAsks().Update(items_types.NewAsk(items_types.PriceType(price), items_types.QuantityType(quantity)))
+			asks, err := parseAsks(res.Asks)
+			if err != nil {
+				return err
 			}
-			d.LastUpdateID = res.LastUpdateID
+			d.Lock()         // Locking the depths
+			defer d.Unlock() // Unlocking the depths
+			d.ApplySnapshot(res.LastUpdateID, bids, asks)
 			return nil
 		}
 	}
 }
 
+func parseBids(levels []binance.Bid) (bids []*items_types.Bid, err error) {
+	bids = make([]*items_types.Bid, 0, len(levels))
+	for _, bid := range levels {
+		level, err := items_types.ParsePriceLevel(bid.Price, bid.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		bids = append(bids, items_types.NewBidFromLevel(level))
+	}
+	return
+}
+
+func parseAsks(levels []binance.Ask) (asks []*items_types.Ask, err error) {
+	asks = make([]*items_types.Ask, 0, len(levels))
+	for _, ask := range levels {
+		level, err := items_types.ParsePriceLevel(ask.Price, ask.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		asks = append(asks, items_types.NewAskFromLevel(level))
+	}
+	return
+}
+
+// parseLevels розбирає рівні події в buffer, при достатній ємності буфера без алокацій
+func parseLevels(levels []binance.Bid, buffer []items_types.PriceLevel) ([]items_types.PriceLevel, error) {
+	buffer = buffer[:0]
+	for _, level := range levels {
+		parsed, err := items_types.ParsePriceLevel(level.Price, level.Quantity)
+		if err != nil {
+			return buffer, err
+		}
+		buffer = append(buffer, parsed)
+	}
+	return buffer, nil
+}
+
 func DepthStreamCreator(
 	handlerCreator func(d *depths_types.Depths) binance.WsDepthHandler,
 	errHandlerCreator func(d *depths_types.Depths) binance.ErrHandler) func(d *depths_types.Depths) types.StreamFunction {
@@ -56,42 +97,31 @@ func DepthStreamCreator(
 }
 
 func eventHandlerCreator(d *depths_types.Depths) binance.WsDepthHandler {
+	// Стакан не зберігає подію, тому одна подія з буферами рівнів використовується для всього стріму
+	update := &depths_types.DepthUpdate{}
 	return func(event *binance.WsDepthEvent) {
-		func() {
-			d.Lock()         // Locking the depths
-			defer d.Unlock() // Unlocking the depths
-			if event.LastUpdateID < d.LastUpdateID {
-				return
-			}
-			if event.LastUpdateID != int64(d.LastUpdateID)+1 {
-				d.Init()
-			} else if event.LastUpdateID == int64(d.LastUpdateID)+1 {
-				for _, bid := range event.Bids {
-					price, quantity, err := bid.Parse()
-					if err != nil {
-						return
-					}
-					d.GetBids().Update(items_types.NewBid(items_types.PriceType(price), items_types.QuantityType(quantity)))
-				}
-				for _, ask := range event.Asks {
-					price, quantity, err := ask.Parse()
-					if err != nil {
-						return
-					}
-					d.GetAsks().Update(items_types.NewAsk(items_types.PriceType(price), items_types.QuantityType(quantity)))
-				}
-				d.LastUpdateID = event.LastUpdateID
-			}
-		}()
+		var err error
+		if update.Bids, err = parseLevels(event.Bids, update.Bids); err != nil {
+			logrus.Errorf("Spot %v depth event parse error: %v", d.Symbol(), err)
+			return
+		}
+		if update.Asks, err = parseLevels(event.Asks, update.Asks); err != nil {
+			logrus.Errorf("Spot %v depth event parse error: %v", d.Symbol(), err)
+			return
+		}
+		update.FirstUpdateID = event.FirstUpdateID
+		update.LastUpdateID = event.LastUpdateID
+		d.Lock()         // Locking the depths
+		defer d.Unlock() // Unlocking the depths
+		d.ProcessUpdate(update)
 	}
 }
 
 func CallBackCreator(
 	handlers ...func(d *depths_types.Depths) binance.WsDepthHandler) func(d *depths_types.Depths) binance.WsDepthHandler {
 	return func(d *depths_types.Depths) binance.WsDepthHandler {
-		d.Init()
 		var stack []binance.WsDepthHandler
-		d.Init()
+		// Знімок стакану завантажується у фоні після першої події стріму
 		standardHandlers := eventHandlerCreator(d)
 		for _, handler := range handlers {
 			stack = append(stack, handler(d))
@@ -107,12 +137,25 @@ func CallBackCreator(
 
 func PartialDepthStreamCreator(
 	levels depths_types.DepthStreamLevel,
+	rate depths_types.DepthStreamRate,
 	handlerCreator func(d *depths_types.Depths) binance.WsPartialDepthHandler,
 	errHandlerCreator func(d *depths_types.Depths) binance.ErrHandler) func(d *depths_types.Depths) types.StreamFunction {
 	return func(d *depths_types.Depths) types.StreamFunction {
+		// Кожне повідомлення - повний знімок, тому StreamStart не вимагає Init
+		d.SetPartialDepth(true)
 		return func() (doneC, stopC chan struct{}, err error) {
-			// Запускаємо стрім подій користувача
-			doneC, stopC, err = binance.WsPartialDepthServe100Ms(d.Symbol(), string(rune(levels)), handlerCreator(d), errHandlerCreator(d))
+			if err = levels.Validate(); err != nil {
+				return
+			}
+			// Спотовий стрім часткового стакану підтримує тільки 100ms та 1000ms
+			switch rate {
+			case depths_types.DepthStreamRate100ms:
+				doneC, stopC, err = binance.WsPartialDepthServe100Ms(d.Symbol(), strconv.Itoa(int(levels)), handlerCreator(d), errHandlerCreator(d))
+			case depths_types.DepthStreamRate1000ms:
+				doneC, stopC, err = binance.WsPartialDepthServe(d.Symbol(), strconv.Itoa(int(levels)), handlerCreator(d), errHandlerCreator(d))
+			default:
+				err = fmt.Errorf("spot partial depth stream supports only 100ms and 1000ms, got %v", time.Duration(rate))
+			}
 			if err != nil {
 				return
 			}
@@ -124,33 +167,20 @@ func PartialDepthStreamCreator(
 
 func PartialDepthHandlerCreator(d *depths_types.Depths) binance.WsPartialDepthHandler {
 	return func(event *binance.WsPartialDepthEvent) {
-		func() {
-			d.Lock()         // Locking the depths
-			defer d.Unlock() // Unlocking the depths
-			if event.LastUpdateID < d.LastUpdateID {
-				return
-			}
-			if event.LastUpdateID != int64(d.LastUpdateID)+1 {
-				d.Init()
-			} else if event.LastUpdateID == int64(d.LastUpdateID)+1 {
-				logrus.Debugf("Spot %v depth update event received", d.Symbol())
-				for _, bid := range event.Bids {
-					price, quantity, err := bid.Parse()
-					if err != nil {
-						return
-					}
-					d.GetBids().Update(items_types.NewBid(items_types.PriceType(price), items_types.QuantityType(quantity)))
-				}
-				for _, ask := range event.Asks {
-					price, quantity, err := ask.Parse()
-					if err != nil {
-						return
-					}
-					d.GetAsks().Update(items_types.NewAsk(items_types.PriceType(price), items_types.QuantityType(quantity)))
-				}
-				d.LastUpdateID = event.LastUpdateID
-			}
-		}()
+		bids, err := parseBids(event.Bids)
+		if err != nil {
+			logrus.Errorf("Spot %v partial depth event parse error: %v", d.Symbol(), err)
+			return
+		}
+		asks, err := parseAsks(event.Asks)
+		if err != nil {
+			logrus.Errorf("Spot %v partial depth event parse error: %v", d.Symbol(), err)
+			return
+		}
+		d.Lock()         // Locking the depths
+		defer d.Unlock() // Unlocking the depths
+		// Кожна подія - повний топ-N знімок, тому обидві сторони замінюються
+		d.ApplyPartialDepth(event.LastUpdateID, bids, asks)
 	}
 }
 
@@ -158,7 +188,6 @@ func PartialDepthCallBackCreator(
 	handlers ...func(d *depths_types.Depths) binance.WsPartialDepthHandler) func(d *depths_types.Depths) binance.WsPartialDepthHandler {
 	return func(d *depths_types.Depths) binance.WsPartialDepthHandler {
 		var stack []binance.WsPartialDepthHandler
-		d.Init()
 		standardHandlers := PartialDepthHandlerCreator(d)
 		for _, handler := range handlers {
 			stack = append(stack, handler(d))
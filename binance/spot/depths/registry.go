@@ -0,0 +1,71 @@
+package depths
+
+import (
+	"github.com/adshao/go-binance/v2"
+	"github.com/sirupsen/logrus"
+
+	depths_types "github.com/fr0ster/go-trading-utils/types/depths"
+)
+
+func RegistryStreamCreator(
+	handlerCreator func(r *depths_types.Registry) binance.WsDepthHandler,
+	errHandlerCreator func(r *depths_types.Registry) binance.ErrHandler) func(r *depths_types.Registry) depths_types.RegistryStreamFunction {
+	return func(r *depths_types.Registry) depths_types.RegistryStreamFunction {
+		return func(symbols []string) (doneC, stopC chan struct{}, err error) {
+			// Один комбінований стрім на всі передані символи
+			return binance.WsCombinedDepthServe100Ms(symbols, handlerCreator(r), errHandlerCreator(r))
+		}
+	}
+}
+
+func registryEventHandlerCreator(r *depths_types.Registry) binance.WsDepthHandler {
+	// Події одного з'єднання обробляються послідовно, тому буфер події спільний для всіх його символів
+	update := &depths_types.DepthUpdate{}
+	return func(event *binance.WsDepthEvent) {
+		var err error
+		if update.Bids, err = parseLevels(event.Bids, update.Bids); err != nil {
+			logrus.Errorf("Spot %v depth event parse error: %v", event.Symbol, err)
+			return
+		}
+		if update.Asks, err = parseLevels(event.Asks, update.Asks); err != nil {
+			logrus.Errorf("Spot %v depth event parse error: %v", event.Symbol, err)
+			return
+		}
+		update.FirstUpdateID = event.FirstUpdateID
+		update.LastUpdateID = event.LastUpdateID
+		r.ProcessUpdate(event.Symbol, update)
+	}
+}
+
+func RegistryCallBackCreator(
+	handlers ...func(r *depths_types.Registry) binance.WsDepthHandler) func(r *depths_types.Registry) binance.WsDepthHandler {
+	return func(r *depths_types.Registry) binance.WsDepthHandler {
+		var stack []binance.WsDepthHandler
+		standardHandlers := registryEventHandlerCreator(r)
+		for _, handler := range handlers {
+			stack = append(stack, handler(r))
+		}
+		return func(event *binance.WsDepthEvent) {
+			standardHandlers(event)
+			for _, handler := range stack {
+				handler(event)
+			}
+		}
+	}
+}
+
+func RegistryWsErrorHandlerCreator(handlers ...func(r *depths_types.Registry) binance.ErrHandler) func(*depths_types.Registry) binance.ErrHandler {
+	return func(r *depths_types.Registry) binance.ErrHandler {
+		var stack []binance.ErrHandler
+		for _, handler := range handlers {
+			stack = append(stack, handler(r))
+		}
+		return func(err error) {
+			// Перепідключення виконує сам реєстр після завершення стріму
+			logrus.Errorf("Spot Depths registry error: %v", err)
+			for _, handler := range stack {
+				handler(err)
+			}
+		}
+	}
+}
@@ -78,24 +78,24 @@ func createOrder(
 	// Type	Additional mandatory parameters
 	if orderType == binance.OrderTypeMarket {
 		// MARKET	quantity
-		service = service.Quantity(utils.ConvFloat64ToStr(float64(quantity), quantityRound))
+		service = service.Quantity(quantity.Format(quantityRound))
 	} else if orderType == binance.OrderTypeLimit {
 		// LIMIT	timeInForce, quantity, price
 		service = service.
 			TimeInForce(timeInForce).
-			Quantity(utils.ConvFloat64ToStr(float64(quantity), quantityRound)).
-			Price(utils.ConvFloat64ToStr(float64(price), priceRound))
+			Quantity(quantity.Format(quantityRound)).
+			Price(price.Format(priceRound))
 	} else if orderType == binance.OrderTypeStopLossLimit || orderType == binance.OrderTypeTakeProfitLimit {
 		// STOP/TAKE_PROFIT	quantity, price, stopPrice
 		service = service.
-			Quantity(utils.ConvFloat64ToStr(float64(quantity), quantityRound)).
-			Price(utils.ConvFloat64ToStr(float64(price), priceRound)).
-			StopPrice(utils.ConvFloat64ToStr(float64(stopPrice), priceRound)).
+			Quantity(quantity.Format(quantityRound)).
+			Price(price.Format(priceRound)).
+			StopPrice(stopPrice.Format(priceRound)).
 			TrailingDelta(utils.ConvFloat64ToStr(float64(callbackRate), priceRound))
 	} else if orderType == binance.OrderTypeStopLoss || orderType == binance.OrderTypeTakeProfit {
 		// STOP_MARKET/TAKE_PROFIT_MARKET	stopPrice
 		service = service.
-			StopPrice(utils.ConvFloat64ToStr(float64(stopPrice), priceRound))
+			StopPrice(stopPrice.Format(priceRound))
 		// if closePosition {
 		// 	service = service.ClosePosition(closePosition)
 		// }
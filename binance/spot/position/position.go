@@ -0,0 +1,151 @@
+package position
+
+import (
+	"context"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	orders_types "github.com/fr0ster/go-trading-utils/types/orders"
+	position_types "github.com/fr0ster/go-trading-utils/types/position"
+	"github.com/fr0ster/go-trading-utils/utils"
+)
+
+const (
+	// Найбільша кількість запитів історії угод при перебудові позиції
+	maxHistoryRequests = 30
+	// Найбільше вікно startTime/endTime, яке приймає myTrades
+	historyWindow = 24 * time.Hour
+)
+
+// InitCreator перебудовує позицію від поточного балансу базового активу.
+// Спочатку завантажуються останні limit угод TradeV3, далі історія читається назад вікнами по добі
+// до найстаршої відомої угоди, поки позиція в історії не стане закритою, але не більше maxHistoryRequests запитів.
+// Ідентифікатори угод спільні для всіх учасників ринку, тому сторінки рахуються за часом, а не за ID.
+// Баланс, отриманий не через угоди пари, враховується як початкова позиція.
+func InitCreator(client *binance.Client, limit int) func(p *position_types.Position) types.InitFunction {
+	return func(p *position_types.Position) types.InitFunction {
+		return func() (err error) {
+			account, err := client.NewGetAccountService().Do(context.Background())
+			if err != nil {
+				return
+			}
+			var quantity items_types.QuantityType
+			for _, balance := range account.Balances {
+				if balance.Asset == p.GetBaseAsset() {
+					quantity = items_types.QuantityType(utils.ConvStrToFloat64(balance.Free) + utils.ConvStrToFloat64(balance.Locked))
+				}
+			}
+			trades, err := client.NewListTradesService().Symbol(p.GetSymbol()).Limit(limit).Do(context.Background())
+			if err != nil {
+				return
+			}
+			fills := make([]position_types.Fill, 0, len(trades))
+			for _, trade := range trades {
+				fills = append(fills, ConvertTrade(trade))
+			}
+			requests := 1
+			if _, flat := p.Opening(quantity, fills); !flat && len(fills) > 0 && len(trades) >= limit {
+				end := fills[0].Time.Add(-time.Millisecond)
+				for requests < maxHistoryRequests {
+					start := end.Add(-historyWindow + time.Millisecond)
+					var window []position_types.Fill
+					if window, err = listWindow(client, p.GetSymbol(), limit, start, end, &requests); err != nil {
+						return
+					}
+					fills = append(window, fills...)
+					if _, flat = p.Opening(quantity, fills); flat {
+						break
+					}
+					end = start.Add(-time.Millisecond)
+				}
+			}
+			p.RebuildFrom(quantity, 0, fills)
+			return
+		}
+	}
+}
+
+// listWindow завантажує угоди з start по end включно, вікно довше за limit угод читається кількома запитами
+func listWindow(
+	client *binance.Client,
+	symbol string,
+	limit int,
+	start, end time.Time,
+	requests *int) (fills []position_types.Fill, err error) {
+	from := start
+	for *requests < maxHistoryRequests {
+		trades, err := client.NewListTradesService().
+			Symbol(symbol).
+			StartTime(from.UnixMilli()).
+			EndTime(end.UnixMilli()).
+			Limit(limit).
+			Do(context.Background())
+		*requests++
+		if err != nil {
+			return nil, err
+		}
+		added := 0
+		for _, trade := range trades {
+			// Наступна сторінка починається з часу останньої угоди, повтори пропускаються
+			if len(fills) == 0 || trade.ID > fills[len(fills)-1].ID {
+				fills = append(fills, ConvertTrade(trade))
+				added++
+			}
+		}
+		if len(trades) < limit || added == 0 {
+			break
+		}
+		from = time.UnixMilli(trades[len(trades)-1].Time)
+	}
+	return
+}
+
+// ConvertTrade перетворює угоду TradeV3 у виконання
+func ConvertTrade(trade *binance.TradeV3) position_types.Fill {
+	side := types.SideTypeSell
+	if trade.IsBuyer {
+		side = types.SideTypeBuy
+	}
+	return position_types.Fill{
+		ID:              trade.ID,
+		OrderID:         trade.OrderID,
+		Side:            side,
+		Price:           items_types.PriceType(utils.ConvStrToFloat64(trade.Price)),
+		Quantity:        items_types.QuantityType(utils.ConvStrToFloat64(trade.Quantity)),
+		Commission:      items_types.ValueType(utils.ConvStrToFloat64(trade.Commission)),
+		CommissionAsset: trade.CommissionAsset,
+		Time:            time.UnixMilli(trade.Time),
+	}
+}
+
+// UserDataHandler враховує виконання з executionReport з типом TRADE для пари позиції
+func UserDataHandler(p *position_types.Position) binance.WsUserDataHandler {
+	return func(event *binance.WsUserDataEvent) {
+		update := event.OrderUpdate
+		if event.Event != binance.UserDataEventTypeExecutionReport ||
+			types.OrderExecutionType(update.ExecutionType) != types.OrderExecutionTypeTrade ||
+			update.Symbol != p.GetSymbol() {
+			return
+		}
+		p.AddFill(position_types.Fill{
+			ID:              update.TradeId,
+			OrderID:         update.Id,
+			Side:            types.OrderSide(update.Side),
+			Price:           items_types.PriceType(utils.ConvStrToFloat64(update.LatestPrice)),
+			Quantity:        items_types.QuantityType(utils.ConvStrToFloat64(update.LatestVolume)),
+			Commission:      items_types.ValueType(utils.ConvStrToFloat64(update.FeeCost)),
+			CommissionAsset: update.FeeAsset,
+			Time:            time.UnixMilli(update.TransactionTime),
+		})
+	}
+}
+
+// UserDataHandlerCreator підключає облік позиції до CallBackCreator стріму ордерів
+func UserDataHandlerCreator(p *position_types.Position) func(*orders_types.Orders) binance.WsUserDataHandler {
+	return func(*orders_types.Orders) binance.WsUserDataHandler {
+		return UserDataHandler(p)
+	}
+}
@@ -0,0 +1,112 @@
+package position_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/stretchr/testify/assert"
+
+	spot_position "github.com/fr0ster/go-trading-utils/binance/spot/position"
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	position_types "github.com/fr0ster/go-trading-utils/types/position"
+)
+
+func TestUserDataHandler(t *testing.T) {
+	p := position_types.New("BTCUSDT", "BTC", "USDT", true, nil)
+	p.Rebuild([]position_types.Fill{spot_position.ConvertTrade(&binance.TradeV3{
+		ID: 1, Symbol: "BTCUSDT", Price: "100", Quantity: "1",
+		Commission: "0.001", CommissionAsset: "BTC", Time: 1000, IsBuyer: true,
+	})})
+	handler := spot_position.UserDataHandler(p)
+	handler(&binance.WsUserDataEvent{
+		Event: binance.UserDataEventTypeExecutionReport,
+		OrderUpdate: binance.WsOrderUpdate{
+			Symbol: "BTCUSDT", Side: string(types.SideTypeSell), ExecutionType: string(types.OrderExecutionTypeTrade),
+			TradeId: 2, LatestPrice: "120", LatestVolume: "0.5",
+			FeeCost: "0.06", FeeAsset: "USDT", TransactionTime: 2000,
+		},
+	})
+
+	assert.InDelta(t, 0.499, float64(p.GetQuantity()), 1e-12)
+	assert.Equal(t, items_types.PriceType(100), p.GetEntryPrice())
+	assert.Equal(t, items_types.ValueType(10), p.GetRealizedPnL())
+	assert.Equal(t, types.SideTypeBuy, p.GetSide())
+}
+
+func TestInitCreatorPaginatesUntilFlat(t *testing.T) {
+	day := int64(24 * time.Hour / time.Millisecond)
+	// Ідентифікатори угод рахунку йдуть з пропусками, бо спільні для всього ринку
+	trades := []*binance.TradeV3{
+		{ID: 1000, Price: "100", Quantity: "1", Time: 10 * day, IsBuyer: true},
+		{ID: 5000, Price: "110", Quantity: "1", Time: 10*day + 1000, IsBuyer: true},
+		{ID: 9000, Price: "120", Quantity: "1", Time: 10*day + 2000},
+		{ID: 20000, Price: "100", Quantity: "1", Time: 11 * day},
+		{ID: 30000, Price: "90", Quantity: "2", Time: 11*day + 1000, IsBuyer: true},
+	}
+	var balance string
+	pages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/account":
+			_ = json.NewEncoder(w).Encode(binance.Account{Balances: []binance.Balance{{Asset: "BTC", Free: balance, Locked: "0"}}})
+		case "/api/v3/myTrades":
+			pages++
+			query := r.URL.Query()
+			assert.Empty(t, query.Get("fromId"))
+			limit, _ := strconv.Atoi(query.Get("limit"))
+			page := trades[len(trades)-limit:]
+			if query.Get("startTime") != "" {
+				startTime, _ := strconv.ParseInt(query.Get("startTime"), 10, 64)
+				endTime, _ := strconv.ParseInt(query.Get("endTime"), 10, 64)
+				assert.Less(t, endTime-startTime, day)
+				page = nil
+				for _, trade := range trades {
+					if trade.Time >= startTime && trade.Time <= endTime && len(page) < limit {
+						page = append(page, trade)
+					}
+				}
+			}
+			_ = json.NewEncoder(w).Encode(page)
+		}
+	}))
+	defer server.Close()
+	client := binance.NewClient("", "")
+	client.BaseURL = server.URL
+	p := position_types.New("BTCUSDT", "BTC", "USDT", false, spot_position.InitCreator(client, 2))
+
+	// Позиція закрита перед останньою угодою, старші сторінки не потрібні
+	balance = "2"
+	assert.NoError(t, p.Refresh())
+	assert.Equal(t, 1, pages)
+	assert.Equal(t, items_types.QuantityType(2), p.GetQuantity())
+	assert.Equal(t, items_types.PriceType(90), p.GetEntryPrice())
+
+	// 0.5 BTC отримано не через угоди пари, історія читається до обмеження кількості запитів
+	pages, balance = 0, "2.5"
+	assert.NoError(t, p.Refresh())
+	assert.Greater(t, pages, 4)
+	assert.Equal(t, items_types.QuantityType(2.5), p.GetQuantity())
+	assert.InDelta(t, 92.8, float64(p.GetEntryPrice()), 1e-9)
+	assert.InDelta(t, 12, float64(p.GetRealizedPnL()), 1e-9)
+
+	// Вікно перед першою сторінкою має більше limit угод і читається кількома запитами
+	trades = []*binance.TradeV3{
+		{ID: 1000, Price: "100", Quantity: "1", Time: 10 * day, IsBuyer: true},
+		{ID: 5000, Price: "110", Quantity: "1", Time: 10*day + 1000},
+		{ID: 9000, Price: "120", Quantity: "1", Time: 10*day + 2000, IsBuyer: true},
+		{ID: 20000, Price: "100", Quantity: "1", Time: 11 * day, IsBuyer: true},
+		{ID: 30000, Price: "90", Quantity: "1", Time: 11*day + 1000, IsBuyer: true},
+	}
+	pages, balance = 0, "3"
+	assert.NoError(t, p.Refresh())
+	assert.Equal(t, 4, pages)
+	assert.Equal(t, items_types.QuantityType(3), p.GetQuantity())
+	assert.InDelta(t, 310.0/3, float64(p.GetEntryPrice()), 1e-9)
+	assert.InDelta(t, 10, float64(p.GetRealizedPnL()), 1e-9)
+}
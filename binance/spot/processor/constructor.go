@@ -2,18 +2,26 @@ package processor
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/adshao/go-binance/v2"
 	"github.com/fr0ster/go-trading-utils/utils"
 	"github.com/sirupsen/logrus"
 
+	spot_account "github.com/fr0ster/go-trading-utils/binance/spot/account"
 	spot_exchangeinfo "github.com/fr0ster/go-trading-utils/binance/spot/exchangeinfo"
 
+	account_types "github.com/fr0ster/go-trading-utils/types/account"
 	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
 	exchangeinfo_types "github.com/fr0ster/go-trading-utils/types/exchangeinfo"
 	processor_types "github.com/fr0ster/go-trading-utils/types/processor"
 )
 
+// DefaultAccountMaxAge - вік знімку балансів, після якого кеш завантажується знову,
+// навіть якщо стрім користувача не підключено
+const DefaultAccountMaxAge = 1 * time.Minute
+
 func New(
 	client *binance.Client,
 	degree int,
@@ -35,28 +43,20 @@ func New(
 	}
 	exchange := exchangeinfo_types.New(spot_exchangeinfo.InitCreator(client, degree, symbol))
 	symbolInfo := exchange.GetSymbol(symbol)
-	baseSymbol := string(exchange.GetSymbol(symbol).GetBaseSymbol())
-	targetSymbol := string(exchange.GetSymbol(symbol).GetTargetSymbol())
+	if symbolInfo == nil {
+		return nil, fmt.Errorf("symbol %v not found", symbol)
+	}
+	// Баланси читаються з кешу, обробник стріму користувача підключається до pairProcessor.GetAccount()
+	account := account_types.New(DefaultAccountMaxAge, spot_account.InitCreator(client))
+	getBaseBalance, getTargetBalance, getFreeBalance, getLockedBalance := processor_types.AccountBalanceGetters(account, symbolInfo)
 	pairProcessor, err = processor_types.New(
-		quit,       // quit
-		symbol,     // pair
-		symbolInfo, // symbolInfo
-		getBaseBalance(
-			client, // client
-			baseSymbol,
-		), // getBaseBalance
-		getTargetBalance(
-			client, // client
-			targetSymbol,
-		), // getTargetBalance
-		getFreeBalance(
-			client, // client
-			symbol,
-		), // getFreeBalance
-		getLockedBalance(
-			client, // client
-			symbol,
-		), // getLockedBalance
+		quit,             // quit
+		symbol,           // pair
+		symbolInfo,       // symbolInfo
+		getBaseBalance,   // getBaseBalance
+		getTargetBalance, // getTargetBalance
+		getFreeBalance,   // getFreeBalance
+		getLockedBalance, // getLockedBalance
 		getCurrentPrice(
 			client, // client
 			symbol,
@@ -87,69 +87,33 @@ func New(
 			return callbackRate
 		}, // getCallbackRate
 		debug)
+	pairProcessor.SetAccount(account)
 	return
 } // New
 
-func getBaseBalance(client *binance.Client, symbol string) processor_types.GetBaseBalanceFunction {
-	return func() items_types.ValueType {
-		account, err := client.NewGetAccountService().Do(context.Background())
-		if err != nil {
-			logrus.Errorf("Can't get account: %v", err)
-			return 0
-		}
-		for _, asset := range account.Balances {
-			if asset.Asset == symbol {
-				return items_types.ValueType(utils.ConvStrToFloat64(asset.Free) + utils.ConvStrToFloat64(asset.Locked))
-			}
-		}
-		return 0.0
-	}
-} // getBaseBalance
-func getTargetBalance(client *binance.Client, symbol string) processor_types.GetTargetBalanceFunction {
-	return func() items_types.QuantityType {
-		account, err := client.NewGetAccountService().Do(context.Background())
-		if err != nil {
-			logrus.Errorf("Can't get account: %v", err)
-			return 0
-		}
-		for _, asset := range account.Balances {
-			if asset.Asset == symbol {
-				return items_types.QuantityType(utils.ConvStrToFloat64(asset.Free) + utils.ConvStrToFloat64(asset.Locked))
-			}
-		}
-		return 0.0
-	}
-} // getTargetBalance
-func getFreeBalance(client *binance.Client, symbol string) processor_types.GetFreeBalanceFunction {
-	return func() items_types.ValueType {
-		account, err := client.NewGetAccountService().Do(context.Background())
-		if err != nil {
-			logrus.Errorf("Can't get account: %v", err)
-			return 0
-		}
-		for _, asset := range account.Balances {
-			if asset.Asset == symbol {
-				return items_types.ValueType(utils.ConvStrToFloat64(asset.Free))
-			}
-		}
-		return 0.0
+// NewFromConfig створює процесор пари з config, геттери балансів та ціни беруться з client,
+// options застосовуються після них і можуть їх перевизначити.
+// Баланси читаються з власного кешу рахунку, спільний кеш передається через processor_types.WithAccount.
+func NewFromConfig(
+	client *binance.Client,
+	config processor_types.Config,
+	options ...processor_types.Option,
+) (pairProcessor *processor_types.Processor, err error) {
+	config.SetDefaults()
+	if err = config.Validate(); err != nil {
+		return nil, fmt.Errorf("processor %v config: %w", config.Symbol, err)
 	}
-} // getFreeBalance
-func getLockedBalance(client *binance.Client, symbol string) processor_types.GetLockedBalanceFunction {
-	return func() items_types.ValueType {
-		account, err := client.NewGetAccountService().Do(context.Background())
-		if err != nil {
-			logrus.Errorf("Can't get account: %v", err)
-			return 0
-		}
-		for _, asset := range account.Balances {
-			if asset.Asset == symbol {
-				return items_types.ValueType(utils.ConvStrToFloat64(asset.Locked))
-			}
-		}
-		return 0.0
+	exchange := exchangeinfo_types.New(spot_exchangeinfo.InitCreator(client, config.Degree, config.Symbol))
+	symbolInfo := exchange.GetSymbol(config.Symbol)
+	if symbolInfo == nil {
+		return nil, fmt.Errorf("symbol %v not found", config.Symbol)
 	}
-} // getLockedBalance
+	return processor_types.NewFromConfig(config, symbolInfo, append([]processor_types.Option{
+		processor_types.WithAccount(account_types.New(DefaultAccountMaxAge, spot_account.InitCreator(client))),
+		processor_types.WithCurrentPrice(getCurrentPrice(client, config.Symbol)),
+	}, options...)...)
+} // NewFromConfig
+
 func getCurrentPrice(client *binance.Client, symbol string) processor_types.GetCurrentPriceFunction {
 	return func() items_types.PriceType {
 		price, err := client.NewListPricesService().Symbol(symbol).Do(context.Background())
@@ -0,0 +1,39 @@
+package processor_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adshao/go-binance/v2"
+	"github.com/stretchr/testify/assert"
+
+	spot_processor "github.com/fr0ster/go-trading-utils/binance/spot/processor"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func TestNewReadsBalancesBeforeSetup(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/exchangeInfo":
+			_, _ = w.Write([]byte(`{"symbols":[{"symbol":"BTCUSDT","baseAsset":"BTC","quoteAsset":"USDT","filters":[
+				{"filterType":"PRICE_FILTER","tickSize":"0.01","maxPrice":"1000000","minPrice":"0.01"},
+				{"filterType":"LOT_SIZE","stepSize":"0.00001","maxQty":"9000","minQty":"0.00001"},
+				{"filterType":"NOTIONAL","minNotional":"5"}]}]}`))
+		case "/api/v3/account":
+			_, _ = w.Write([]byte(`{"balances":[{"asset":"USDT","free":"1000","locked":"200"},{"asset":"BTC","free":"0.5","locked":"0"}]}`))
+		case "/api/v3/ticker/price":
+			_, _ = w.Write([]byte(`[{"symbol":"BTCUSDT","price":"60000"}]`))
+		}
+	}))
+	defer server.Close()
+	client := binance.NewClient("", "")
+	client.BaseURL = server.URL
+
+	pp, err := spot_processor.New(client, 3, "BTCUSDT", 500, 10, 10, 1, 1, 0.1, true)
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.ValueType(1000), pp.GetFreeBalance())
+	assert.Equal(t, items_types.QuantityType(0.5), pp.GetTargetBalance())
+	assert.Equal(t, items_types.ValueType(50), pp.GetLimitOnTransaction())
+	assert.NotNil(t, pp.GetAccount())
+}
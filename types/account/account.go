@@ -0,0 +1,238 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+// DefaultRetryInterval - пауза після невдалого завантаження знімку,
+// протягом якої читання повертають кеш без нових запитів до біржі
+const DefaultRetryInterval = 5 * time.Second
+
+type (
+	// Balance - баланс активу, Free доступний для нових ордерів, Locked зайнятий ордерами або маржею
+	Balance struct {
+		Asset     string
+		Free      items_types.ValueType
+		Locked    items_types.ValueType
+		UpdatedAt time.Time
+	}
+	// Account - кеш балансів рахунку. Знімок завантажується через Init (REST),
+	// далі баланси оновлюються подіями стріму користувача.
+	// Кеш застарілий, якщо ще не завантажений, після Invalidate або якщо знімку більше maxAge.
+	Account struct {
+		mutex       sync.Mutex
+		refresh     sync.Mutex
+		balances    map[string]*Balance
+		maxAge      time.Duration
+		refreshedAt time.Time
+		invalid     bool
+		// Остання помилка завантаження знімку для паузи між повторами
+		retryInterval time.Duration
+		failedAt      time.Time
+		failure       error
+		Init          types.InitFunction
+	}
+)
+
+// GetTotal повертає повний баланс активу
+func (b Balance) GetTotal() items_types.ValueType {
+	return b.Free + b.Locked
+}
+
+// New створює кеш, maxAge 0 - знімок не застаріває з часом
+func New(maxAge time.Duration, initCreator func(*Account) types.InitFunction) *Account {
+	this := &Account{
+		balances:      make(map[string]*Balance),
+		maxAge:        maxAge,
+		retryInterval: DefaultRetryInterval,
+	}
+	this.SetInit(initCreator)
+	return this
+}
+
+func (a *Account) SetInit(initCreator func(*Account) types.InitFunction) {
+	if initCreator != nil {
+		a.Init = initCreator(a)
+	}
+}
+
+// SetRetryInterval змінює паузу після невдалого завантаження знімку, 0 - повторювати при кожному читанні
+func (a *Account) SetRetryInterval(interval time.Duration) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.retryInterval = interval
+}
+
+// Refresh примусово завантажує знімок балансів через Init, пауза після помилки не враховується
+func (a *Account) Refresh() error {
+	if a.Init == nil {
+		return errors.New("account init function is not set")
+	}
+	a.refresh.Lock()
+	defer a.refresh.Unlock()
+	return a.init()
+}
+
+// SetBalances замінює всі баланси знімком, викликається з Init.
+// Баланси, які стрім оновив пізніше за UpdatedAt знімку, зберігаються.
+func (a *Account) SetBalances(balances []Balance) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	now := time.Now()
+	updated := make(map[string]*Balance, len(balances))
+	for _, balance := range balances {
+		balance := balance
+		if balance.UpdatedAt.IsZero() {
+			balance.UpdatedAt = now
+		}
+		if old, ok := a.balances[balance.Asset]; ok && old.UpdatedAt.After(balance.UpdatedAt) {
+			balance = *old
+		}
+		updated[balance.Asset] = &balance
+	}
+	a.balances = updated
+	a.refreshedAt = now
+	a.invalid = false
+}
+
+// UpdateBalance встановлює баланс активу з події стріму, події старші за останнє оновлення активу ігноруються
+func (a *Account) UpdateBalance(asset string, free, locked items_types.ValueType, at time.Time) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if balance, ok := a.balance(asset, at); ok {
+		balance.Free, balance.Locked, balance.UpdatedAt = free, locked, at
+	}
+}
+
+// UpdateTotal встановлює повний баланс активу, коли стрім не повідомляє доступну частину,
+// як ACCOUNT_UPDATE ф'ючерсів. Різниця додається до Free.
+func (a *Account) UpdateTotal(asset string, total items_types.ValueType, at time.Time) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if balance, ok := a.balance(asset, at); ok {
+		balance.Free += total - balance.GetTotal()
+		balance.UpdatedAt = at
+	}
+}
+
+// AddBalance додає зміну delta до доступного балансу, наприклад депозит або виведення
+func (a *Account) AddBalance(asset string, delta items_types.ValueType, at time.Time) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if balance, ok := a.balance(asset, at); ok {
+		balance.Free += delta
+		balance.UpdatedAt = at
+	}
+}
+
+// Invalidate позначає кеш застарілим, наступне читання завантажить знімок.
+// Викликається, коли події могли бути втрачені, наприклад після помилки стріму.
+func (a *Account) Invalidate() {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	a.invalid = true
+}
+
+// InvalidateOlderThan позначає кеш застарілим, лише якщо знімок старший за age,
+// щоб часті події не викликали завантаження знімку частіше за раз на age
+func (a *Account) InvalidateOlderThan(age time.Duration) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if time.Since(a.refreshedAt) > age {
+		a.invalid = true
+	}
+}
+
+// IsStale перевіряє, чи потрібно завантажити знімок перед читанням
+func (a *Account) IsStale() bool {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.isStale()
+}
+
+// GetRefreshedAt повертає час останнього завантаження знімку
+func (a *Account) GetRefreshedAt() time.Time {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.refreshedAt
+}
+
+// GetBalance повертає копію балансу активу, застарілий кеш спочатку оновлюється.
+// Якщо оновлення не вдалося, повертається кешоване значення разом з помилкою.
+func (a *Account) GetBalance(asset string) (balance Balance, err error) {
+	err = a.refreshIfStale()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if b, ok := a.balances[asset]; ok {
+		balance = *b
+	} else {
+		balance = Balance{Asset: asset}
+	}
+	return
+}
+
+// GetBalances повертає копії всіх балансів, застарілий кеш спочатку оновлюється
+func (a *Account) GetBalances() (balances []Balance, err error) {
+	err = a.refreshIfStale()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	for _, balance := range a.balances {
+		balances = append(balances, *balance)
+	}
+	return
+}
+
+// Паралельні читання застарілого кешу чекають одне завантаження знімку.
+// Після помилки наступна спроба можлива не раніше retryInterval, щоб не посилювати обмеження біржі.
+func (a *Account) refreshIfStale() error {
+	if !a.IsStale() {
+		return nil
+	}
+	if a.Init == nil {
+		return errors.New("account init function is not set")
+	}
+	a.refresh.Lock()
+	defer a.refresh.Unlock()
+	if !a.IsStale() {
+		return nil
+	}
+	a.mutex.Lock()
+	failure, wait := a.failure, a.retryInterval-time.Since(a.failedAt)
+	a.mutex.Unlock()
+	if failure != nil && wait > 0 {
+		return fmt.Errorf("account refresh delayed for %v after error: %w", wait.Round(time.Millisecond), failure)
+	}
+	return a.init()
+}
+
+// Викликається під a.refresh
+func (a *Account) init() (err error) {
+	err = a.Init()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if err != nil {
+		a.failedAt, a.failure = time.Now(), err
+	} else {
+		a.failedAt, a.failure = time.Time{}, nil
+	}
+	return
+}
+
+func (a *Account) isStale() bool {
+	return a.invalid || a.refreshedAt.IsZero() || a.maxAge > 0 && time.Since(a.refreshedAt) > a.maxAge
+}
+
+func (a *Account) balance(asset string, at time.Time) (balance *Balance, ok bool) {
+	balance, exists := a.balances[asset]
+	if !exists {
+		balance = &Balance{Asset: asset}
+		a.balances[asset] = balance
+	}
+	return balance, !at.Before(balance.UpdatedAt)
+}
@@ -0,0 +1,156 @@
+package account_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	account_types "github.com/fr0ster/go-trading-utils/types/account"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func initCreator(calls *int32, snapshot ...account_types.Balance) func(*account_types.Account) types.InitFunction {
+	return func(a *account_types.Account) types.InitFunction {
+		return func() error {
+			atomic.AddInt32(calls, 1)
+			a.SetBalances(snapshot)
+			return nil
+		}
+	}
+}
+
+func TestAccountCache(t *testing.T) {
+	var calls int32
+	a := account_types.New(0, initCreator(&calls, account_types.Balance{Asset: "USDT", Free: 100, Locked: 20}))
+	assert.True(t, a.IsStale())
+
+	balance, err := a.GetBalance("USDT")
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.ValueType(100), balance.Free)
+	assert.Equal(t, items_types.ValueType(120), balance.GetTotal())
+
+	// Повторні читання беруть значення з кешу
+	for i := 0; i < 5; i++ {
+		_, err = a.GetBalance("USDT")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	at := time.Now().Add(time.Second)
+	a.UpdateBalance("USDT", 90, 30, at)
+	a.UpdateBalance("USDT", 1, 1, at.Add(-time.Millisecond))
+	balance, _ = a.GetBalance("USDT")
+	assert.Equal(t, items_types.ValueType(90), balance.Free)
+	assert.Equal(t, items_types.ValueType(30), balance.Locked)
+
+	a.UpdateTotal("USDT", 150, at.Add(time.Millisecond))
+	balance, _ = a.GetBalance("USDT")
+	assert.Equal(t, items_types.ValueType(120), balance.Free)
+	assert.Equal(t, items_types.ValueType(150), balance.GetTotal())
+
+	a.AddBalance("BTC", 0.5, at)
+	balance, _ = a.GetBalance("BTC")
+	assert.Equal(t, items_types.ValueType(0.5), balance.Free)
+
+	balance, _ = a.GetBalance("ETH")
+	assert.Equal(t, "ETH", balance.Asset)
+	assert.Equal(t, items_types.ValueType(0), balance.GetTotal())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// Після Invalidate знімок завантажується знову, новіші оновлення стріму зберігаються
+	a.Invalidate()
+	assert.True(t, a.IsStale())
+	balances, err := a.GetBalances()
+	assert.NoError(t, err)
+	assert.Len(t, balances, 1)
+	assert.Equal(t, items_types.ValueType(150), balances[0].GetTotal())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	assert.NoError(t, a.Refresh())
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestAccountStaleness(t *testing.T) {
+	var calls int32
+	a := account_types.New(20*time.Millisecond, initCreator(&calls, account_types.Balance{Asset: "USDT", Free: 1}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = a.GetBalance("USDT")
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.False(t, a.IsStale())
+
+	time.Sleep(30 * time.Millisecond)
+	assert.True(t, a.IsStale())
+	_, _ = a.GetBalance("USDT")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// Свіжий знімок не позначається застарілим
+	a.InvalidateOlderThan(10 * time.Millisecond)
+	assert.False(t, a.IsStale())
+	time.Sleep(15 * time.Millisecond)
+	a.InvalidateOlderThan(10 * time.Millisecond)
+	assert.True(t, a.IsStale())
+}
+
+func TestAccountRefreshError(t *testing.T) {
+	a := account_types.New(0, nil)
+	_, err := a.GetBalance("USDT")
+	assert.Error(t, err)
+	assert.Error(t, a.Refresh())
+
+	a.SetInit(func(*account_types.Account) types.InitFunction {
+		return func() error { return errors.New("rate limit") }
+	})
+	a.UpdateBalance("USDT", 5, 0, time.Now())
+	balance, err := a.GetBalance("USDT")
+	assert.Error(t, err)
+	assert.Equal(t, items_types.ValueType(5), balance.Free)
+}
+
+func TestAccountRetryInterval(t *testing.T) {
+	var calls int32
+	fail := true
+	a := account_types.New(0, func(a *account_types.Account) types.InitFunction {
+		return func() error {
+			atomic.AddInt32(&calls, 1)
+			if fail {
+				return errors.New("-1003 too many requests")
+			}
+			a.SetBalances([]account_types.Balance{{Asset: "USDT", Free: 10}})
+			return nil
+		}
+	})
+	a.SetRetryInterval(50 * time.Millisecond)
+
+	// Читання після помилки не звертаються до біржі до кінця паузи
+	for i := 0; i < 5; i++ {
+		_, err := a.GetBalance("USDT")
+		assert.ErrorContains(t, err, "-1003")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	fail = false
+	time.Sleep(60 * time.Millisecond)
+	balance, err := a.GetBalance("USDT")
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.ValueType(10), balance.Free)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// Примусове оновлення ігнорує паузу
+	fail = true
+	assert.Error(t, a.Refresh())
+	assert.Error(t, a.Refresh())
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,193 @@
+package depth
+
+import (
+	"time"
+
+	"github.com/google/btree"
+
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+type (
+	// DepthSignals - короткострокові сигнали стакану на момент оновлення
+	DepthSignals struct {
+		Time         time.Time
+		LastUpdateID int64
+		// (bids - asks) / (bids + asks) по кількості на перших N рівнях, від -1 до 1
+		Imbalance float64
+		// Середнє між VWAP перших N рівнів asks та bids
+		WeightedMidPrice items_types.PriceType
+		// Ціна, зважена кількістю на найкращих рівнях
+		MicroPrice items_types.PriceType
+		// Кількість в межах ±percent від середини спреду
+		AsksDepth items_types.QuantityType
+		BidsDepth items_types.QuantityType
+		// Order flow imbalance між двома послідовними оновленнями
+		OrderFlowImbalance float64
+	}
+	analytics struct {
+		levels      int
+		percent     items_types.PricePercentType
+		historySize int
+		history     []*DepthSignals
+		bestAsk     *items_types.DepthItem
+		bestBid     *items_types.DepthItem
+	}
+)
+
+// EnableAnalytics вмикає розрахунок сигналів після кожного застосованого оновлення стакану.
+// levels - кількість рівнів для imbalance та weighted mid, percent - відхилення від середини для глибини,
+// historySize - скільки останніх значень зберігати, не менше одного.
+func (d *Depths) EnableAnalytics(levels int, percent items_types.PricePercentType, historySize int) {
+	if historySize < 1 {
+		historySize = 1
+	}
+	d.analytics = &analytics{
+		levels:      levels,
+		percent:     percent,
+		historySize: historySize,
+	}
+}
+
+// UpdateAnalytics рахує сигнали по поточному стану стакану та додає їх в історію.
+// Викликається автоматично після застосування подій стріму, виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) UpdateAnalytics() (signals *DepthSignals) {
+	if d.analytics == nil {
+		return
+	}
+	signals = &DepthSignals{
+		Time:             time.Now(),
+		LastUpdateID:     d.LastUpdateID,
+		Imbalance:        d.GetImbalance(d.analytics.levels),
+		WeightedMidPrice: d.GetWeightedMidPrice(d.analytics.levels),
+		MicroPrice:       d.GetMicroPrice(),
+	}
+	signals.AsksDepth, signals.BidsDepth = d.GetDepthByPercent(d.analytics.percent)
+	bestAsk, _ := d.asks.GetMinPrice()
+	bestBid, _ := d.bids.GetMaxPrice()
+	signals.OrderFlowImbalance = orderFlowImbalance(d.analytics.bestBid, d.analytics.bestAsk, bestBid, bestAsk)
+	// Зберігаємо копії, бо елементи дерева змінюються на місці
+	d.analytics.bestAsk = copyItem(bestAsk)
+	d.analytics.bestBid = copyItem(bestBid)
+	d.analytics.history = append(d.analytics.history, signals)
+	if len(d.analytics.history) > d.analytics.historySize {
+		d.analytics.history = d.analytics.history[len(d.analytics.history)-d.analytics.historySize:]
+	}
+	return
+}
+
+// GetSignals повертає останні розраховані сигнали
+func (d *Depths) GetSignals() *DepthSignals {
+	if d.analytics == nil || len(d.analytics.history) == 0 {
+		return nil
+	}
+	return d.analytics.history[len(d.analytics.history)-1]
+}
+
+// GetSignalsHistory повертає історію сигналів від найстаріших до найновіших
+func (d *Depths) GetSignalsHistory() []*DepthSignals {
+	if d.analytics == nil {
+		return nil
+	}
+	return append([]*DepthSignals(nil), d.analytics.history...)
+}
+
+// GetImbalance - (bids - asks) / (bids + asks) по кількості на перших levels рівнях
+func (d *Depths) GetImbalance(levels int) float64 {
+	asksQuantity, _ := d.topLevels(levels, true)
+	bidsQuantity, _ := d.topLevels(levels, false)
+	if asksQuantity+bidsQuantity == 0 {
+		return 0
+	}
+	return float64((bidsQuantity - asksQuantity) / (bidsQuantity + asksQuantity))
+}
+
+// GetWeightedMidPrice - середнє між VWAP перших levels рівнів asks та bids
+func (d *Depths) GetWeightedMidPrice(levels int) items_types.PriceType {
+	asksQuantity, asksValue := d.topLevels(levels, true)
+	bidsQuantity, bidsValue := d.topLevels(levels, false)
+	if asksQuantity == 0 || bidsQuantity == 0 {
+		return 0
+	}
+	return (items_types.PriceType(asksValue)/items_types.PriceType(asksQuantity) +
+		items_types.PriceType(bidsValue)/items_types.PriceType(bidsQuantity)) / 2
+}
+
+// GetMicroPrice - (bestBid*askQty + bestAsk*bidQty) / (askQty + bidQty)
+func (d *Depths) GetMicroPrice() items_types.PriceType {
+	ask, err := d.asks.GetMinPrice()
+	if err != nil {
+		return 0
+	}
+	bid, err := d.bids.GetMaxPrice()
+	if err != nil {
+		return 0
+	}
+	return (bid.GetPrice()*items_types.PriceType(ask.GetQuantity()) + ask.GetPrice()*items_types.PriceType(bid.GetQuantity())) /
+		items_types.PriceType(ask.GetQuantity()+bid.GetQuantity())
+}
+
+// GetDepthByPercent - кількість по кожній стороні в межах ±percent від середини спреду.
+// Рівні точно на межі не враховуються, як і у вікні SetBounds, яке їх видаляє через RestrictUp/RestrictDown.
+func (d *Depths) GetDepthByPercent(percent items_types.PricePercentType) (asks, bids items_types.QuantityType) {
+	mid, err := d.GetMidPrice()
+	if err != nil {
+		return
+	}
+	delta := mid * items_types.PriceType(percent) / 100
+	d.asks.GetTree().AscendLessThan(items_types.New(mid+delta), func(i btree.Item) bool {
+		asks += i.(*items_types.DepthItem).GetQuantity()
+		return true
+	})
+	d.bids.GetTree().DescendGreaterThan(items_types.New(mid-delta), func(i btree.Item) bool {
+		bids += i.(*items_types.DepthItem).GetQuantity()
+		return true
+	})
+	return
+}
+
+func (d *Depths) topLevels(levels int, up bool) (quantity items_types.QuantityType, value items_types.ValueType) {
+	count := 0
+	iterator := func(i btree.Item) bool {
+		if count >= levels {
+			return false
+		}
+		quantity += i.(*items_types.DepthItem).GetQuantity()
+		value += i.(*items_types.DepthItem).GetValue()
+		count++
+		return true
+	}
+	if up {
+		d.asks.GetTree().Ascend(iterator)
+	} else {
+		d.bids.GetTree().Descend(iterator)
+	}
+	return
+}
+
+// Order flow imbalance (Cont, Kukanov, Stoikov) по зміні найкращих рівнів
+func orderFlowImbalance(prevBid, prevAsk, bid, ask *items_types.DepthItem) (ofi float64) {
+	if prevBid == nil || prevAsk == nil || bid == nil || ask == nil {
+		return
+	}
+	if bid.GetPrice() >= prevBid.GetPrice() {
+		ofi += float64(bid.GetQuantity())
+	}
+	if bid.GetPrice() <= prevBid.GetPrice() {
+		ofi -= float64(prevBid.GetQuantity())
+	}
+	if ask.GetPrice() <= prevAsk.GetPrice() {
+		ofi -= float64(ask.GetQuantity())
+	}
+	if ask.GetPrice() >= prevAsk.GetPrice() {
+		ofi += float64(prevAsk.GetQuantity())
+	}
+	return
+}
+
+func copyItem(item *items_types.DepthItem) *items_types.DepthItem {
+	if item == nil {
+		return nil
+	}
+	return items_types.New(item.GetPrice(), item.GetQuantity())
+}
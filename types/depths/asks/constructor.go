@@ -1,18 +1,15 @@
 package asks
 
 import (
-	depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	side_types "github.com/fr0ster/go-trading-utils/types/depths/side"
 )
 
 func New(degree int, symbol string) *Asks {
-	return &Asks{tree: depths_types.New(degree, symbol)}
+	return &Asks{Side: side_types.New[*items_types.Ask](degree, symbol)}
 }
 
-// Symbol implements depth_interface.Depths.
-func (d *Asks) Symbol() string {
-	return d.tree.Symbol()
-}
-
-func (d *Asks) Degree() int {
-	return d.tree.Degree()
+// Clone повертає copy-on-write копію стакану
+func (d *Asks) Clone() *Asks {
+	return &Asks{Side: d.Side.Clone()}
 }
@@ -78,3 +78,48 @@ func TestGetMaxQuantity(t *testing.T) {
 	assert.Equal(t, items_types.QuantityType(10), min.GetQuantity())
 	assert.Equal(t, items_types.PriceType(100), min.GetPrice())
 }
+
+func TestAsksSideHelpers(t *testing.T) {
+	asks := asks_types.New(degree, "BTCUSDT")
+	for _, price := range []items_types.PriceType{100, 200, 300, 400, 500} {
+		asks.Set(items_types.NewAsk(price, items_types.QuantityType(price/10)))
+	}
+
+	best, err := asks.GetBest()
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.PriceType(100), best.GetPrice())
+	assert.True(t, asks.IsBetter(100, 200))
+	assert.True(t, asks.IsWorse(300, 200))
+
+	var prices []items_types.PriceType
+	asks.BetterThan(300, func(item *items_types.Ask) bool {
+		prices = append(prices, item.GetDepthItem().GetPrice())
+		return true
+	})
+	assert.Equal(t, []items_types.PriceType{100, 200}, prices)
+	prices = nil
+	asks.WorseThan(300, func(item *items_types.Ask) bool {
+		prices = append(prices, item.GetDepthItem().GetPrice())
+		return true
+	})
+	assert.Equal(t, []items_types.PriceType{400, 500}, prices)
+
+	ticks, err := asks.GetDistanceTicks(130, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), ticks)
+	ticks, _ = asks.GetDistanceTicks(90, 10)
+	assert.Equal(t, int64(-1), ticks)
+	_, err = asks.GetDistanceTicks(130, 0)
+	assert.Error(t, err)
+	percent, _ := asks.GetDistancePercent(110)
+	assert.InDelta(t, 10, float64(percent), 1e-9)
+
+	// Відсоток рахується від найкращої ціни вгору
+	_, _, quantity := asks.GetSummaByPricePercent(25)
+	assert.Equal(t, items_types.QuantityType(30), quantity)
+	delta, _ := asks.GetDeltaPricePercent()
+	assert.Equal(t, items_types.PricePercentType(400), delta)
+
+	_, err = asks_types.New(degree, "BTCUSDT").GetBest()
+	assert.Error(t, err)
+}
@@ -1,21 +1,9 @@
 package asks
 
 import (
-	depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
 	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
 )
 
-func (d *Asks) GetFiltered(f ...items_types.DepthFilter) (asks *Asks) {
-	asks = New(d.Degree(), d.Symbol())
-	asks.SetTree(d.tree.GetFiltered(depths_types.UP, f...).GetTree())
-	return
-}
-
-func (d *Asks) GetSummaByPriceRange(
-	first,
-	last items_types.PriceType,
-	f ...items_types.DepthFilter) (
-	value items_types.ValueType,
-	quantity items_types.QuantityType) {
-	return d.tree.GetSummaByPriceRange(first, last, f...)
+func (d *Asks) GetFiltered(f ...items_types.DepthFilter) *Asks {
+	return &Asks{Side: d.Side.GetFiltered(f...)}
 }
@@ -1,7 +1,12 @@
 package asks
 
-import depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
+import (
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	side_types "github.com/fr0ster/go-trading-utils/types/depths/side"
+)
 
 type (
-	Asks struct{ tree *depths_types.Depths }
+	Asks struct {
+		*side_types.Side[*items_types.Ask]
+	}
 )
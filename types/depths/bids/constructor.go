@@ -1,18 +1,15 @@
 package bids
 
 import (
-	depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	side_types "github.com/fr0ster/go-trading-utils/types/depths/side"
 )
 
 func New(degree int, symbol string) *Bids {
-	return &Bids{tree: depths_types.New(degree, symbol)}
+	return &Bids{Side: side_types.New[*items_types.Bid](degree, symbol)}
 }
 
-// Symbol implements depth_interface.Depths.
-func (d *Bids) Symbol() string {
-	return d.tree.Symbol()
-}
-
-func (d *Bids) Degree() int {
-	return d.tree.Degree()
+// Clone повертає copy-on-write копію стакану
+func (d *Bids) Clone() *Bids {
+	return &Bids{Side: d.Side.Clone()}
 }
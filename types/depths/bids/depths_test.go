@@ -76,3 +76,48 @@ func TestGetMaxQuantity(t *testing.T) {
 	assert.Equal(t, items_types.QuantityType(10), min.GetQuantity())
 	assert.Equal(t, items_types.PriceType(500), min.GetPrice())
 }
+
+func TestBidsSideHelpers(t *testing.T) {
+	bids := bids_types.New(degree, "BTCUSDT")
+	for _, price := range []items_types.PriceType{100, 200, 300, 400, 500} {
+		bids.Set(items_types.NewBid(price, items_types.QuantityType(price/10)))
+	}
+
+	best, err := bids.GetBest()
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.PriceType(500), best.GetPrice())
+	assert.True(t, bids.IsBetter(200, 100))
+	assert.True(t, bids.IsWorse(200, 300))
+
+	var prices []items_types.PriceType
+	bids.BetterThan(300, func(item *items_types.Bid) bool {
+		prices = append(prices, item.GetDepthItem().GetPrice())
+		return true
+	})
+	assert.Equal(t, []items_types.PriceType{500, 400}, prices)
+	prices = nil
+	bids.WorseThan(300, func(item *items_types.Bid) bool {
+		prices = append(prices, item.GetDepthItem().GetPrice())
+		return true
+	})
+	assert.Equal(t, []items_types.PriceType{200, 100}, prices)
+
+	ticks, err := bids.GetDistanceTicks(470, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), ticks)
+	ticks, _ = bids.GetDistanceTicks(510, 10)
+	assert.Equal(t, int64(-1), ticks)
+	percent, _ := bids.GetDistancePercent(450)
+	assert.InDelta(t, 10, float64(percent), 1e-9)
+
+	_, _, quantity := bids.GetSummaByPricePercent(25)
+	assert.Equal(t, items_types.QuantityType(90), quantity)
+	delta, _ := bids.GetDeltaPricePercent()
+	assert.Equal(t, items_types.PricePercentType(80), delta)
+
+	// Копія та фільтр лишаються Bids
+	filtered := bids.GetFiltered(func(item *items_types.DepthItem) bool { return item.GetPrice() > 200 })
+	assert.Equal(t, 3, filtered.Count())
+	best, _ = filtered.Clone().GetBest()
+	assert.Equal(t, items_types.PriceType(500), best.GetPrice())
+}
@@ -1,21 +1,9 @@
 package bids
 
 import (
-	depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
 	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
 )
 
-func (d *Bids) GetFiltered(f ...items_types.DepthFilter) (bids *Bids) {
-	bids = New(d.Degree(), d.Symbol())
-	bids.SetTree(d.tree.GetFiltered(depths_types.DOWN, f...).GetTree())
-	return
-}
-
-func (d *Bids) GetSummaByPriceRange(
-	first,
-	last items_types.PriceType,
-	f ...items_types.DepthFilter) (
-	value items_types.ValueType,
-	quantity items_types.QuantityType) {
-	return d.tree.GetSummaByPriceRange(first, last, f...)
+func (d *Bids) GetFiltered(f ...items_types.DepthFilter) *Bids {
+	return &Bids{Side: d.Side.GetFiltered(f...)}
 }
@@ -1,7 +1,12 @@
 package bids
 
-import depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
+import (
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	side_types "github.com/fr0ster/go-trading-utils/types/depths/side"
+)
 
 type (
-	Bids struct{ tree *depths_types.Depths }
+	Bids struct {
+		*side_types.Side[*items_types.Bid]
+	}
 )
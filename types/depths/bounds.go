@@ -0,0 +1,49 @@
+package depth
+
+import (
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+type (
+	// bounds - вікно стакану, яке зберігається, нульові значення вимикають обмеження
+	bounds struct {
+		levels  int
+		percent items_types.PricePercentType
+	}
+)
+
+// SetBounds вмикає обмежений режим стакану: не більше levels рівнів на кожну сторону
+// та тільки рівні в межах ±percent від середини спреду. Нульове значення вимикає відповідне обмеження.
+// Суми та кількість рівнів рахуються тільки по вікну, рівні поза вікном втрачаються,
+// тому після повернення ціни вони з'являються лише з наступними подіями стріму.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) SetBounds(levels int, percent items_types.PricePercentType) {
+	d.bounds = bounds{levels: levels, percent: percent}
+	d.Trim()
+}
+
+// GetBounds повертає обмеження стакану
+func (d *Depths) GetBounds() (levels int, percent items_types.PricePercentType) {
+	return d.bounds.levels, d.bounds.percent
+}
+
+// IsBounded - чи ввімкнено обмежений режим
+func (d *Depths) IsBounded() bool {
+	return d.bounds.levels > 0 || d.bounds.percent > 0
+}
+
+// Trim обрізає стакан до вікна SetBounds.
+// Викликається автоматично після застосування подій стріму, виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) Trim() {
+	if d.bounds.percent > 0 {
+		if mid, err := d.GetMidPrice(); err == nil {
+			delta := mid * items_types.PriceType(d.bounds.percent) / 100
+			d.asks.RestrictUp(mid + delta)
+			d.bids.RestrictDown(mid - delta)
+		}
+	}
+	if d.bounds.levels > 0 {
+		d.asks.KeepLevels(d.bounds.levels)
+		d.bids.KeepLevels(d.bounds.levels)
+	}
+}
@@ -0,0 +1,338 @@
+package consolidated
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/google/btree"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+type (
+	// Level - зведений рівень стакану з розподілом кількості по джерелах
+	Level struct {
+		Price    items_types.PriceType
+		Quantity items_types.QuantityType
+		Sources  map[string]items_types.QuantityType
+		key      int64
+	}
+	// Quote - найкраща ціна одного джерела
+	Quote struct {
+		Source   string
+		Price    items_types.PriceType
+		Quantity items_types.QuantityType
+	}
+	// CrossedSpread - bid одного джерела вищий за ask іншого
+	CrossedSpread struct {
+		Bid Quote
+		Ask Quote
+		// Bid.Price - Ask.Price, завжди додатне
+		Spread        items_types.PriceType
+		SpreadPercent items_types.PricePercentType
+	}
+	CrossedSpreadHandler func(crossed *CrossedSpread)
+	// Book - зведений стакан з кількох depth.Depths, наприклад спот та ф'ючерси одного символу.
+	// Оновлюється по подіях джерел, тому працює з будь-якими адаптерами стрімів без змін в них.
+	Book struct {
+		mutex         sync.Mutex
+		degree        int
+		asks          *btree.BTree
+		bids          *btree.BTree
+		sources       map[string]*source
+		crossHandlers []CrossedSpreadHandler
+	}
+	source struct {
+		depths       *depth_types.Depths
+		subscription *depth_types.Subscription
+		bestAsk      *Quote
+		bestBid      *Quote
+	}
+)
+
+func (l *Level) Less(than btree.Item) bool {
+	return l.key < than.(*Level).key
+}
+
+// GetSourceQuantity повертає кількість рівня від джерела name
+func (l *Level) GetSourceQuantity(name string) items_types.QuantityType {
+	return l.Sources[name]
+}
+
+func New(degree int) *Book {
+	return &Book{
+		degree:  degree,
+		asks:    btree.New(degree),
+		bids:    btree.New(degree),
+		sources: make(map[string]*source),
+	}
+}
+
+// Add додає джерело під назвою name.
+// Блокує depths на час первинного заповнення, тому не можна викликати під блокуванням depths.
+func (b *Book) Add(name string, depths *depth_types.Depths) (err error) {
+	b.mutex.Lock()
+	if _, ok := b.sources[name]; ok {
+		b.mutex.Unlock()
+		return fmt.Errorf("source %v is already added", name)
+	}
+	src := &source{depths: depths}
+	b.sources[name] = src
+	b.mutex.Unlock()
+
+	depths.Lock()
+	defer depths.Unlock()
+	b.mutex.Lock()
+	b.rebuild(name, src)
+	b.mutex.Unlock()
+	src.subscription = depths.SubscribeCallback(b.handlerCreator(name, src))
+	return
+}
+
+// Remove прибирає джерело та його кількість зі зведених рівнів
+func (b *Book) Remove(name string) {
+	b.mutex.Lock()
+	src, ok := b.sources[name]
+	if !ok {
+		b.mutex.Unlock()
+		return
+	}
+	delete(b.sources, name)
+	b.removeSource(name)
+	b.mutex.Unlock()
+	src.depths.Unsubscribe(src.subscription)
+}
+
+// AddCrossedSpreadHandler додає обробник, який викликається, коли після зміни найкращих цін
+// bid одного джерела перевищує ask іншого
+func (b *Book) AddCrossedSpreadHandler(handler CrossedSpreadHandler) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.crossHandlers = append(b.crossHandlers, handler)
+}
+
+// GetBestAsk повертає найнижчий зведений ask з розподілом по джерелах
+func (b *Book) GetBestAsk() *Level {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if item := b.asks.Min(); item != nil {
+		return item.(*Level).copy()
+	}
+	return nil
+}
+
+// GetBestBid повертає найвищий зведений bid з розподілом по джерелах
+func (b *Book) GetBestBid() *Level {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if item := b.bids.Max(); item != nil {
+		return item.(*Level).copy()
+	}
+	return nil
+}
+
+// GetBestQuotes повертає найкращі ціни кожного джерела
+func (b *Book) GetBestQuotes(side types.DepthSide) (quotes []Quote) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	for _, src := range b.sources {
+		quote := src.bestBid
+		if side == types.DepthSideAsk {
+			quote = src.bestAsk
+		}
+		if quote != nil {
+			quotes = append(quotes, *quote)
+		}
+	}
+	sort.Slice(quotes, func(i, j int) bool {
+		if side == types.DepthSideAsk {
+			return quotes[i].Price < quotes[j].Price
+		}
+		return quotes[i].Price > quotes[j].Price
+	})
+	return
+}
+
+// GetLevels повертає до count зведених рівнів сторони, від найкращого
+func (b *Book) GetLevels(side types.DepthSide, count int) (levels []*Level) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	iterator := func(i btree.Item) bool {
+		if len(levels) >= count {
+			return false
+		}
+		levels = append(levels, i.(*Level).copy())
+		return true
+	}
+	if side == types.DepthSideAsk {
+		b.asks.Ascend(iterator)
+	} else {
+		b.bids.Descend(iterator)
+	}
+	return
+}
+
+// GetSummaQuantity повертає загальну кількість сторони та її розподіл по джерелах
+func (b *Book) GetSummaQuantity(side types.DepthSide) (summa items_types.QuantityType, sources map[string]items_types.QuantityType) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	sources = make(map[string]items_types.QuantityType)
+	tree := b.bids
+	if side == types.DepthSideAsk {
+		tree = b.asks
+	}
+	tree.Ascend(func(i btree.Item) bool {
+		level := i.(*Level)
+		summa += level.Quantity
+		for name, quantity := range level.Sources {
+			sources[name] += quantity
+		}
+		return true
+	})
+	return
+}
+
+// GetCrossedSpreads повертає всі пари джерел, де bid одного вищий за ask іншого
+func (b *Book) GetCrossedSpreads() []*CrossedSpread {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.crossedSpreads()
+}
+
+func (b *Book) crossedSpreads() (crossed []*CrossedSpread) {
+	for bidName, bidSource := range b.sources {
+		if bidSource.bestBid == nil {
+			continue
+		}
+		for askName, askSource := range b.sources {
+			if askName == bidName || askSource.bestAsk == nil {
+				continue
+			}
+			if bidSource.bestBid.Price > askSource.bestAsk.Price {
+				spread := bidSource.bestBid.Price - askSource.bestAsk.Price
+				crossed = append(crossed, &CrossedSpread{
+					Bid:           *bidSource.bestBid,
+					Ask:           *askSource.bestAsk,
+					Spread:        spread,
+					SpreadPercent: items_types.PricePercentType(spread * 100 / askSource.bestAsk.Price),
+				})
+			}
+		}
+	}
+	sort.Slice(crossed, func(i, j int) bool { return crossed[i].Spread > crossed[j].Spread })
+	return
+}
+
+// Обробник викликається під блокуванням джерела, тому стакан джерела можна читати напряму
+func (b *Book) handlerCreator(name string, src *source) depth_types.DepthEventHandler {
+	return func(event *depth_types.DepthEvent) {
+		b.mutex.Lock()
+		if _, ok := b.sources[name]; !ok {
+			b.mutex.Unlock()
+			return
+		}
+		var crossed []*CrossedSpread
+		switch event.Type {
+		case depth_types.DepthEventResynced:
+			b.removeSource(name)
+			b.rebuild(name, src)
+		case depth_types.DepthEventLevelAdded, depth_types.DepthEventLevelChanged, depth_types.DepthEventLevelRemoved:
+			b.apply(name, event.Side, event.Price, event.Quantity)
+		case depth_types.DepthEventBestAskChanged, depth_types.DepthEventBestBidChanged:
+			quote := &Quote{Source: name, Price: event.Price, Quantity: event.Quantity}
+			if event.Quantity == 0 {
+				quote = nil
+			}
+			if event.Type == depth_types.DepthEventBestAskChanged {
+				src.bestAsk = quote
+			} else {
+				src.bestBid = quote
+			}
+			crossed = b.crossedSpreads()
+		}
+		handlers := b.crossHandlers
+		b.mutex.Unlock()
+		for _, c := range crossed {
+			for _, handler := range handlers {
+				handler(c)
+			}
+		}
+	}
+}
+
+func (b *Book) apply(name string, side types.DepthSide, price items_types.PriceType, quantity items_types.QuantityType) {
+	tree := b.bids
+	if side == types.DepthSideAsk {
+		tree = b.asks
+	}
+	key := &Level{key: items_types.PriceKey(price)}
+	var level *Level
+	if item := tree.Get(key); item != nil {
+		level = item.(*Level)
+	} else {
+		if quantity == 0 {
+			return
+		}
+		level = &Level{Price: price, key: key.key, Sources: make(map[string]items_types.QuantityType)}
+		tree.ReplaceOrInsert(level)
+	}
+	level.Quantity += quantity - level.Sources[name]
+	if quantity == 0 {
+		delete(level.Sources, name)
+	} else {
+		level.Sources[name] = quantity
+	}
+	if len(level.Sources) == 0 {
+		tree.Delete(level)
+	}
+}
+
+func (b *Book) rebuild(name string, src *source) {
+	src.bestAsk, src.bestBid = nil, nil
+	src.depths.GetAsks().GetTree().Ascend(func(i btree.Item) bool {
+		item := i.(*items_types.DepthItem)
+		if src.bestAsk == nil {
+			src.bestAsk = &Quote{Source: name, Price: item.GetPrice(), Quantity: item.GetQuantity()}
+		}
+		b.apply(name, types.DepthSideAsk, item.GetPrice(), item.GetQuantity())
+		return true
+	})
+	src.depths.GetBids().GetTree().Descend(func(i btree.Item) bool {
+		item := i.(*items_types.DepthItem)
+		if src.bestBid == nil {
+			src.bestBid = &Quote{Source: name, Price: item.GetPrice(), Quantity: item.GetQuantity()}
+		}
+		b.apply(name, types.DepthSideBid, item.GetPrice(), item.GetQuantity())
+		return true
+	})
+}
+
+func (b *Book) removeSource(name string) {
+	for _, tree := range []*btree.BTree{b.asks, b.bids} {
+		levels := make([]*Level, 0)
+		tree.Ascend(func(i btree.Item) bool {
+			if _, ok := i.(*Level).Sources[name]; ok {
+				levels = append(levels, i.(*Level))
+			}
+			return true
+		})
+		for _, level := range levels {
+			level.Quantity -= level.Sources[name]
+			delete(level.Sources, name)
+			if len(level.Sources) == 0 {
+				tree.Delete(level)
+			}
+		}
+	}
+}
+
+func (l *Level) copy() *Level {
+	sources := make(map[string]items_types.QuantityType, len(l.Sources))
+	for name, quantity := range l.Sources {
+		sources[name] = quantity
+	}
+	return &Level{Price: l.Price, Quantity: l.Quantity, Sources: sources, key: l.key}
+}
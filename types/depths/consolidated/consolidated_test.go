@@ -0,0 +1,69 @@
+package consolidated_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	consolidated_types "github.com/fr0ster/go-trading-utils/types/depths/consolidated"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func TestConsolidatedBook(t *testing.T) {
+	spot := depth_types.New(3, "BTCUSDT", nil, nil)
+	spot.ApplySnapshot(1,
+		[]*items_types.Bid{items_types.NewBid(100, 1), items_types.NewBid(99, 2)},
+		[]*items_types.Ask{items_types.NewAsk(101, 1), items_types.NewAsk(102, 2)})
+	futures := depth_types.New(3, "BTCUSDT", nil, nil)
+	futures.ApplySnapshot(1,
+		[]*items_types.Bid{items_types.NewBid(100, 5), items_types.NewBid(98, 1)},
+		[]*items_types.Ask{items_types.NewAsk(102, 3), items_types.NewAsk(103, 1)})
+
+	book := consolidated_types.New(3)
+	assert.Nil(t, book.Add("spot", spot))
+	assert.Nil(t, book.Add("futures", futures))
+	assert.NotNil(t, book.Add("spot", spot))
+
+	bid := book.GetBestBid()
+	assert.Equal(t, items_types.PriceType(100), bid.Price)
+	assert.Equal(t, items_types.QuantityType(6), bid.Quantity)
+	assert.Equal(t, items_types.QuantityType(1), bid.GetSourceQuantity("spot"))
+	assert.Equal(t, items_types.QuantityType(5), bid.GetSourceQuantity("futures"))
+	ask := book.GetBestAsk()
+	assert.Equal(t, map[string]items_types.QuantityType{"spot": 1}, ask.Sources)
+	levels := book.GetLevels(types.DepthSideAsk, 2)
+	assert.Equal(t, items_types.QuantityType(5), levels[1].Quantity)
+	summa, sources := book.GetSummaQuantity(types.DepthSideBid)
+	assert.Equal(t, items_types.QuantityType(9), summa)
+	assert.Equal(t, items_types.QuantityType(6), sources["futures"])
+	assert.Empty(t, book.GetCrossedSpreads())
+
+	crossed := make([]*consolidated_types.CrossedSpread, 0)
+	book.AddCrossedSpreadHandler(func(c *consolidated_types.CrossedSpread) {
+		crossed = append(crossed, c)
+	})
+	// Bid ф'ючерсів перевищує ask споту
+	futures.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 1, LastUpdateID: 2, PrevLastUpdateID: 1,
+		Bids: []items_types.PriceLevel{{Price: 101.5, Quantity: 2}}})
+	assert.NotEmpty(t, crossed)
+	assert.Equal(t, "futures", crossed[0].Bid.Source)
+	assert.Equal(t, "spot", crossed[0].Ask.Source)
+	assert.Equal(t, items_types.PriceType(0.5), crossed[0].Spread)
+	assert.Equal(t, 1, len(book.GetCrossedSpreads()))
+	assert.Equal(t, "futures", book.GetBestQuotes(types.DepthSideBid)[0].Source)
+
+	// Ресинхронізація джерела перебудовує тільки його рівні
+	futures.ApplySnapshot(10,
+		[]*items_types.Bid{items_types.NewBid(97, 1)},
+		[]*items_types.Ask{items_types.NewAsk(104, 1)})
+	assert.Equal(t, map[string]items_types.QuantityType{"spot": 1}, book.GetBestBid().Sources)
+	assert.Empty(t, book.GetCrossedSpreads())
+
+	book.Remove("spot")
+	assert.Equal(t, items_types.PriceType(97), book.GetBestBid().Price)
+	spot.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 2, LastUpdateID: 2,
+		Bids: []items_types.PriceLevel{{Price: 100.5, Quantity: 1}}})
+	assert.Equal(t, items_types.PriceType(97), book.GetBestBid().Price)
+}
@@ -25,18 +25,23 @@ func New(
 		stop = make(chan struct{}, 1)
 	}
 	this := &Depths{
-		symbol:           symbol,
-		degree:           degree,
-		asks:             asks_types.New(degree, symbol),
-		bids:             bids_types.New(degree, symbol),
-		mutex:            &sync.Mutex{},
-		stop:             stop,
-		resetEvent:       make(chan error, 1),
-		isStartedStream:  false,
-		timeOut:          1 * time.Hour,
-		startDepthStream: nil,
-		Init:             nil,
+		symbol:              symbol,
+		degree:              degree,
+		asks:                asks_types.New(degree, symbol),
+		bids:                bids_types.New(degree, symbol),
+		mutex:               &sync.Mutex{},
+		maxSyncBuffer:       defaultMaxSyncBuffer,
+		resyncRetryInterval: DefaultResyncRetryInterval,
+		groupedViews:        make(map[groupedKey]*GroupedView),
+		stop:                stop,
+		resetEvent:          make(chan error, 1),
+		isStartedStream:     false,
+		timeOut:             1 * time.Hour,
+		startDepthStream:    nil,
+		Init:                nil,
 	}
+	this.asks.AddChangeHandler(this.levelChangeHandler(types.DepthSideAsk))
+	this.bids.AddChangeHandler(this.levelChangeHandler(types.DepthSideBid))
 	this.SetStartDepthStream(startDepthStreamCreator)
 	this.SetInit(initCreator)
 	return this
@@ -286,6 +286,158 @@ func TestAskAndBidSummaQuantity(t *testing.T) {
 	}()
 }
 
+func TestEstimateBuyAndSell(t *testing.T) {
+	ds := depth_types.New(degree, "BTCUSDT", nil, nil)
+	initDepths(ds)
+	mid, err := ds.GetMidPrice()
+	assert.Nil(t, err)
+	assert.Equal(t, items_types.PriceType(550), mid)
+
+	buy := ds.EstimateBuyByQuantity(15)
+	assert.Equal(t, items_types.PriceType(600), buy.BestPrice)
+	assert.Equal(t, items_types.PriceType(700), buy.WorstPrice)
+	assert.Equal(t, items_types.ValueType(9500), buy.Value)
+	assert.InDelta(t, (9500.0/15-550)/550*10000, buy.SlippageMidBps, 1e-9)
+	assert.Nil(t, ds.CheckImpact(buy, 2000))
+	assert.NotNil(t, ds.CheckImpact(buy, 100))
+
+	sell := ds.EstimateSellByValue(9000)
+	assert.Equal(t, items_types.QuantityType(20), sell.Quantity)
+	assert.Equal(t, items_types.PriceType(400), sell.WorstPrice)
+	assert.InDelta(t, (550-450.0)/550*10000, sell.SlippageMidBps, 1e-9)
+
+	assert.NotNil(t, ds.CheckImpact(ds.EstimateSellByQuantity(1000), 100000))
+}
+
+func TestGroupedView(t *testing.T) {
+	ds := depth_types.New(degree, "BTCUSDT", nil, nil)
+	initDepths(ds)
+	view, err := ds.GetGroupedView(1000, false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, view.Asks.Count())
+	assert.Equal(t, items_types.QuantityType(80), view.Asks.Get(600).GetQuantity())
+	assert.Equal(t, 1, view.Bids.Count())
+
+	same, err := ds.GetGroupedView(1000, false)
+	assert.Nil(t, err)
+	assert.Equal(t, view, same)
+
+	// Представлення оновлюється разом зі стаканом
+	ds.GetAsks().Update(items_types.NewAsk(800, 0))
+	ds.GetAsks().Update(items_types.NewAsk(650, 25))
+	assert.Equal(t, items_types.QuantityType(75), view.Asks.Get(600).GetQuantity())
+	assert.Equal(t, items_types.PriceType(650), view.Asks.Get(600).GetMaxDepth().GetPrice())
+	ds.GetBids().Clear()
+	assert.Equal(t, 0, view.Bids.Count())
+
+	_, err = ds.GetGroupedView(3, false)
+	assert.NotNil(t, err)
+}
+
+func TestDepthAnalytics(t *testing.T) {
+	ds := depth_types.New(degree, "BTCUSDT", nil, nil)
+	initDepths(ds)
+	// asks 600:10 700:20, bids 500:10 400:20
+	assert.InDelta(t, 0.0, ds.GetImbalance(2), 1e-9)
+	ds.GetBids().Update(items_types.NewBid(500, 30))
+	assert.InDelta(t, (50.0-30.0)/80.0, ds.GetImbalance(2), 1e-9)
+	assert.Equal(t, items_types.PriceType((500*10+600*30)/40.0), ds.GetMicroPrice())
+	assert.InDelta(t, float64((20000.0/30+23000.0/50)/2), float64(ds.GetWeightedMidPrice(2)), 1e-9)
+	asks, bids := ds.GetDepthByPercent(items_types.PricePercentType(100.0 * 160 / 550))
+	assert.Equal(t, items_types.QuantityType(30), asks)
+	assert.Equal(t, items_types.QuantityType(50), bids)
+	// Рівні 700 та 400 на межі не враховуються, як і у вікні SetBounds
+	asks, bids = ds.GetDepthByPercent(items_types.PricePercentType(100.0 * 150 / 550))
+	assert.Equal(t, items_types.QuantityType(10), asks)
+	assert.Equal(t, items_types.QuantityType(30), bids)
+	ds.SetBounds(0, items_types.PricePercentType(100.0*150/550))
+	assert.Nil(t, ds.GetAsks().Get(items_types.NewAsk(700)))
+	assert.Nil(t, ds.GetBids().Get(items_types.NewBid(400)))
+	ds.SetBounds(0, 0)
+	ds.GetAsks().Set(items_types.NewAsk(700.0, 20.0))
+	ds.GetBids().Set(items_types.NewBid(400.0, 20.0))
+
+	ds.EnableAnalytics(2, 10, 2)
+	first := ds.UpdateAnalytics()
+	assert.Equal(t, 0.0, first.OrderFlowImbalance)
+	// Кількість на найкращому bid зросла, ask без змін
+	ds.GetBids().Update(items_types.NewBid(500, 40))
+	second := ds.UpdateAnalytics()
+	assert.Equal(t, 10.0, second.OrderFlowImbalance)
+	// Найкращий ask знято
+	ds.GetAsks().Update(items_types.NewAsk(600, 0))
+	third := ds.UpdateAnalytics()
+	assert.Equal(t, 10.0, third.OrderFlowImbalance)
+	assert.Equal(t, third, ds.GetSignals())
+	assert.Equal(t, []*depth_types.DepthSignals{second, third}, ds.GetSignalsHistory())
+	// Історія зберігає щонайменше останнє значення
+	ds.EnableAnalytics(2, 10, -1)
+	fourth := ds.UpdateAnalytics()
+	assert.Equal(t, fourth, ds.GetSignals())
+	assert.Equal(t, []*depth_types.DepthSignals{fourth}, ds.GetSignalsHistory())
+}
+
+func TestSnapshot(t *testing.T) {
+	ds := depth_types.New(degree, "BTCUSDT", nil, nil)
+	initDepths(ds)
+	ds.LastUpdateID = 10
+	snapshot := ds.Snapshot()
+	assert.Equal(t, int64(10), snapshot.GetLastUpdateID())
+	assert.False(t, snapshot.GetTime().IsZero())
+
+	// Зміни стакану після знімку не впливають на знімок
+	ds.Lock()
+	ds.GetAsks().Update(items_types.NewAsk(600, 0))
+	ds.GetBids().Update(items_types.NewBid(550, 5))
+	ds.LastUpdateID = 11
+	ds.Unlock()
+
+	assert.Equal(t, 5, snapshot.GetAsks().Count())
+	assert.Equal(t, items_types.QuantityType(90), snapshot.GetAsks().GetSummaQuantity())
+	assert.NotNil(t, snapshot.GetAsks().Get(items_types.NewAsk(600)))
+	assert.Nil(t, snapshot.GetBids().Get(items_types.NewBid(550)))
+	mid, err := snapshot.GetMidPrice()
+	assert.Nil(t, err)
+	assert.Equal(t, items_types.PriceType(550), mid)
+
+	assert.Equal(t, 4, ds.GetAsks().Count())
+	assert.NotNil(t, ds.GetBids().Get(items_types.NewBid(550)))
+	assert.Equal(t, int64(11), ds.Snapshot().GetLastUpdateID())
+}
+
+func TestBounds(t *testing.T) {
+	ds := depth_types.New(degree, "BTCUSDT", nil, nil)
+	initDepths(ds)
+	// asks 600..1000, bids 100..500, середина 550
+	ds.SetBounds(3, 0)
+	assert.Equal(t, 3, ds.GetAsks().Count())
+	assert.Equal(t, items_types.ValueType(600*10+700*20+800*30), ds.GetAsks().GetSummaValue())
+	assert.Equal(t, items_types.QuantityType(60), ds.GetBids().GetSummaQuantity())
+	assert.NotNil(t, ds.GetBids().Get(items_types.NewBid(300)))
+	assert.Nil(t, ds.GetBids().Get(items_types.NewBid(200)))
+
+	ds.SetBounds(0, 20)
+	levels, percent := ds.GetBounds()
+	assert.Equal(t, 0, levels)
+	assert.Equal(t, items_types.PricePercentType(20), percent)
+	// 550 ± 110
+	assert.Equal(t, 1, ds.GetAsks().Count())
+	assert.Equal(t, items_types.QuantityType(10), ds.GetAsks().GetSummaQuantity())
+	assert.Equal(t, 1, ds.GetBids().Count())
+	assert.Equal(t, items_types.ValueType(5000), ds.GetBids().GetSummaValue())
+
+	// Обрізання після кожної події стріму
+	ds.SetBounds(1, 0)
+	ds.ApplySnapshot(1,
+		[]*items_types.Bid{items_types.NewBid(500, 1), items_types.NewBid(400, 1)},
+		[]*items_types.Ask{items_types.NewAsk(600, 1), items_types.NewAsk(700, 1)})
+	assert.Equal(t, 1, ds.GetAsks().Count())
+	ds.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 2, LastUpdateID: 2,
+		Asks: []items_types.PriceLevel{{Price: 590, Quantity: 2}}})
+	assert.Equal(t, 1, ds.GetAsks().Count())
+	assert.Equal(t, items_types.ValueType(1180), ds.GetAsks().GetSummaValue())
+}
+
 // func TestAskAndBidMinMaxQuantity(t *testing.T) {
 // 	func() {
 // 		ds := depth_types.New(degree, "BTCUSDT", nil, nil)
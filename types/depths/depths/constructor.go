@@ -4,6 +4,8 @@ import (
 	"sync"
 
 	"github.com/google/btree"
+
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
 )
 
 // DepthBTree - B-дерево для зберігання стакана заявок
@@ -18,6 +20,9 @@ func New(degree int, symbol string) *Depths {
 		countQuantity: 0,
 		summaQuantity: 0,
 		summaValue:    0,
+
+		generation: nextGeneration(),
+		probe:      items_types.New(0),
 	}
 }
 
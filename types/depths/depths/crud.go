@@ -19,7 +19,9 @@ func (a *Depths) Get(item *items_types.DepthItem) *items_types.DepthItem {
 
 // Set implements depth_interface.Depths.
 func (d *Depths) Set(item *items_types.DepthItem) (err error) {
-	if old := d.tree.Get(item); old != nil {
+	var oldQuantity items_types.QuantityType
+	if old := d.tree.ReplaceOrInsert(item); old != nil {
+		oldQuantity = old.(*items_types.DepthItem).GetQuantity()
 		d.summaQuantity += item.GetQuantity() - old.(*items_types.DepthItem).GetQuantity()
 		d.summaValue += item.GetValue() - old.(*items_types.DepthItem).GetValue()
 	} else {
@@ -27,18 +29,17 @@ func (d *Depths) Set(item *items_types.DepthItem) (err error) {
 		d.summaValue += item.GetValue()
 		d.countQuantity++
 	}
-	d.tree.ReplaceOrInsert(item)
+	d.notify(item.GetPrice(), oldQuantity, item.GetQuantity())
 	return
 }
 
 // Delete implements depth_interface.Depths.
 func (d *Depths) Delete(item *items_types.DepthItem) {
-	old := d.tree.Get(item)
-	if old != nil {
+	if old := d.tree.Delete(item); old != nil {
 		d.summaQuantity -= old.(*items_types.DepthItem).GetQuantity()
 		d.summaValue -= old.(*items_types.DepthItem).GetValue()
 		d.countQuantity--
-		d.tree.Delete(item)
+		d.notify(old.(*items_types.DepthItem).GetPrice(), old.(*items_types.DepthItem).GetQuantity(), 0)
 	}
 }
 
@@ -52,6 +53,55 @@ func (d *Depths) Update(item *items_types.DepthItem) bool {
 	return true
 }
 
+// UpdateLevel встановлює кількість рівня price, нульова кількість видаляє рівень.
+// Існуючий рівень змінюється на місці за один пошук, нові рівні беруться з пулу,
+// тому в усталеному режимі оновлення не алокує пам'ять.
+// Рівні, отримані раніше через Get/GetMinPrice тощо, після оновлення можуть бути змінені
+// або перевикористані, для читання між оновленнями потрібно використовувати Clone.
+func (d *Depths) UpdateLevel(price items_types.PriceType, quantity items_types.QuantityType) {
+	d.ApplyLevel(items_types.PriceLevel{Price: price, Quantity: quantity})
+}
+
+// ApplyLevel - UpdateLevel для рівня з ключем дерева, розібраним з точної ціни API
+func (d *Depths) ApplyLevel(level items_types.PriceLevel) {
+	price, quantity := level.Price, level.Quantity
+	d.probe.SetPriceLevel(level)
+	if quantity == 0 {
+		old := d.tree.Delete(d.probe)
+		if old == nil {
+			return
+		}
+		item := old.(*items_types.DepthItem)
+		d.summaQuantity -= item.GetQuantity()
+		d.summaValue -= item.GetValue()
+		d.countQuantity--
+		d.notify(item.GetPrice(), item.GetQuantity(), 0)
+		if item.GetGeneration() == d.generation {
+			items_types.Release(item)
+		}
+		return
+	}
+	if old := d.tree.Get(d.probe); old != nil {
+		item := old.(*items_types.DepthItem)
+		oldQuantity := item.GetQuantity()
+		d.summaQuantity += quantity - oldQuantity
+		d.summaValue += items_types.ValueType(quantity-oldQuantity) * items_types.ValueType(item.GetPrice())
+		if item.GetGeneration() == d.generation {
+			item.SetQuantity(quantity)
+		} else {
+			// Рівень створено поза пулом або він спільний з копією стакану
+			d.tree.ReplaceOrInsert(items_types.Acquire(item.GetPrice(), quantity, d.generation))
+		}
+		d.notify(item.GetPrice(), oldQuantity, quantity)
+		return
+	}
+	d.tree.ReplaceOrInsert(items_types.AcquireLevel(level, d.generation))
+	d.summaQuantity += quantity
+	d.summaValue += items_types.ValueType(quantity) * items_types.ValueType(price)
+	d.countQuantity++
+	d.notify(price, 0, quantity)
+}
+
 // Count implements depth_interface.Depths.
 func (d *Depths) Count() int {
 	return d.countQuantity
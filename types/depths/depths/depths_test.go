@@ -458,3 +458,172 @@ func TestGetFiltered(t *testing.T) {
 	assert.Equal(t, items_types.PriceType(200), min.GetPrice())
 	assert.Equal(t, items_types.PriceType(300), max.GetPrice())
 }
+
+func TestEstimateByQuantityAndValue(t *testing.T) {
+	depth := depths_types.New(degree, "BTCUSDT")
+	depth.Set(items_types.New(100, 1))
+	depth.Set(items_types.New(200, 2))
+	depth.Set(items_types.New(300, 3))
+
+	estimate := depth.EstimateByQuantity(2, depths_types.UP)
+	assert.Equal(t, items_types.QuantityType(2), estimate.Quantity)
+	assert.Equal(t, items_types.ValueType(300), estimate.Value)
+	assert.Equal(t, items_types.PriceType(150), estimate.AveragePrice)
+	assert.Equal(t, items_types.PriceType(100), estimate.BestPrice)
+	assert.Equal(t, items_types.PriceType(200), estimate.WorstPrice)
+	assert.Equal(t, 2, estimate.Levels)
+	assert.Equal(t, 5000.0, estimate.SlippageBestBps)
+	assert.True(t, estimate.IsFilled())
+
+	estimate = depth.EstimateByQuantity(10, depths_types.DOWN)
+	assert.Equal(t, items_types.QuantityType(6), estimate.Quantity)
+	assert.Equal(t, items_types.QuantityType(4), estimate.UnfilledQuantity)
+	assert.Equal(t, 3, estimate.Levels)
+	assert.False(t, estimate.IsFilled())
+
+	estimate = depth.EstimateByValue(1300, depths_types.DOWN)
+	assert.Equal(t, items_types.QuantityType(5), estimate.Quantity)
+	assert.Equal(t, items_types.ValueType(1300), estimate.Value)
+	assert.Equal(t, items_types.PriceType(200), estimate.WorstPrice)
+	assert.Equal(t, items_types.ValueType(0), estimate.UnfilledValue)
+}
+
+func TestEstimateByValuePartialLevelIsExact(t *testing.T) {
+	depth := depths_types.New(degree, "BTCUSDT")
+	depth.Set(items_types.New(1.1, 10))
+	depth.Set(items_types.New(2, 10))
+
+	// 10.1 / 1.1 * 1.1 менше за 10.1 в float64
+	target := items_types.ValueType(10.1)
+	estimate := depth.EstimateByValue(target, depths_types.UP)
+	assert.Equal(t, 1, estimate.Levels)
+	assert.Equal(t, items_types.PriceType(1.1), estimate.WorstPrice)
+	assert.Equal(t, target, estimate.Value)
+	assert.Equal(t, items_types.ValueType(0), estimate.UnfilledValue)
+	assert.True(t, estimate.IsFilled())
+}
+
+func TestEstimateByQuantityPartialLevelIsExact(t *testing.T) {
+	depth := depths_types.New(degree, "BTCUSDT")
+	depth.Set(items_types.New(100, 0.1))
+	depth.Set(items_types.New(101, 0.1))
+	depth.Set(items_types.New(102, 1))
+
+	// 0.1 + 0.1 + (0.9 - 0.2) менше за 0.9 в float64
+	target := items_types.QuantityType(0.9)
+	estimate := depth.EstimateByQuantity(target, depths_types.UP)
+	assert.Equal(t, 3, estimate.Levels)
+	assert.Equal(t, target, estimate.Quantity)
+	assert.Equal(t, items_types.QuantityType(0), estimate.UnfilledQuantity)
+	assert.True(t, estimate.IsFilled())
+
+	// Рівень, що точно закриває залишок, також не залишає недовиконання
+	estimate = depth.EstimateByQuantity(0.1+0.1+1, depths_types.UP)
+	assert.Equal(t, 3, estimate.Levels)
+	assert.True(t, estimate.IsFilled())
+}
+
+func TestRestrictAndKeepLevels(t *testing.T) {
+	depth := depths_types.New(degree, "BTCUSDT")
+	for _, price := range []items_types.PriceType{100, 200, 300, 400, 500} {
+		depth.Set(items_types.New(price, 1))
+	}
+	depth.RestrictUp(400)
+	assert.Equal(t, 3, depth.Count())
+	assert.Equal(t, items_types.QuantityType(3), depth.GetSummaQuantity())
+	assert.Equal(t, items_types.ValueType(600), depth.GetSummaValue())
+
+	depth.RestrictDown(100)
+	assert.Equal(t, 2, depth.Count())
+	assert.Equal(t, items_types.ValueType(500), depth.GetSummaValue())
+
+	depth.Set(items_types.New(100, 1))
+	depth.KeepLevels(2, depths_types.DOWN)
+	assert.Equal(t, 2, depth.Count())
+	assert.Nil(t, depth.Get(items_types.New(100)))
+	assert.Equal(t, items_types.ValueType(500), depth.GetSummaValue())
+	depth.KeepLevels(1, depths_types.UP)
+	assert.NotNil(t, depth.Get(items_types.New(200)))
+	assert.Equal(t, items_types.ValueType(200), depth.GetSummaValue())
+}
+
+func TestUpdateLevel(t *testing.T) {
+	depth := depths_types.New(degree, "BTCUSDT")
+	depth.UpdateLevel(100, 10)
+	depth.UpdateLevel(200, 20)
+	depth.UpdateLevel(100, 15)
+	assert.Equal(t, 2, depth.Count())
+	assert.Equal(t, items_types.QuantityType(35), depth.GetSummaQuantity())
+	assert.Equal(t, items_types.ValueType(5500), depth.GetSummaValue())
+
+	// Копія не бачить змін на місці після Clone
+	clone := depth.Clone()
+	depth.UpdateLevel(100, 1)
+	depth.UpdateLevel(200, 0)
+	assert.Equal(t, items_types.QuantityType(15), clone.Get(items_types.New(100)).GetQuantity())
+	assert.Equal(t, items_types.QuantityType(20), clone.Get(items_types.New(200)).GetQuantity())
+	assert.Equal(t, items_types.QuantityType(1), depth.Get(items_types.New(100)).GetQuantity())
+	assert.Nil(t, depth.Get(items_types.New(200)))
+	assert.Equal(t, 1, depth.Count())
+	assert.Equal(t, items_types.ValueType(100), depth.GetSummaValue())
+
+	// Зміна існуючого рівня та видалення неіснуючого не алокують пам'ять
+	allocs := testing.AllocsPerRun(100, func() {
+		depth.UpdateLevel(100, 2)
+		depth.UpdateLevel(100, 3)
+		depth.UpdateLevel(300, 0)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestApplyLevel(t *testing.T) {
+	depth := depths_types.New(degree, "BTCUSDT")
+	low, _ := items_types.ParsePriceLevel("1234567891.12345678", "1")
+	high, _ := items_types.ParsePriceLevel("1234567891.12345679", "2")
+	depth.ApplyLevel(low)
+	depth.ApplyLevel(high)
+	assert.Equal(t, 2, depth.Count())
+
+	update, _ := items_types.ParsePriceLevel("1234567891.12345679", "5")
+	remove, _ := items_types.ParsePriceLevel("1234567891.12345678", "0")
+	allocs := testing.AllocsPerRun(100, func() {
+		depth.ApplyLevel(update)
+	})
+	assert.Zero(t, allocs)
+	depth.ApplyLevel(remove)
+	assert.Equal(t, 1, depth.Count())
+	assert.Equal(t, items_types.QuantityType(5), depth.GetSummaQuantity())
+}
+
+func BenchmarkUpdateLevel(b *testing.B) {
+	depth := depths_types.New(degree, "BTCUSDT")
+	for i := 0; i < 1000; i++ {
+		depth.UpdateLevel(items_types.PriceType(100+i), 1)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		price := items_types.PriceType(100 + i%1000)
+		if i%10 == 0 {
+			// Частина оновлень видаляє та повертає рівень
+			depth.UpdateLevel(price, 0)
+		}
+		depth.UpdateLevel(price, items_types.QuantityType(i%7+1))
+	}
+}
+
+func BenchmarkUpdateItem(b *testing.B) {
+	depth := depths_types.New(degree, "BTCUSDT")
+	for i := 0; i < 1000; i++ {
+		depth.Update(items_types.New(items_types.PriceType(100+i), 1))
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		price := items_types.PriceType(100 + i%1000)
+		if i%10 == 0 {
+			depth.Update(items_types.New(price, 0))
+		}
+		depth.Update(items_types.New(price, items_types.QuantityType(i%7+1)))
+	}
+}
@@ -0,0 +1,126 @@
+package depths
+
+import (
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	"github.com/google/btree"
+)
+
+type (
+	// ExecutionEstimate - оцінка виконання ринкового ордера проходом по стакану
+	ExecutionEstimate struct {
+		BestPrice        items_types.PriceType
+		AveragePrice     items_types.PriceType
+		WorstPrice       items_types.PriceType
+		Quantity         items_types.QuantityType
+		Value            items_types.ValueType
+		Levels           int
+		UnfilledQuantity items_types.QuantityType
+		UnfilledValue    items_types.ValueType
+		// Прослизання в базисних пунктах, додатне значення - гірше для нас
+		SlippageBestBps float64
+		// Заповнюється на рівні depth.Depths, бо потребує обох сторін стакану
+		MidPrice       items_types.PriceType
+		SlippageMidBps float64
+	}
+)
+
+// IsFilled - чи вистачає ліквідності для повного виконання
+func (e *ExecutionEstimate) IsFilled() bool {
+	return e.UnfilledQuantity == 0 && e.UnfilledValue == 0
+}
+
+// SetMidPrice рахує прослизання відносно середини спреду
+func (e *ExecutionEstimate) SetMidPrice(mid items_types.PriceType, up UpOrDown) {
+	e.MidPrice = mid
+	e.SlippageMidBps = slippageBps(e.AveragePrice, mid, up)
+}
+
+// EstimateByQuantity проходить по стакану, поки не набере targetQuantity базового активу,
+// останній рівень може бути використаний частково
+func (d *Depths) EstimateByQuantity(targetQuantity items_types.QuantityType, up UpOrDown) (estimate *ExecutionEstimate) {
+	estimate = &ExecutionEstimate{}
+	d.walk(up, func(item *items_types.DepthItem) bool {
+		rest := targetQuantity - estimate.Quantity
+		if rest <= 0 {
+			return false
+		}
+		if item.GetQuantity() >= rest {
+			// Останній рівень, Quantity фіксується точно,
+			// щоб похибка суми не залишала недовиконаний залишок
+			estimate.fill(item.GetPrice(), rest)
+			estimate.Quantity = targetQuantity
+			return false
+		}
+		estimate.fill(item.GetPrice(), item.GetQuantity())
+		return estimate.Quantity < targetQuantity
+	})
+	if estimate.Quantity < targetQuantity {
+		estimate.UnfilledQuantity = targetQuantity - estimate.Quantity
+	}
+	estimate.finish(up)
+	return
+}
+
+// EstimateByValue проходить по стакану, поки не витратить targetValue котирувального активу,
+// останній рівень може бути використаний частково
+func (d *Depths) EstimateByValue(targetValue items_types.ValueType, up UpOrDown) (estimate *ExecutionEstimate) {
+	estimate = &ExecutionEstimate{}
+	d.walk(up, func(item *items_types.DepthItem) bool {
+		rest := targetValue - estimate.Value
+		if rest <= 0 {
+			return false
+		}
+		if item.GetValue() >= rest {
+			// Рівень використовується частково, Value фіксується точно,
+			// щоб похибка quantity * price не залишала недовиконаний залишок
+			estimate.fill(item.GetPrice(), items_types.QuantityType(rest)/items_types.QuantityType(item.GetPrice()))
+			estimate.Value = targetValue
+			return false
+		}
+		estimate.fill(item.GetPrice(), item.GetQuantity())
+		return estimate.Value < targetValue
+	})
+	if estimate.Value < targetValue {
+		estimate.UnfilledValue = targetValue - estimate.Value
+	}
+	estimate.finish(up)
+	return
+}
+
+func (d *Depths) walk(up UpOrDown, iterator func(item *items_types.DepthItem) bool) {
+	f := func(i btree.Item) bool {
+		return iterator(i.(*items_types.DepthItem))
+	}
+	if up {
+		d.GetTree().Ascend(f)
+	} else {
+		d.GetTree().Descend(f)
+	}
+}
+
+func (e *ExecutionEstimate) fill(price items_types.PriceType, quantity items_types.QuantityType) {
+	if e.Levels == 0 {
+		e.BestPrice = price
+	}
+	e.WorstPrice = price
+	e.Quantity += quantity
+	e.Value += items_types.ValueType(price) * items_types.ValueType(quantity)
+	e.Levels++
+}
+
+func (e *ExecutionEstimate) finish(up UpOrDown) {
+	if e.Quantity > 0 {
+		e.AveragePrice = items_types.PriceType(e.Value) / items_types.PriceType(e.Quantity)
+	}
+	e.SlippageBestBps = slippageBps(e.AveragePrice, e.BestPrice, up)
+}
+
+func slippageBps(price, reference items_types.PriceType, up UpOrDown) float64 {
+	if reference == 0 || price == 0 {
+		return 0
+	}
+	if up {
+		return float64((price - reference) / reference * 10000)
+	}
+	return float64((reference - price) / reference * 10000)
+}
@@ -0,0 +1,22 @@
+package depths
+
+import (
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+// AddChangeHandler додає обробник змін рівнів стакану
+func (d *Depths) AddChangeHandler(handler ChangeHandler) {
+	if handler != nil {
+		d.changeHandlers = append(d.changeHandlers, handler)
+	}
+}
+
+func (d *Depths) notify(price items_types.PriceType, oldQuantity, newQuantity items_types.QuantityType) {
+	// Повторне встановлення тієї ж кількості не є зміною
+	if oldQuantity == newQuantity {
+		return
+	}
+	for _, handler := range d.changeHandlers {
+		handler(price, oldQuantity, newQuantity)
+	}
+}
@@ -1,6 +1,8 @@
 package depths
 
 import (
+	"sync"
+
 	"github.com/google/btree"
 
 	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
@@ -18,12 +20,19 @@ func (d *Depths) SetTree(tree *btree.BTree) {
 		d.summaQuantity += i.(*items_types.DepthItem).GetQuantity()
 		d.summaValue += i.(*items_types.DepthItem).GetValue()
 		d.countQuantity++
+		d.notify(i.(*items_types.DepthItem).GetPrice(), 0, i.(*items_types.DepthItem).GetQuantity())
 		return true
 	})
 }
 
 // Clear implements depth_interface.Depths.
 func (d *Depths) Clear() {
+	if len(d.changeHandlers) > 0 {
+		d.tree.Ascend(func(i btree.Item) bool {
+			d.notify(i.(*items_types.DepthItem).GetPrice(), i.(*items_types.DepthItem).GetQuantity(), 0)
+			return true
+		})
+	}
 	d.tree.Clear(false)
 	d.summaQuantity = 0
 	d.summaValue = 0
@@ -38,7 +47,7 @@ func (d *Depths) RestrictUp(price items_types.PriceType) {
 		return true
 	})
 	for _, p := range prices {
-		d.tree.Delete(items_types.New(p))
+		d.Delete(items_types.New(p))
 	}
 }
 
@@ -50,6 +59,50 @@ func (d *Depths) RestrictDown(price items_types.PriceType) {
 		return true
 	})
 	for _, p := range prices {
-		d.tree.Delete(items_types.New(p))
+		d.Delete(items_types.New(p))
+	}
+}
+
+// KeepLevels лишає тільки count рівнів, рахуючи від початку в напрямку up,
+// решта видаляється з оновленням сум та кількості рівнів
+func (d *Depths) KeepLevels(count int, up UpOrDown) {
+	if count < 0 || d.countQuantity <= count {
+		return
+	}
+	prices := make([]items_types.PriceType, 0, d.countQuantity-count)
+	index := 0
+	iterator := func(i btree.Item) bool {
+		if index >= count {
+			prices = append(prices, i.(*items_types.DepthItem).GetPrice())
+		}
+		index++
+		return true
+	}
+	if up {
+		d.tree.Ascend(iterator)
+	} else {
+		d.tree.Descend(iterator)
+	}
+	for _, p := range prices {
+		d.Delete(items_types.New(p))
+	}
+}
+
+// Clone повертає копію стакану на поточний момент.
+// Дерево копіюється ліниво (copy-on-write), тому виклик дешевий, а копію можна читати
+// паралельно зі змінами оригіналу. Обробники змін не копіюються.
+func (d *Depths) Clone() *Depths {
+	// Рівні тепер спільні, тому обидва дерева отримують нові покоління
+	d.generation = nextGeneration()
+	return &Depths{
+		symbol:        d.symbol,
+		degree:        d.degree,
+		tree:          d.tree.Clone(),
+		mutex:         &sync.Mutex{},
+		countQuantity: d.countQuantity,
+		summaQuantity: d.summaQuantity,
+		summaValue:    d.summaValue,
+		generation:    nextGeneration(),
+		probe:         items_types.New(0),
 	}
 }
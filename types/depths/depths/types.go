@@ -2,6 +2,7 @@ package depths
 
 import (
 	"sync"
+	"sync/atomic"
 
 	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
 	"github.com/google/btree"
@@ -14,7 +15,10 @@ const (
 
 type (
 	UpOrDown bool
-	Depths   struct {
+	// ChangeHandler викликається після кожної зміни рівня стакану,
+	// нульова кількість означає відсутність рівня до або після зміни
+	ChangeHandler func(price items_types.PriceType, oldQuantity, newQuantity items_types.QuantityType)
+	Depths        struct {
 		symbol string
 		degree int
 
@@ -24,5 +28,19 @@ type (
 		countQuantity int
 		summaQuantity items_types.QuantityType
 		summaValue    items_types.ValueType
+
+		changeHandlers []ChangeHandler
+
+		// Рівні поточного покоління належать тільки цьому дереву і змінюються на місці,
+		// Clone змінює покоління, щоб спільні з копією рівні більше не змінювались
+		generation uint64
+		// Ключ для пошуку рівня без алокації
+		probe *items_types.DepthItem
 	}
 )
+
+var generations atomic.Uint64
+
+func nextGeneration() uint64 {
+	return generations.Add(1)
+}
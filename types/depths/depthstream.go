@@ -17,8 +17,10 @@ func (d *Depths) IsStreamStarted() bool {
 	return d.isStartedStream
 }
 
+// StreamStart запускає стрім стакану.
+// Init потрібен тільки для diff-стріму, стакан з SetPartialDepth працює без нього.
 func (d *Depths) StreamStart() (err error) {
-	if d.Init == nil || d.startDepthStream == nil {
+	if d.startDepthStream == nil || (d.Init == nil && !d.partialDepth) {
 		err = errors.New("initial functions for Streams and Data are not initialized")
 		return
 	}
@@ -28,6 +30,9 @@ func (d *Depths) StreamStart() (err error) {
 	lastResponse := time.Now()
 	// Запускаємо стрім подій користувача
 	_, stopC, err := d.startDepthStream()
+	if err != nil {
+		return
+	}
 	// Запускаємо стрім для перевірки часу відповіді та оновлення стріму подій користувача при необхідності
 	go func() {
 		for {
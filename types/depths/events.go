@@ -0,0 +1,288 @@
+package depth
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+const (
+	DepthEventLevelAdded     DepthEventType = "LEVEL_ADDED"
+	DepthEventLevelChanged   DepthEventType = "LEVEL_CHANGED"
+	DepthEventLevelRemoved   DepthEventType = "LEVEL_REMOVED"
+	DepthEventBestBidChanged DepthEventType = "BEST_BID_CHANGED"
+	DepthEventBestAskChanged DepthEventType = "BEST_ASK_CHANGED"
+	DepthEventSpreadChanged  DepthEventType = "SPREAD_CHANGED"
+	DepthEventResynced       DepthEventType = "RESYNCED"
+	// Оновлення застосовано, навіть якщо жоден рівень не змінився.
+	// Надсилається тільки підписникам, які явно вказали цей тип.
+	DepthEventUpdated DepthEventType = "UPDATED"
+	// Перевірка цілісності знайшла порушення, деталі в Issues
+	DepthEventIntegrityFailed DepthEventType = "INTEGRITY_FAILED"
+)
+
+const (
+	// Якщо буфер підписника заповнений - відкидаємо нову подію
+	DropNewest DropPolicy = iota
+	// Якщо буфер підписника заповнений - відкидаємо найстарішу подію з буфера
+	DropOldest
+)
+
+type (
+	DepthEventType string
+	DropPolicy     int
+	// DepthEvent - нормалізована подія зміни стакану, не залежить від біржі та типу ринку
+	DepthEvent struct {
+		Type         DepthEventType
+		Symbol       string
+		Side         types.DepthSide
+		Price        items_types.PriceType
+		OldPrice     items_types.PriceType
+		Quantity     items_types.QuantityType
+		OldQuantity  items_types.QuantityType
+		Spread       items_types.PriceType
+		OldSpread    items_types.PriceType
+		LastUpdateID int64
+		Time         time.Time
+		Issues       []IntegrityIssue
+	}
+	DepthEventHandler func(event *DepthEvent)
+	// Subscription - підписка на події стакану з власним буфером
+	Subscription struct {
+		// mutex захищає закриття каналу від одночасного надсилання
+		mutex    sync.Mutex
+		closed   bool
+		events   chan *DepthEvent
+		callback DepthEventHandler
+		filter   map[DepthEventType]bool
+		policy   DropPolicy
+		dropped  int64
+	}
+	publisher struct {
+		mutex       sync.Mutex
+		subscribers []*Subscription
+		pending     []*DepthEvent
+		bestAsk     *items_types.DepthItem
+		bestBid     *items_types.DepthItem
+	}
+)
+
+// Subscribe створює підписку з каналом на bufferSize подій.
+// Якщо eventTypes не задано, підписник отримує всі події.
+func (d *Depths) Subscribe(bufferSize int, policy DropPolicy, eventTypes ...DepthEventType) *Subscription {
+	subscription := &Subscription{
+		events: make(chan *DepthEvent, bufferSize),
+		filter: newEventFilter(eventTypes),
+		policy: policy,
+	}
+	d.addSubscriber(subscription)
+	return subscription
+}
+
+// SubscribeCallback створює підписку з обробником, який викликається під блокуванням Depths,
+// тому обробник повинен бути швидким і не блокувати Depths повторно.
+func (d *Depths) SubscribeCallback(callback DepthEventHandler, eventTypes ...DepthEventType) *Subscription {
+	subscription := &Subscription{
+		callback: callback,
+		filter:   newEventFilter(eventTypes),
+	}
+	d.addSubscriber(subscription)
+	return subscription
+}
+
+// Unsubscribe прибирає підписку та закриває її канал.
+// Можна викликати з будь-якої горутини, зокрема під час розсилки подій.
+func (d *Depths) Unsubscribe(subscription *Subscription) {
+	d.publisher.mutex.Lock()
+	defer d.publisher.mutex.Unlock()
+	for i, s := range d.publisher.subscribers {
+		if s == subscription {
+			d.publisher.subscribers = append(d.publisher.subscribers[:i], d.publisher.subscribers[i+1:]...)
+			s.close()
+			return
+		}
+	}
+}
+
+// C повертає канал подій підписки
+func (s *Subscription) C() <-chan *DepthEvent {
+	return s.events
+}
+
+// GetDropped повертає кількість відкинутих через переповнення буфера подій
+func (s *Subscription) GetDropped() int64 {
+	return atomic.LoadInt64(&s.dropped)
+}
+
+func (s *Subscription) send(event *DepthEvent) {
+	if len(s.filter) > 0 && !s.filter[event.Type] || len(s.filter) == 0 && event.Type == DepthEventUpdated {
+		return
+	}
+	if s.callback != nil {
+		// Обробник викликається без блокування підписки, бо може сам відписатись
+		if !s.isClosed() {
+			s.callback(event)
+		}
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	select {
+	case s.events <- event:
+		return
+	default:
+	}
+	atomic.AddInt64(&s.dropped, 1)
+	if s.policy == DropOldest {
+		select {
+		case <-s.events:
+		default:
+		}
+		select {
+		case s.events <- event:
+		default:
+		}
+	}
+}
+
+func (s *Subscription) close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if s.events != nil {
+		close(s.events)
+	}
+}
+
+func (s *Subscription) isClosed() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.closed
+}
+
+func newEventFilter(eventTypes []DepthEventType) (filter map[DepthEventType]bool) {
+	filter = make(map[DepthEventType]bool, len(eventTypes))
+	for _, eventType := range eventTypes {
+		filter[eventType] = true
+	}
+	return
+}
+
+func (d *Depths) addSubscriber(subscription *Subscription) {
+	d.publisher.mutex.Lock()
+	defer d.publisher.mutex.Unlock()
+	d.publisher.subscribers = append(d.publisher.subscribers, subscription)
+}
+
+func (d *Depths) hasSubscribers() bool {
+	d.publisher.mutex.Lock()
+	defer d.publisher.mutex.Unlock()
+	return len(d.publisher.subscribers) > 0
+}
+
+// levelChangeHandler збирає зміни рівнів до завершення застосування оновлення
+func (d *Depths) levelChangeHandler(side types.DepthSide) func(price items_types.PriceType, oldQuantity, newQuantity items_types.QuantityType) {
+	return func(price items_types.PriceType, oldQuantity, newQuantity items_types.QuantityType) {
+		if !d.hasSubscribers() {
+			return
+		}
+		event := &DepthEvent{
+			Symbol:      d.symbol,
+			Side:        side,
+			Price:       price,
+			Quantity:    newQuantity,
+			OldQuantity: oldQuantity,
+		}
+		switch {
+		case oldQuantity == 0:
+			event.Type = DepthEventLevelAdded
+		case newQuantity == 0:
+			event.Type = DepthEventLevelRemoved
+		default:
+			event.Type = DepthEventLevelChanged
+		}
+		d.publisher.pending = append(d.publisher.pending, event)
+	}
+}
+
+// PublishEvents розсилає підписникам зміни, накопичені з попереднього виклику.
+// Викликається автоматично після застосування подій стріму, виклик повинен виконуватись під блокуванням Depths.
+// resynced - стакан було замінено знімком, тоді замість змін рівнів розсилається одна подія RESYNCED.
+func (d *Depths) PublishEvents(resynced bool) {
+	pending := d.publisher.pending
+	d.publisher.pending = nil
+	if !d.hasSubscribers() {
+		d.publisher.bestAsk, d.publisher.bestBid = nil, nil
+		return
+	}
+	now := time.Now()
+	events := make([]*DepthEvent, 0, len(pending)+4)
+	if resynced {
+		events = append(events, &DepthEvent{Type: DepthEventResynced, Symbol: d.symbol})
+	} else {
+		events = append(events, pending...)
+	}
+	bestAsk, _ := d.asks.GetMinPrice()
+	bestBid, _ := d.bids.GetMaxPrice()
+	oldSpread := spread(d.publisher.bestAsk, d.publisher.bestBid)
+	newSpread := spread(bestAsk, bestBid)
+	if event := bestChanged(DepthEventBestAskChanged, types.DepthSideAsk, d.publisher.bestAsk, bestAsk); event != nil {
+		events = append(events, event)
+	}
+	if event := bestChanged(DepthEventBestBidChanged, types.DepthSideBid, d.publisher.bestBid, bestBid); event != nil {
+		events = append(events, event)
+	}
+	if oldSpread != newSpread {
+		events = append(events, &DepthEvent{Type: DepthEventSpreadChanged, Spread: newSpread, OldSpread: oldSpread})
+	}
+	if !resynced {
+		events = append(events, &DepthEvent{Type: DepthEventUpdated})
+	}
+	d.publisher.bestAsk = copyItem(bestAsk)
+	d.publisher.bestBid = copyItem(bestBid)
+	d.send(events, now)
+}
+
+func (d *Depths) send(events []*DepthEvent, now time.Time) {
+	d.publisher.mutex.Lock()
+	subscribers := append([]*Subscription(nil), d.publisher.subscribers...)
+	d.publisher.mutex.Unlock()
+	for _, event := range events {
+		event.Symbol = d.symbol
+		event.LastUpdateID = d.LastUpdateID
+		event.Time = now
+		for _, subscriber := range subscribers {
+			subscriber.send(event)
+		}
+	}
+}
+
+func bestChanged(eventType DepthEventType, side types.DepthSide, old, new *items_types.DepthItem) *DepthEvent {
+	if old.GetPrice() == new.GetPrice() && old.GetQuantity() == new.GetQuantity() {
+		return nil
+	}
+	return &DepthEvent{
+		Type:        eventType,
+		Side:        side,
+		Price:       new.GetPrice(),
+		OldPrice:    old.GetPrice(),
+		Quantity:    new.GetQuantity(),
+		OldQuantity: old.GetQuantity(),
+	}
+}
+
+func spread(ask, bid *items_types.DepthItem) items_types.PriceType {
+	if ask == nil || bid == nil {
+		return 0
+	}
+	return ask.GetPrice() - bid.GetPrice()
+}
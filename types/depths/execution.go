@@ -0,0 +1,63 @@
+package depth
+
+import (
+	"fmt"
+
+	depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+// GetMidPrice - середина між найкращими цінами продажу та купівлі
+func (d *Depths) GetMidPrice() (mid items_types.PriceType, err error) {
+	ask, err := d.asks.GetMinPrice()
+	if err != nil {
+		return
+	}
+	bid, err := d.bids.GetMaxPrice()
+	if err != nil {
+		return
+	}
+	mid = (ask.GetPrice() + bid.GetPrice()) / 2
+	return
+}
+
+// EstimateBuyByQuantity - оцінка ринкової купівлі quantity базового активу по asks
+func (d *Depths) EstimateBuyByQuantity(quantity items_types.QuantityType) *depths_types.ExecutionEstimate {
+	return d.withMidPrice(d.asks.EstimateByQuantity(quantity), depths_types.UP)
+}
+
+// EstimateBuyByValue - оцінка ринкової купівлі на value котирувального активу по asks
+func (d *Depths) EstimateBuyByValue(value items_types.ValueType) *depths_types.ExecutionEstimate {
+	return d.withMidPrice(d.asks.EstimateByValue(value), depths_types.UP)
+}
+
+// EstimateSellByQuantity - оцінка ринкового продажу quantity базового активу по bids
+func (d *Depths) EstimateSellByQuantity(quantity items_types.QuantityType) *depths_types.ExecutionEstimate {
+	return d.withMidPrice(d.bids.EstimateByQuantity(quantity), depths_types.DOWN)
+}
+
+// EstimateSellByValue - оцінка ринкового продажу на value котирувального активу по bids
+func (d *Depths) EstimateSellByValue(value items_types.ValueType) *depths_types.ExecutionEstimate {
+	return d.withMidPrice(d.bids.EstimateByValue(value), depths_types.DOWN)
+}
+
+// CheckImpact повертає помилку, якщо ордер не виконується повністю
+// або прослизання відносно середини спреду перевищує maxSlippageBps
+func (d *Depths) CheckImpact(estimate *depths_types.ExecutionEstimate, maxSlippageBps float64) (err error) {
+	if !estimate.IsFilled() {
+		err = fmt.Errorf("not enough liquidity in %v depth, unfilled quantity %f, unfilled value %f",
+			d.symbol, estimate.UnfilledQuantity, estimate.UnfilledValue)
+		return
+	}
+	if estimate.SlippageMidBps > maxSlippageBps {
+		err = fmt.Errorf("slippage %f bps is more than limit %f bps", estimate.SlippageMidBps, maxSlippageBps)
+	}
+	return
+}
+
+func (d *Depths) withMidPrice(estimate *depths_types.ExecutionEstimate, up depths_types.UpOrDown) *depths_types.ExecutionEstimate {
+	if mid, err := d.GetMidPrice(); err == nil {
+		estimate.SetMidPrice(mid, up)
+	}
+	return estimate
+}
@@ -0,0 +1,53 @@
+package depth
+
+import (
+	"github.com/google/btree"
+
+	grouped_types "github.com/fr0ster/go-trading-utils/types/depths/grouped"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+type (
+	// GroupedView - згруповані asks та bids з однаковим кроком
+	GroupedView struct {
+		Asks *grouped_types.Grouped
+		Bids *grouped_types.Grouped
+	}
+	groupedKey struct {
+		step    items_types.PriceType
+		roundUp bool
+	}
+)
+
+// GetGroupedView повертає згруповане представлення стакану з кроком step (0.1, 1, 10 ...).
+// Представлення створюється при першому запиті і далі оновлюється при кожній зміні стакану.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) GetGroupedView(step items_types.PriceType, roundUp bool) (view *GroupedView, err error) {
+	key := groupedKey{step: step, roundUp: roundUp}
+	if view, ok := d.groupedViews[key]; ok {
+		return view, nil
+	}
+	asks, err := grouped_types.New(d.degree, step, roundUp)
+	if err != nil {
+		return
+	}
+	bids, err := grouped_types.New(d.degree, step, roundUp)
+	if err != nil {
+		return
+	}
+	view = &GroupedView{Asks: asks, Bids: bids}
+	d.asks.GetTree().Ascend(func(i btree.Item) bool {
+		item := i.(*items_types.DepthItem)
+		asks.Update(item.GetPrice(), 0, item.GetQuantity())
+		return true
+	})
+	d.bids.GetTree().Ascend(func(i btree.Item) bool {
+		item := i.(*items_types.DepthItem)
+		bids.Update(item.GetPrice(), 0, item.GetQuantity())
+		return true
+	})
+	d.asks.AddChangeHandler(asks.Update)
+	d.bids.AddChangeHandler(bids.Update)
+	d.groupedViews[key] = view
+	return
+}
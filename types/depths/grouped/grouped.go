@@ -0,0 +1,114 @@
+package grouped
+
+import (
+	"fmt"
+	"math"
+
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	"github.com/google/btree"
+)
+
+type (
+	// Grouped - стакан, згрупований по кошиках цін з кроком 10^exp
+	Grouped struct {
+		degree  int
+		exp     int
+		roundUp bool
+		tree    *btree.BTree
+	}
+)
+
+// New створює згрупований стакан, step повинен бути степенем десяти (0.1, 1, 10 ...)
+func New(degree int, step items_types.PriceType, roundUp bool) (grouped *Grouped, err error) {
+	exp, err := StepToExp(step)
+	if err != nil {
+		return
+	}
+	grouped = &Grouped{
+		degree:  degree,
+		exp:     exp,
+		roundUp: roundUp,
+		tree:    btree.New(degree),
+	}
+	return
+}
+
+// StepToExp переводить крок групування в степінь десяти
+func StepToExp(step items_types.PriceType) (exp int, err error) {
+	if step <= 0 {
+		err = fmt.Errorf("grouping step %f should be positive", step)
+		return
+	}
+	exp = int(math.Round(math.Log10(float64(step))))
+	if math.Abs(math.Pow10(exp)-float64(step)) > math.Pow10(exp)*1e-9 {
+		err = fmt.Errorf("grouping step %f should be a power of ten", step)
+	}
+	return
+}
+
+func (g *Grouped) GetStep() items_types.PriceType {
+	return items_types.PriceType(math.Pow10(g.exp))
+}
+
+func (g *Grouped) IsRoundUp() bool {
+	return g.roundUp
+}
+
+// Update - обробник змін рівнів стакану, підходить як depths.ChangeHandler
+func (g *Grouped) Update(price items_types.PriceType, oldQuantity, newQuantity items_types.QuantityType) {
+	bucket := g.Get(price)
+	if bucket == nil {
+		if newQuantity == 0 {
+			return
+		}
+		g.tree.ReplaceOrInsert(items_types.NewNormalizedItem(price, g.degree, g.exp, g.roundUp, newQuantity))
+		return
+	}
+	if newQuantity == 0 {
+		bucket.Delete(price, oldQuantity)
+	} else {
+		bucket.Add(price, newQuantity)
+	}
+	if bucket.IsShouldDelete() {
+		g.tree.Delete(bucket)
+	}
+}
+
+// Get повертає кошик, в який потрапляє ціна
+func (g *Grouped) Get(price items_types.PriceType) *items_types.NormalizedItem {
+	if val := g.tree.Get(items_types.NewNormalizedItem(price, g.degree, g.exp, g.roundUp)); val != nil {
+		return val.(*items_types.NormalizedItem)
+	}
+	return nil
+}
+
+func (g *Grouped) Count() int {
+	return g.tree.Len()
+}
+
+func (g *Grouped) Clear() {
+	g.tree.Clear(false)
+}
+
+func (g *Grouped) Ascend(iterator func(*items_types.NormalizedItem) bool) {
+	g.tree.Ascend(func(i btree.Item) bool {
+		return iterator(i.(*items_types.NormalizedItem))
+	})
+}
+
+func (g *Grouped) Descend(iterator func(*items_types.NormalizedItem) bool) {
+	g.tree.Descend(func(i btree.Item) bool {
+		return iterator(i.(*items_types.NormalizedItem))
+	})
+}
+
+// GetMaxByQuantity повертає кошик з найбільшою сумарною кількістю
+func (g *Grouped) GetMaxByQuantity() (max *items_types.NormalizedItem) {
+	g.Ascend(func(i *items_types.NormalizedItem) bool {
+		if max == nil || i.GetQuantity() > max.GetQuantity() {
+			max = i
+		}
+		return true
+	})
+	return
+}
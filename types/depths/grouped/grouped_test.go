@@ -0,0 +1,63 @@
+package grouped_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	grouped_types "github.com/fr0ster/go-trading-utils/types/depths/grouped"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+const (
+	degree = 3
+)
+
+func TestStepToExp(t *testing.T) {
+	exp, err := grouped_types.StepToExp(0.1)
+	assert.Nil(t, err)
+	assert.Equal(t, -1, exp)
+	exp, err = grouped_types.StepToExp(10)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, exp)
+	_, err = grouped_types.StepToExp(5)
+	assert.NotNil(t, err)
+	_, err = grouped_types.New(degree, 0, false)
+	assert.NotNil(t, err)
+}
+
+func TestGroupedUpdate(t *testing.T) {
+	grouped, err := grouped_types.New(degree, 10, false)
+	assert.Nil(t, err)
+	grouped.Update(101, 0, 1)
+	grouped.Update(105, 0, 3)
+	grouped.Update(109, 0, 2)
+	grouped.Update(111, 0, 5)
+
+	assert.Equal(t, 2, grouped.Count())
+	bucket := grouped.Get(107)
+	assert.NotNil(t, bucket)
+	assert.Equal(t, items_types.PriceType(100), bucket.GetNormalizedPrice())
+	assert.Equal(t, items_types.QuantityType(6), bucket.GetQuantity())
+	assert.Equal(t, items_types.ValueType(101+315+218), bucket.GetValue())
+	assert.Equal(t, items_types.PriceType(105), bucket.GetMaxDepth().GetPrice())
+
+	// Зміна кількості рівня
+	grouped.Update(105, 3, 1)
+	assert.Equal(t, items_types.QuantityType(4), bucket.GetQuantity())
+	assert.Equal(t, items_types.PriceType(109), bucket.GetMaxDepth().GetPrice())
+	assert.Equal(t, items_types.PriceType(110), grouped.GetMaxByQuantity().GetNormalizedPrice())
+
+	// Видалення рівнів та кошика
+	grouped.Update(111, 5, 0)
+	assert.Equal(t, 1, grouped.Count())
+	assert.Nil(t, grouped.Get(111))
+
+	prices := []items_types.PriceType{}
+	grouped.Update(95, 0, 1)
+	grouped.Descend(func(i *items_types.NormalizedItem) bool {
+		prices = append(prices, i.GetNormalizedPrice())
+		return true
+	})
+	assert.Equal(t, []items_types.PriceType{100, 90}, prices)
+}
@@ -0,0 +1,133 @@
+package heatmap
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+type (
+	record struct {
+		BucketSize items_types.PriceType `json:"bucketSize"`
+		Frames     []Frame               `json:"frames"`
+	}
+)
+
+var csvHeader = []string{"time", "lastUpdateId", "side", "price", "quantity"}
+
+// WriteJSON записує розмір кошика та всі зрізи
+func (h *History) WriteJSON(w io.Writer) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return json.NewEncoder(w).Encode(record{BucketSize: h.bucketSize, Frames: h.frames})
+}
+
+// ReadJSON додає зрізи, записані WriteJSON, розмір кошика запису повинен збігатися
+func (h *History) ReadJSON(r io.Reader) (err error) {
+	var rec record
+	if err = json.NewDecoder(r).Decode(&rec); err != nil {
+		return
+	}
+	if rec.BucketSize != h.bucketSize {
+		return fmt.Errorf("bucket size %v of record doesn't match %v", rec.BucketSize, h.bucketSize)
+	}
+	for _, frame := range rec.Frames {
+		h.AddFrame(frame)
+	}
+	return
+}
+
+// WriteCSV записує по рядку на кожен непорожній кошик: time,lastUpdateId,side,price,quantity.
+// Зрізи без жодного рівня в CSV не потрапляють.
+func (h *History) WriteCSV(w io.Writer) (err error) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	writer := csv.NewWriter(w)
+	if err = writer.Write(csvHeader); err != nil {
+		return
+	}
+	for _, frame := range h.frames {
+		for _, side := range []types.DepthSide{types.DepthSideAsk, types.DepthSideBid} {
+			for _, cell := range frame.side(side) {
+				err = writer.Write([]string{
+					frame.Time.Format(time.RFC3339Nano),
+					strconv.FormatInt(frame.LastUpdateID, 10),
+					string(side),
+					strconv.FormatFloat(float64(h.GetBucketPrice(cell.Bucket)), 'f', -1, 64),
+					strconv.FormatFloat(float64(cell.Quantity), 'f', -1, 64),
+				})
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ReadCSV додає зрізи з CSV у форматі WriteCSV, ціни розкладаються по кошиках цієї історії
+func (h *History) ReadCSV(r io.Reader) (err error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvHeader)
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return
+	}
+	var frame *Frame
+	for i, row := range rows {
+		if i == 0 && row[0] == csvHeader[0] {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339Nano, row[0])
+		if err != nil {
+			return fmt.Errorf("row %v: invalid time: %w", i+1, err)
+		}
+		lastUpdateID, err := strconv.ParseInt(row[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("row %v: invalid lastUpdateId: %w", i+1, err)
+		}
+		price, err := strconv.ParseFloat(row[3], 64)
+		if err != nil {
+			return fmt.Errorf("row %v: invalid price: %w", i+1, err)
+		}
+		quantity, err := strconv.ParseFloat(row[4], 64)
+		if err != nil {
+			return fmt.Errorf("row %v: invalid quantity: %w", i+1, err)
+		}
+		if frame == nil || !frame.Time.Equal(at) {
+			if frame != nil {
+				h.AddFrame(*frame)
+			}
+			frame = &Frame{Time: at, LastUpdateID: lastUpdateID}
+		}
+		cell := Cell{Bucket: h.bucket(items_types.PriceType(price)), Quantity: items_types.QuantityType(quantity)}
+		switch types.DepthSide(row[2]) {
+		case types.DepthSideAsk:
+			frame.Asks = addCell(frame.Asks, cell)
+		case types.DepthSideBid:
+			frame.Bids = addCell(frame.Bids, cell)
+		default:
+			return fmt.Errorf("row %v: invalid side %v", i+1, row[2])
+		}
+	}
+	if frame != nil {
+		h.AddFrame(*frame)
+	}
+	return
+}
+
+// Рядки одного зрізу йдуть за зростанням ціни, тому кошик додається в кінець або зливається з останнім
+func addCell(cells []Cell, cell Cell) []Cell {
+	if len(cells) > 0 && cells[len(cells)-1].Bucket == cell.Bucket {
+		cells[len(cells)-1].Quantity += cell.Quantity
+		return cells
+	}
+	return append(cells, cell)
+}
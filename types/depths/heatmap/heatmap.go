@@ -0,0 +1,243 @@
+package heatmap
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/btree"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+type (
+	// Cell - сумарна кількість рівнів стакану в кошику ціни Bucket * bucketSize
+	Cell struct {
+		Bucket   int64                    `json:"bucket"`
+		Quantity items_types.QuantityType `json:"quantity"`
+	}
+	// Frame - один зріз стакану, кошики кожної сторони відсортовані за зростанням
+	Frame struct {
+		Time         time.Time `json:"time"`
+		LastUpdateID int64     `json:"lastUpdateId"`
+		Asks         []Cell    `json:"asks"`
+		Bids         []Cell    `json:"bids"`
+	}
+	// Point - кількість в діапазоні цін на момент Time
+	Point struct {
+		Time     time.Time
+		Quantity items_types.QuantityType
+	}
+	// Drop - зникнення ліквідності між двома сусідніми зрізами
+	Drop struct {
+		Time   time.Time
+		Before items_types.QuantityType
+		After  items_types.QuantityType
+	}
+	// History - історія ліквідності стакану як матриця час x кошик ціни
+	History struct {
+		mutex        sync.Mutex
+		bucketSize   items_types.PriceType
+		interval     time.Duration
+		retention    time.Duration
+		frames       []Frame
+		depths       *depth_types.Depths
+		subscription *depth_types.Subscription
+	}
+)
+
+// New створює історію з кошиками по bucketSize, зрізами не частіше за interval
+// та зберіганням зрізів не старших за retention від останнього, retention 0 - без обмеження.
+func New(bucketSize items_types.PriceType, interval, retention time.Duration) (*History, error) {
+	if !(bucketSize > 0) {
+		return nil, fmt.Errorf("heatmap bucket size %v must be positive", bucketSize)
+	}
+	return &History{
+		bucketSize: bucketSize,
+		interval:   interval,
+		retention:  retention,
+	}, nil
+}
+
+// GetBucketSize повертає розмір кошика ціни
+func (h *History) GetBucketSize() items_types.PriceType {
+	return h.bucketSize
+}
+
+// Attach робить зріз після оновлень стакану, якщо з попереднього минуло не менше interval
+func (h *History) Attach(depths *depth_types.Depths) {
+	h.mutex.Lock()
+	h.depths = depths
+	h.mutex.Unlock()
+	h.subscription = depths.SubscribeCallback(h.onDepthEvent,
+		depth_types.DepthEventUpdated,
+		depth_types.DepthEventResynced)
+}
+
+// Detach відписує історію від стакану, накопичені зрізи лишаються
+func (h *History) Detach() {
+	h.mutex.Lock()
+	depths := h.depths
+	h.depths = nil
+	h.mutex.Unlock()
+	if depths != nil {
+		depths.Unsubscribe(h.subscription)
+	}
+}
+
+// Sample робить зріз стакану на момент at незалежно від interval.
+// Для офлайн-відтворення записані події застосовуються до стакану, а Sample викликається з часом запису.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (h *History) Sample(depths *depth_types.Depths, at time.Time) {
+	frame := Frame{
+		Time:         at,
+		LastUpdateID: depths.LastUpdateID,
+		Asks:         h.cells(depths.GetAsks().GetTree()),
+		Bids:         h.cells(depths.GetBids().GetTree()),
+	}
+	h.AddFrame(frame)
+}
+
+// AddFrame додає готовий зріз, наприклад прочитаний із запису.
+// Зрізи повинні додаватись в порядку часу, старіші за останній ігноруються.
+func (h *History) AddFrame(frame Frame) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if len(h.frames) > 0 && frame.Time.Before(h.frames[len(h.frames)-1].Time) {
+		return
+	}
+	h.frames = append(h.frames, frame)
+	if h.retention > 0 {
+		border := frame.Time.Add(-h.retention)
+		index := sort.Search(len(h.frames), func(i int) bool { return !h.frames[i].Time.Before(border) })
+		if index > 0 {
+			// Без копіювання на кожен зріз: старі зрізи звільняються для GC,
+			// а масив перевиділяється з живою частиною, коли append вичерпає ємність
+			clear(h.frames[:index])
+			h.frames = h.frames[index:]
+		}
+	}
+}
+
+// GetFrames повертає зрізи з проміжку [from, to]
+func (h *History) GetFrames(from, to time.Time) []Frame {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	first, last := h.bounds(from, to)
+	return append([]Frame(nil), h.frames[first:last]...)
+}
+
+// GetLastFrames повертає зрізи за window до останнього зрізу, window 0 - всі зрізи
+func (h *History) GetLastFrames(window time.Duration) []Frame {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if len(h.frames) == 0 {
+		return nil
+	}
+	first := 0
+	if window > 0 {
+		border := h.frames[len(h.frames)-1].Time.Add(-window)
+		first = sort.Search(len(h.frames), func(i int) bool { return !h.frames[i].Time.Before(border) })
+	}
+	return append([]Frame(nil), h.frames[first:]...)
+}
+
+// Len повертає кількість збережених зрізів
+func (h *History) Len() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return len(h.frames)
+}
+
+// GetQuantity повертає кількість в кошику ціни price по кожному зрізу з проміжку [from, to]
+func (h *History) GetQuantity(side types.DepthSide, price items_types.PriceType, from, to time.Time) []Point {
+	return h.GetRangeQuantity(side, price, price, from, to)
+}
+
+// GetRangeQuantity повертає сумарну кількість в кошиках між low та high по кожному зрізу з проміжку [from, to]
+func (h *History) GetRangeQuantity(side types.DepthSide, low, high items_types.PriceType, from, to time.Time) (points []Point) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	lowBucket, highBucket := h.bucket(low), h.bucket(high)
+	first, last := h.bounds(from, to)
+	points = make([]Point, 0, last-first)
+	for _, frame := range h.frames[first:last] {
+		points = append(points, Point{Time: frame.Time, Quantity: sumCells(frame.side(side), lowBucket, highBucket)})
+	}
+	return
+}
+
+// GetDrops повертає моменти, коли кількість в межах width від price зменшилась щонайменше на ratio (0..1)
+// відносно попереднього зрізу, наприклад коли зникла ліквідність біля 60000
+func (h *History) GetDrops(side types.DepthSide, price, width items_types.PriceType, ratio float64, from, to time.Time) (drops []Drop) {
+	points := h.GetRangeQuantity(side, price-width, price+width, from, to)
+	for i := 1; i < len(points); i++ {
+		before, after := points[i-1].Quantity, points[i].Quantity
+		if before > 0 && float64(before-after) >= ratio*float64(before) {
+			drops = append(drops, Drop{Time: points[i].Time, Before: before, After: after})
+		}
+	}
+	return
+}
+
+// GetBucketPrice повертає нижню межу кошика
+func (h *History) GetBucketPrice(bucket int64) items_types.PriceType {
+	return items_types.PriceType(bucket) * h.bucketSize
+}
+
+func (h *History) onDepthEvent(event *depth_types.DepthEvent) {
+	h.mutex.Lock()
+	depths := h.depths
+	due := len(h.frames) == 0 || event.Time.Sub(h.frames[len(h.frames)-1].Time) >= h.interval
+	h.mutex.Unlock()
+	if depths != nil && due {
+		h.Sample(depths, event.Time)
+	}
+}
+
+func (h *History) bucket(price items_types.PriceType) int64 {
+	// Зсув компенсує похибку float на межі кошика
+	return int64(math.Floor(float64(price/h.bucketSize) + 1e-9))
+}
+
+func (h *History) cells(tree *btree.BTree) (cells []Cell) {
+	tree.Ascend(func(i btree.Item) bool {
+		item := i.(*items_types.DepthItem)
+		bucket := h.bucket(item.GetPrice())
+		if len(cells) > 0 && cells[len(cells)-1].Bucket == bucket {
+			cells[len(cells)-1].Quantity += item.GetQuantity()
+		} else {
+			cells = append(cells, Cell{Bucket: bucket, Quantity: item.GetQuantity()})
+		}
+		return true
+	})
+	return
+}
+
+func (h *History) bounds(from, to time.Time) (first, last int) {
+	first = sort.Search(len(h.frames), func(i int) bool { return !h.frames[i].Time.Before(from) })
+	last = sort.Search(len(h.frames), func(i int) bool { return h.frames[i].Time.After(to) })
+	if last < first {
+		last = first
+	}
+	return
+}
+
+func (f *Frame) side(side types.DepthSide) []Cell {
+	if side == types.DepthSideAsk {
+		return f.Asks
+	}
+	return f.Bids
+}
+
+func sumCells(cells []Cell, low, high int64) (summa items_types.QuantityType) {
+	index := sort.Search(len(cells), func(i int) bool { return cells[i].Bucket >= low })
+	for ; index < len(cells) && cells[index].Bucket <= high; index++ {
+		summa += cells[index].Quantity
+	}
+	return
+}
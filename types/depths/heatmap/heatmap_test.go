@@ -0,0 +1,97 @@
+package heatmap_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	heatmap_types "github.com/fr0ster/go-trading-utils/types/depths/heatmap"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func TestHeatmapHistory(t *testing.T) {
+	d := depth_types.New(3, "BTCUSDT", nil, nil)
+	d.ApplySnapshot(1,
+		[]*items_types.Bid{items_types.NewBid(59990, 1), items_types.NewBid(59995, 2), items_types.NewBid(59800, 5)},
+		[]*items_types.Ask{items_types.NewAsk(60010, 3), items_types.NewAsk(60090, 4)})
+	history, err := heatmap_types.New(100, time.Second, 3*time.Minute)
+	assert.Nil(t, err)
+
+	// Офлайн-відтворення: події застосовуються до стакану, зрізи робляться з часом запису
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history.Sample(d, start)
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 2, LastUpdateID: 2,
+		Bids: []items_types.PriceLevel{{Price: 59995, Quantity: 0}}})
+	history.Sample(d, start.Add(time.Minute))
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 3, LastUpdateID: 3,
+		Bids: []items_types.PriceLevel{{Price: 59990, Quantity: 0}}})
+	history.Sample(d, start.Add(2*time.Minute))
+
+	points := history.GetQuantity(types.DepthSideBid, 59950, start, start.Add(time.Hour))
+	assert.Equal(t, 3, len(points))
+	assert.Equal(t, items_types.QuantityType(3), points[0].Quantity)
+	assert.Equal(t, items_types.QuantityType(1), points[1].Quantity)
+	assert.Equal(t, items_types.QuantityType(0), points[2].Quantity)
+	assert.Equal(t, items_types.QuantityType(7), history.GetRangeQuantity(types.DepthSideAsk, 60000, 60099, start, start)[0].Quantity)
+
+	// Коли зникла ліквідність біля 60000
+	drops := history.GetDrops(types.DepthSideBid, 59950, 50, 0.5, start, start.Add(time.Hour))
+	assert.Equal(t, 2, len(drops))
+	assert.Equal(t, start.Add(time.Minute), drops[0].Time)
+
+	// Старі зрізи видаляються
+	history.Sample(d, start.Add(4*time.Minute))
+	assert.Equal(t, 3, history.Len())
+
+	for _, format := range []string{"csv", "json"} {
+		buffer := &bytes.Buffer{}
+		restored, _ := heatmap_types.New(100, time.Second, 0)
+		if format == "csv" {
+			assert.Nil(t, history.WriteCSV(buffer))
+			assert.Nil(t, restored.ReadCSV(buffer))
+		} else {
+			assert.Nil(t, history.WriteJSON(buffer))
+			assert.Nil(t, restored.ReadJSON(buffer))
+		}
+		assert.Equal(t, history.GetFrames(start, start.Add(time.Hour)), restored.GetFrames(start, start.Add(time.Hour)), format)
+	}
+	small, _ := heatmap_types.New(10, time.Second, 0)
+	assert.NotNil(t, small.ReadJSON(bytes.NewBufferString(`{"bucketSize":100,"frames":[]}`)))
+}
+
+func TestHeatmapAttach(t *testing.T) {
+	d := depth_types.New(3, "BTCUSDT", nil, nil)
+	history, _ := heatmap_types.New(100, time.Hour, 0)
+	history.Attach(d)
+	d.ApplySnapshot(1,
+		[]*items_types.Bid{items_types.NewBid(59990, 1)},
+		[]*items_types.Ask{items_types.NewAsk(60010, 3)})
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 2, LastUpdateID: 2,
+		Bids: []items_types.PriceLevel{{Price: 59990, Quantity: 2}}})
+	// Друге оновлення в межах інтервалу не створює зріз
+	assert.Equal(t, 1, history.Len())
+	history.Detach()
+}
+
+func TestHeatmapValidationAndRetention(t *testing.T) {
+	for _, size := range []items_types.PriceType{0, -1} {
+		history, err := heatmap_types.New(size, time.Second, 0)
+		assert.Nil(t, history)
+		assert.ErrorContains(t, err, "must be positive")
+	}
+
+	history, err := heatmap_types.New(100, time.Second, 10*time.Second)
+	assert.Nil(t, err)
+	start := time.Now()
+	for i := 0; i < 1000; i++ {
+		history.AddFrame(heatmap_types.Frame{Time: start.Add(time.Duration(i) * time.Second), LastUpdateID: int64(i)})
+	}
+	frames := history.GetLastFrames(0)
+	assert.Equal(t, 11, len(frames))
+	assert.Equal(t, int64(989), frames[0].LastUpdateID)
+	assert.Equal(t, int64(999), frames[len(frames)-1].LastUpdateID)
+}
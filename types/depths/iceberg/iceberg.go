@@ -0,0 +1,327 @@
+package iceberg
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	aggtrade_types "github.com/fr0ster/go-trading-utils/types/trades/aggtrade"
+	"github.com/fr0ster/go-trading-utils/utils"
+)
+
+const (
+	IcebergDetected IcebergEventType = "ICEBERG_DETECTED"
+	IcebergRefilled IcebergEventType = "ICEBERG_REFILLED"
+	// Рівень знято без угод, прихована заявка скасована або вичерпана
+	IcebergRemoved IcebergEventType = "ICEBERG_REMOVED"
+	// Айсберг не змінювався і не виконувався довше за icebergTTL та забутий детектором
+	IcebergExpired IcebergEventType = "ICEBERG_EXPIRED"
+
+	// Скільки оновлень стакану чекати на зміну рівня після угод, бо стріми угод та стакану не синхронізовані.
+	// Якщо рівень за цей час не змінився, угоди повністю поповнено прихованим обсягом.
+	settleUpdates = 2
+
+	// Рівень з угодами, який не визнано айсбергом, забувається, якщо не оновлювався цей час
+	DefaultLevelTTL = time.Minute
+	// Виявлений айсберг забувається, якщо не оновлювався цей час
+	DefaultIcebergTTL = 10 * time.Minute
+)
+
+type (
+	IcebergEventType string
+	// Iceberg - рівень, який поповнюється після угод, та оцінка його прихованого обсягу
+	Iceberg struct {
+		Side  types.DepthSide
+		Price items_types.PriceType
+		// Поточна та максимальна видима кількість
+		Displayed    items_types.QuantityType
+		MaxDisplayed items_types.QuantityType
+		// Обсяг угод по рівню за час спостереження
+		Traded items_types.QuantityType
+		// Оцінка прихованого обсягу - сума всіх поповнень після угод
+		Hidden    items_types.QuantityType
+		Refills   int
+		FirstSeen time.Time
+		UpdatedAt time.Time
+
+		detected bool
+		// Угоди, ще не враховані оновленням рівня, та скільки оновлень стакану вони чекають
+		pending        items_types.QuantityType
+		pendingUpdates int
+		// LastUpdateID оновлення, яке змінило рівень востаннє
+		touched int64
+		// Рівень зник після угод, повернення рівня вважається поповненням
+		tradedThrough bool
+	}
+	IcebergEvent struct {
+		Type         IcebergEventType
+		Iceberg      Iceberg
+		LastUpdateID int64
+		Time         time.Time
+	}
+	IcebergEventHandler func(event *IcebergEvent)
+	// Detector зіставляє угоди AggTrades з оновленнями рівнів depth.Depths.
+	// Угоди між двома оновленнями рівня відносяться до наступної зміни рівня,
+	// тому угоди, які прийшли пізніше за diff-подію, завищують оцінку поповнення.
+	Detector struct {
+		mutex        sync.Mutex
+		depths       *depth_types.Depths
+		subscription *depth_types.Subscription
+		minRefills   int
+		tradedRatio  float64
+		levels       map[types.DepthSide]map[int64]*Iceberg
+		levelTTL     time.Duration
+		icebergTTL   time.Duration
+		handlers     []IcebergEventHandler
+		closed       bool
+	}
+)
+
+// Ratio повертає відношення обсягу угод до максимальної видимої кількості
+func (i *Iceberg) Ratio() float64 {
+	if i.MaxDisplayed == 0 {
+		return 0
+	}
+	return float64(i.Traded / i.MaxDisplayed)
+}
+
+// New створює детектор та підписується на події стакану та нові угоди trades.
+// Рівень вважається айсбергом після minRefills поповнень, якщо обсяг угод по ньому
+// не менший за tradedRatio * максимальну видиму кількість.
+// Блокує trades, тому не можна викликати під блокуванням AggTrades.
+func New(depths *depth_types.Depths, trades *aggtrade_types.AggTrades, minRefills int, tradedRatio float64) *Detector {
+	this := &Detector{
+		depths:      depths,
+		minRefills:  minRefills,
+		tradedRatio: tradedRatio,
+		levels:      newSides(),
+		levelTTL:    DefaultLevelTTL,
+		icebergTTL:  DefaultIcebergTTL,
+	}
+	this.subscription = depths.SubscribeCallback(this.onDepthEvent,
+		depth_types.DepthEventLevelAdded,
+		depth_types.DepthEventLevelChanged,
+		depth_types.DepthEventLevelRemoved,
+		depth_types.DepthEventResynced,
+		depth_types.DepthEventUpdated)
+	if trades != nil {
+		trades.Lock()
+		trades.AddTradeHandler(this.onTrade)
+		trades.Unlock()
+	}
+	return this
+}
+
+// Close відписує детектор від подій стакану, угоди після цього ігноруються
+func (d *Detector) Close() {
+	d.mutex.Lock()
+	d.closed = true
+	d.mutex.Unlock()
+	d.depths.Unsubscribe(d.subscription)
+}
+
+// AddHandler додає обробник подій айсбергів.
+// Обробники викликаються під блокуванням Depths, тому повинні бути швидкими.
+func (d *Detector) AddHandler(handler IcebergEventHandler) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.handlers = append(d.handlers, handler)
+}
+
+// SetTTL змінює, скільки рівні без оновлень зберігаються в детекторі, 0 - не забувати.
+// Застарілі рівні видаляються після кожного оновлення стакану, для виявлених айсбергів надсилається IcebergExpired.
+func (d *Detector) SetTTL(levelTTL, icebergTTL time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.levelTTL = levelTTL
+	d.icebergTTL = icebergTTL
+}
+
+// AddTrade враховує угоду по пасивній стороні side, для угод не з AggTrades
+func (d *Detector) AddTrade(side types.DepthSide, price items_types.PriceType, quantity items_types.QuantityType) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.closed {
+		return
+	}
+	key := items_types.PriceKey(price)
+	level, ok := d.levels[side][key]
+	if !ok {
+		now := time.Now()
+		level = &Iceberg{Side: side, Price: price, FirstSeen: now, UpdatedAt: now}
+		d.levels[side][key] = level
+	}
+	level.Traded += quantity
+	level.pending += quantity
+}
+
+// GetIcebergs повертає копії виявлених айсбергів сторони, від найбільшого прихованого обсягу
+func (d *Detector) GetIcebergs(side types.DepthSide) (icebergs []Iceberg) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, level := range d.levels[side] {
+		if level.detected {
+			icebergs = append(icebergs, *level)
+		}
+	}
+	sort.Slice(icebergs, func(i, j int) bool { return icebergs[i].Hidden > icebergs[j].Hidden })
+	return
+}
+
+// Prune забуває рівні, які не оновлювались з before і ще не визнані айсбергами, не чекаючи levelTTL
+func (d *Detector) Prune(before time.Time) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	for _, levels := range d.levels {
+		for key, level := range levels {
+			if !level.detected && level.UpdatedAt.Before(before) {
+				delete(levels, key)
+			}
+		}
+	}
+}
+
+// Покупець-мейкер означає, що угода виконала bid, інакше - ask
+func (d *Detector) onTrade(trade *aggtrade_types.AggTrade) {
+	side := types.DepthSideAsk
+	if trade.IsBuyerMaker {
+		side = types.DepthSideBid
+	}
+	d.AddTrade(side,
+		items_types.PriceType(utils.ConvStrToFloat64(trade.Price)),
+		items_types.QuantityType(utils.ConvStrToFloat64(trade.Quantity)))
+}
+
+func (d *Detector) onDepthEvent(event *depth_types.DepthEvent) {
+	d.mutex.Lock()
+	var icebergEvents []*IcebergEvent
+	switch event.Type {
+	case depth_types.DepthEventResynced:
+		// Після знімку невідомо, які зміни рівнів відповідають накопиченим угодам
+		d.levels = newSides()
+	case depth_types.DepthEventUpdated:
+		icebergEvents = d.settle(event.LastUpdateID)
+	default:
+		if level, ok := d.levels[event.Side][items_types.PriceKey(event.Price)]; ok {
+			level.touched = event.LastUpdateID
+			if icebergEvent := d.evaluate(level, event.OldQuantity, event.Quantity, event.LastUpdateID); icebergEvent != nil {
+				icebergEvents = append(icebergEvents, icebergEvent)
+			}
+		}
+	}
+	handlers := d.handlers
+	d.mutex.Unlock()
+	for _, icebergEvent := range icebergEvents {
+		for _, handler := range handlers {
+			handler(icebergEvent)
+		}
+	}
+}
+
+// Рівні з угодами, які не змінювались settleUpdates оновлень, оцінюються з незмінною кількістю,
+// рівні без нових угод, які не оновлювались довше за TTL, забуваються.
+// Викликається з обробника подій, тобто під блокуванням Depths.
+func (d *Detector) settle(lastUpdateID int64) (icebergEvents []*IcebergEvent) {
+	now := time.Now()
+	for side, levels := range d.levels {
+		for key, level := range levels {
+			if level.pending == 0 {
+				if icebergEvent := d.expire(levels, key, now, lastUpdateID); icebergEvent != nil {
+					icebergEvents = append(icebergEvents, icebergEvent)
+				}
+				continue
+			}
+			if level.touched == lastUpdateID {
+				continue
+			}
+			level.pendingUpdates++
+			if level.pendingUpdates < settleUpdates {
+				continue
+			}
+			quantity := d.quantity(side, level.Price)
+			if icebergEvent := d.evaluate(level, quantity, quantity, lastUpdateID); icebergEvent != nil {
+				icebergEvents = append(icebergEvents, icebergEvent)
+			}
+		}
+	}
+	return
+}
+
+func (d *Detector) expire(levels map[int64]*Iceberg, key int64, now time.Time, lastUpdateID int64) (icebergEvent *IcebergEvent) {
+	level := levels[key]
+	ttl := d.levelTTL
+	if level.detected {
+		ttl = d.icebergTTL
+	}
+	if ttl <= 0 || now.Sub(level.UpdatedAt) < ttl {
+		return
+	}
+	delete(levels, key)
+	if level.detected {
+		icebergEvent = newEvent(IcebergExpired, level, lastUpdateID, now)
+	}
+	return
+}
+
+func (d *Detector) quantity(side types.DepthSide, price items_types.PriceType) items_types.QuantityType {
+	if side == types.DepthSideAsk {
+		return d.depths.GetAsks().Get(items_types.NewAsk(price)).GetDepthItem().GetQuantity()
+	}
+	return d.depths.GetBids().Get(items_types.NewBid(price)).GetDepthItem().GetQuantity()
+}
+
+func (d *Detector) evaluate(level *Iceberg, oldQuantity, quantity items_types.QuantityType, lastUpdateID int64) (icebergEvent *IcebergEvent) {
+	now := time.Now()
+	consumed := level.pending
+	level.pending = 0
+	level.pendingUpdates = 0
+	level.Displayed = quantity
+	if quantity > level.MaxDisplayed {
+		level.MaxDisplayed = quantity
+	}
+	level.UpdatedAt = now
+	// Без прихованого обсягу угоди зменшують рівень рівно на свій обсяг
+	expected := oldQuantity - consumed
+	if expected < 0 {
+		expected = 0
+	}
+	refilled := false
+	if refill := quantity - expected; refill > 0 && (consumed > 0 || level.tradedThrough) {
+		level.Hidden += refill
+		level.Refills++
+		level.tradedThrough = false
+		refilled = true
+	}
+	if quantity == 0 {
+		if consumed > 0 {
+			level.tradedThrough = true
+			return
+		}
+		delete(d.levels[level.Side], items_types.PriceKey(level.Price))
+		if level.detected {
+			icebergEvent = newEvent(IcebergRemoved, level, lastUpdateID, now)
+		}
+		return
+	}
+	switch {
+	case !level.detected && level.Refills >= d.minRefills && level.Ratio() >= d.tradedRatio:
+		level.detected = true
+		icebergEvent = newEvent(IcebergDetected, level, lastUpdateID, now)
+	case level.detected && refilled:
+		icebergEvent = newEvent(IcebergRefilled, level, lastUpdateID, now)
+	}
+	return
+}
+
+func newEvent(eventType IcebergEventType, level *Iceberg, lastUpdateID int64, now time.Time) *IcebergEvent {
+	return &IcebergEvent{Type: eventType, Iceberg: *level, LastUpdateID: lastUpdateID, Time: now}
+}
+func newSides() map[types.DepthSide]map[int64]*Iceberg {
+	return map[types.DepthSide]map[int64]*Iceberg{
+		types.DepthSideAsk: make(map[int64]*Iceberg),
+		types.DepthSideBid: make(map[int64]*Iceberg),
+	}
+}
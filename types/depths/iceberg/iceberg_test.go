@@ -0,0 +1,122 @@
+package iceberg_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	iceberg_types "github.com/fr0ster/go-trading-utils/types/depths/iceberg"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	aggtrade_types "github.com/fr0ster/go-trading-utils/types/trades/aggtrade"
+)
+
+func TestIcebergDetector(t *testing.T) {
+	d := depth_types.New(3, "BTCUSDT", nil, nil)
+	d.ApplySnapshot(1,
+		[]*items_types.Bid{items_types.NewBid(100, 10), items_types.NewBid(99, 10)},
+		[]*items_types.Ask{items_types.NewAsk(101, 10), items_types.NewAsk(102, 10)})
+	trades := aggtrade_types.New(make(chan struct{}), "BTCUSDT", nil, nil)
+
+	detector := iceberg_types.New(d, trades, 3, 2)
+	events := make([]*iceberg_types.IcebergEvent, 0)
+	detector.AddHandler(func(event *iceberg_types.IcebergEvent) {
+		events = append(events, event)
+	})
+	trade := func(id int64, price, quantity string, isBuyerMaker bool) {
+		trades.Lock()
+		defer trades.Unlock()
+		trades.Update(&aggtrade_types.AggTrade{AggTradeID: id, Price: price, Quantity: quantity, IsBuyerMaker: isBuyerMaker})
+	}
+
+	// Bid 100 тричі виконується на 8, але кількість в стакані не змінюється
+	for i := int64(0); i < 3; i++ {
+		trade(i, "100", "8", true)
+		d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 2*i + 2, LastUpdateID: 2*i + 2,
+			Bids: []items_types.PriceLevel{{Price: 100, Quantity: 10}}})
+		d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 2*i + 3, LastUpdateID: 2*i + 3,
+			Asks: []items_types.PriceLevel{{Price: 102, Quantity: items_types.QuantityType(11 + i)}}})
+	}
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, iceberg_types.IcebergDetected, events[0].Type)
+	icebergs := detector.GetIcebergs(types.DepthSideBid)
+	assert.Equal(t, 1, len(icebergs))
+	assert.Equal(t, 3, icebergs[0].Refills)
+	assert.Equal(t, items_types.QuantityType(24), icebergs[0].Traded)
+	assert.Equal(t, items_types.QuantityType(24), icebergs[0].Hidden)
+
+	// Звичайне виконання ask без поповнення
+	trade(10, "101", "4", false)
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 8, LastUpdateID: 8,
+		Asks: []items_types.PriceLevel{{Price: 101, Quantity: 6}}})
+	assert.Empty(t, detector.GetIcebergs(types.DepthSideAsk))
+
+	// Рівень виконано повністю і виставлено знову з тією ж кількістю
+	trade(11, "100", "10", true)
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 9, LastUpdateID: 9,
+		Bids: []items_types.PriceLevel{{Price: 100, Quantity: 0}}})
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 10, LastUpdateID: 10,
+		Bids: []items_types.PriceLevel{{Price: 100, Quantity: 10}}})
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, iceberg_types.IcebergRefilled, events[1].Type)
+	assert.Equal(t, 4, events[1].Iceberg.Refills)
+
+	// Рівень знято без угод
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 11, LastUpdateID: 11,
+		Bids: []items_types.PriceLevel{{Price: 100, Quantity: 0}}})
+	assert.Equal(t, 3, len(events))
+	assert.Equal(t, iceberg_types.IcebergRemoved, events[2].Type)
+	assert.Empty(t, detector.GetIcebergs(types.DepthSideBid))
+}
+
+func TestIcebergDetectorExpiresLevels(t *testing.T) {
+	d := depth_types.New(3, "BTCUSDT", nil, nil)
+	d.ApplySnapshot(1,
+		[]*items_types.Bid{items_types.NewBid(100, 10), items_types.NewBid(99, 10)},
+		[]*items_types.Ask{items_types.NewAsk(101, 10)})
+
+	detector := iceberg_types.New(d, nil, 3, 2)
+	detector.SetTTL(20*time.Millisecond, 200*time.Millisecond)
+	events := make([]*iceberg_types.IcebergEvent, 0)
+	detector.AddHandler(func(event *iceberg_types.IcebergEvent) {
+		events = append(events, event)
+	})
+	updateID := int64(1)
+	update := func(bids ...items_types.PriceLevel) {
+		updateID++
+		d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: updateID, LastUpdateID: updateID,
+			Bids: bids, Asks: []items_types.PriceLevel{{Price: 101, Quantity: items_types.QuantityType(updateID)}}})
+	}
+	refill := func(price items_types.PriceType) {
+		detector.AddTrade(types.DepthSideBid, price, 8)
+		update(items_types.PriceLevel{Price: price, Quantity: 10})
+		update()
+	}
+
+	for i := 0; i < 3; i++ {
+		refill(100)
+	}
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, iceberg_types.IcebergDetected, events[0].Type)
+
+	// Рівень 99 не став айсбергом і забувається разом з лічильником поповнень
+	refill(99)
+	refill(99)
+	time.Sleep(30 * time.Millisecond)
+	update()
+	refill(99)
+	icebergs := detector.GetIcebergs(types.DepthSideBid)
+	assert.Equal(t, 1, len(icebergs))
+	assert.Equal(t, items_types.PriceType(100), icebergs[0].Price)
+
+	// Виявлений айсберг без оновлень забувається з подією
+	time.Sleep(210 * time.Millisecond)
+	update()
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, iceberg_types.IcebergExpired, events[1].Type)
+	assert.Equal(t, items_types.PriceType(100), events[1].Iceberg.Price)
+	assert.Empty(t, detector.GetIcebergs(types.DepthSideBid))
+	detector.Close()
+}
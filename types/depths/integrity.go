@@ -0,0 +1,275 @@
+package depth
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/btree"
+	"github.com/sirupsen/logrus"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+const (
+	IntegrityCrossed  IntegrityIssueType = "CROSSED"
+	IntegrityQuantity IntegrityIssueType = "INVALID_QUANTITY"
+	IntegrityTotals   IntegrityIssueType = "TOTALS_DRIFT"
+	IntegrityStale    IntegrityIssueType = "STALE"
+
+	// Допустима відносна розбіжність сум через накопичення похибки float при інкрементальному оновленні,
+	// суми перераховуються з нуля при кожному знімку
+	totalsTolerance = 1e-6
+)
+
+type (
+	IntegrityIssueType string
+	// IntegrityIssue - порушення цілісності стакану та рівні, які його спричинили
+	IntegrityIssue struct {
+		Type    IntegrityIssueType
+		Side    types.DepthSide
+		Message string
+		Levels  []items_types.PriceLevel
+	}
+	// IntegrityCheck перевіряє стакан і повертає знайдені порушення.
+	// Виклик виконується під блокуванням Depths.
+	IntegrityCheck func(d *Depths) []IntegrityIssue
+	integrity      struct {
+		checks  []IntegrityCheck
+		every   int
+		heal    bool
+		updates int
+		stop    chan struct{}
+	}
+)
+
+// DefaultIntegrityChecks повертає перевірки, які не потребують параметрів
+func DefaultIntegrityChecks() []IntegrityCheck {
+	return []IntegrityCheck{CheckCrossed, CheckQuantities, CheckTotals}
+}
+
+// CheckCrossed знаходить перехрещений стакан, коли кращий bid не нижчий за кращий ask
+func CheckCrossed(d *Depths) (issues []IntegrityIssue) {
+	bestAsk, err := d.asks.GetBest()
+	if err != nil {
+		return
+	}
+	bestBid, err := d.bids.GetBest()
+	if err != nil || bestBid.GetPrice() < bestAsk.GetPrice() {
+		return
+	}
+	issue := IntegrityIssue{
+		Type:    IntegrityCrossed,
+		Message: fmt.Sprintf("best bid %v >= best ask %v", bestBid.GetPrice(), bestAsk.GetPrice()),
+	}
+	// Рівні bid не нижче кращого ask та рівні ask не вище кращого bid
+	d.bids.GetTree().AscendGreaterOrEqual(items_types.New(bestAsk.GetPrice()), collectLevels(&issue.Levels))
+	d.asks.GetTree().DescendLessOrEqual(items_types.New(bestBid.GetPrice()), collectLevels(&issue.Levels))
+	return append(issues, issue)
+}
+
+// CheckQuantities знаходить рівні з нульовою або від'ємною кількістю
+func CheckQuantities(d *Depths) (issues []IntegrityIssue) {
+	for _, side := range []types.DepthSide{types.DepthSideAsk, types.DepthSideBid} {
+		issue := IntegrityIssue{Type: IntegrityQuantity, Side: side}
+		d.sideTree(side).Ascend(func(i btree.Item) bool {
+			if i.(*items_types.DepthItem).GetQuantity() <= 0 {
+				collectLevels(&issue.Levels)(i)
+			}
+			return true
+		})
+		if len(issue.Levels) > 0 {
+			issue.Message = fmt.Sprintf("%v levels with non-positive quantity", len(issue.Levels))
+			issues = append(issues, issue)
+		}
+	}
+	return
+}
+
+// CheckTotals порівнює накопичені суми та кількість рівнів кожної сторони з перерахованими по дереву
+func CheckTotals(d *Depths) (issues []IntegrityIssue) {
+	check := func(side types.DepthSide, count int, quantity items_types.QuantityType, value items_types.ValueType) {
+		var (
+			walkCount    int
+			walkQuantity items_types.QuantityType
+			walkValue    items_types.ValueType
+		)
+		d.sideTree(side).Ascend(func(i btree.Item) bool {
+			walkCount++
+			walkQuantity += i.(*items_types.DepthItem).GetQuantity()
+			walkValue += i.(*items_types.DepthItem).GetValue()
+			return true
+		})
+		if walkCount != count || drifted(float64(walkQuantity), float64(quantity)) || drifted(float64(walkValue), float64(value)) {
+			issues = append(issues, IntegrityIssue{
+				Type: IntegrityTotals,
+				Side: side,
+				Message: fmt.Sprintf("count %v, quantity %v, value %v, recomputed count %v, quantity %v, value %v",
+					count, quantity, value, walkCount, walkQuantity, walkValue),
+			})
+		}
+	}
+	check(types.DepthSideAsk, d.asks.Count(), d.asks.GetSummaQuantity(), d.asks.GetSummaValue())
+	check(types.DepthSideBid, d.bids.Count(), d.bids.GetSummaQuantity(), d.bids.GetSummaValue())
+	return
+}
+
+// CheckStale повертає перевірку, що стакан оновлювався не пізніше ніж maxAge тому.
+// Для стакану без жодного оновлення вік рахується від першої спроби синхронізації.
+func CheckStale(maxAge time.Duration) IntegrityCheck {
+	return func(d *Depths) (issues []IntegrityIssue) {
+		since := d.updatedAt
+		if since.IsZero() {
+			since = d.syncStartedAt
+		}
+		if since.IsZero() {
+			return
+		}
+		if age := time.Since(since); age > maxAge {
+			issues = append(issues, IntegrityIssue{
+				Type:    IntegrityStale,
+				Message: fmt.Sprintf("no updates for %v", age),
+			})
+		}
+		return
+	}
+}
+
+// Validate виконує перевірки checks, без checks - DefaultIntegrityChecks, і нічого не змінює.
+// Підходить для стаканів без стріму, наприклад paper trading чи бектесту.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) Validate(checks ...IntegrityCheck) (issues []IntegrityIssue) {
+	if len(checks) == 0 {
+		checks = DefaultIntegrityChecks()
+	}
+	for _, check := range checks {
+		issues = append(issues, check(d)...)
+	}
+	return
+}
+
+// EnableIntegrity вмикає перевірку цілісності після кожного every-го застосованого оновлення, every 0 - тільки
+// за таймером або викликом CheckIntegrity. Без checks використовуються DefaultIntegrityChecks.
+// При порушенні підписникам надсилається подія INTEGRITY_FAILED, а з heal стакан ресинхронізується через Init.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) EnableIntegrity(every int, heal bool, checks ...IntegrityCheck) {
+	d.StopIntegrityTimer()
+	if len(checks) == 0 {
+		checks = DefaultIntegrityChecks()
+	}
+	d.integrity = &integrity{checks: checks, every: every, heal: heal}
+}
+
+// DisableIntegrity вимикає перевірку цілісності та її таймер.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) DisableIntegrity() {
+	d.StopIntegrityTimer()
+	d.integrity = nil
+}
+
+// StartIntegrityTimer запускає перевірку цілісності кожні interval, потрібен попередній EnableIntegrity.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) StartIntegrityTimer(interval time.Duration) {
+	if d.integrity == nil {
+		return
+	}
+	d.StopIntegrityTimer()
+	stop := make(chan struct{})
+	d.integrity.stop = stop
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				d.Lock()
+				select {
+				case <-stop:
+				default:
+					d.CheckIntegrity()
+				}
+				d.Unlock()
+			}
+		}
+	}()
+}
+
+// StopIntegrityTimer зупиняє таймер перевірки цілісності.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) StopIntegrityTimer() {
+	if d.integrity != nil && d.integrity.stop != nil {
+		close(d.integrity.stop)
+		d.integrity.stop = nil
+	}
+}
+
+// GetUpdatedAt повертає час останнього застосованого оновлення або знімку
+func (d *Depths) GetUpdatedAt() time.Time {
+	return d.updatedAt
+}
+
+// CheckIntegrity виконує перевірки EnableIntegrity, надсилає подію INTEGRITY_FAILED
+// та за потреби запускає ресинхронізацію.
+// Для несинхронізованого стакану повідомляється тільки застарілість, інші перевірки дали б хибні порушення.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) CheckIntegrity() (issues []IntegrityIssue) {
+	if d.integrity == nil {
+		return
+	}
+	state := d.GetSyncState()
+	for _, issue := range d.Validate(d.integrity.checks...) {
+		if state == SyncStateSynced || issue.Type == IntegrityStale {
+			issues = append(issues, issue)
+		}
+	}
+	if len(issues) == 0 {
+		return
+	}
+	logrus.Warnf("Depths %v integrity failed in state %v: %v", d.symbol, state, issues[0].Message)
+	d.send([]*DepthEvent{{Type: DepthEventIntegrityFailed, Issues: issues}}, time.Now())
+	// Без Init ресинхронізація неможлива, а знімок, що вже завантажується, не перезапускається
+	if d.integrity.heal && d.Init != nil && state != SyncStateSyncing {
+		d.syncBuffer = nil
+		if err := d.Resync(); err != nil {
+			logrus.Errorf("Depths %v resync error: %v", d.symbol, err)
+		}
+	}
+	return
+}
+
+// Викликається після застосування оновлення або знімку під блокуванням Depths
+func (d *Depths) afterUpdate() {
+	d.updatedAt = time.Now()
+	if d.integrity == nil || d.integrity.every <= 0 {
+		return
+	}
+	d.integrity.updates++
+	if d.integrity.updates >= d.integrity.every {
+		d.integrity.updates = 0
+		d.CheckIntegrity()
+	}
+}
+
+func (d *Depths) sideTree(side types.DepthSide) *btree.BTree {
+	if side == types.DepthSideAsk {
+		return d.asks.GetTree()
+	}
+	return d.bids.GetTree()
+}
+
+func collectLevels(levels *[]items_types.PriceLevel) btree.ItemIterator {
+	return func(i btree.Item) bool {
+		*levels = append(*levels, items_types.PriceLevel{
+			Price:    i.(*items_types.DepthItem).GetPrice(),
+			Quantity: i.(*items_types.DepthItem).GetQuantity(),
+		})
+		return true
+	}
+}
+
+func drifted(recomputed, total float64) bool {
+	return math.Abs(recomputed-total) > totalsTolerance*math.Max(1, math.Abs(recomputed))
+}
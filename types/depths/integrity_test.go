@@ -0,0 +1,143 @@
+package depth_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func TestIntegrityAutoHeal(t *testing.T) {
+	done := make(chan struct{}, 1)
+	d := depth_types.New(degree, "BTCUSDT", nil, snapshotInitCreator(100, done))
+	failed := d.Subscribe(10, depth_types.DropNewest, depth_types.DepthEventIntegrityFailed)
+
+	d.Lock()
+	d.EnableIntegrity(1, true)
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 95, LastUpdateID: 101})
+	d.Unlock()
+	waitSnapshot(t, done)
+
+	d.Lock()
+	assert.True(t, d.IsSynced())
+	assert.False(t, d.GetUpdatedAt().IsZero())
+	// Bid вище кращого ask, ask 110 у стріму не прибрано
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 102, LastUpdateID: 102,
+		Bids: []items_types.PriceLevel{{Price: 115, Quantity: 1}}})
+	assert.Equal(t, depth_types.SyncStateSyncing, d.GetSyncState())
+	assert.Equal(t, int64(1), d.GetResyncCount())
+	d.Unlock()
+
+	select {
+	case event := <-failed.C():
+		assert.Len(t, event.Issues, 1)
+		assert.Equal(t, depth_types.IntegrityCrossed, event.Issues[0].Type)
+		assert.Equal(t, []items_types.PriceLevel{{Price: 115, Quantity: 1}, {Price: 110, Quantity: 10}}, event.Issues[0].Levels)
+	default:
+		t.Fatal("integrity event wasn't sent")
+	}
+
+	waitSnapshot(t, done)
+	d.Lock()
+	defer d.Unlock()
+	assert.True(t, d.IsSynced())
+	assert.Nil(t, d.GetBids().Get(items_types.NewBid(115)))
+	assert.Empty(t, d.Validate())
+}
+
+func TestIntegrityChecks(t *testing.T) {
+	// Стакан без стріму, як у бектесті
+	d := depth_types.New(degree, "BTCUSDT", nil, nil)
+	d.Lock()
+	defer d.Unlock()
+	d.GetAsks().Set(items_types.NewAsk(110, 10))
+	d.GetBids().Set(items_types.NewBid(100, 10))
+	assert.Empty(t, d.Validate())
+
+	d.GetBids().Set(items_types.NewBid(90, 0))
+	issues := d.Validate(depth_types.CheckQuantities)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, []items_types.PriceLevel{{Price: 90, Quantity: 0}}, issues[0].Levels)
+
+	// Зміна кількості повз дерево ламає суми сторони
+	d.GetAsks().Get(items_types.NewAsk(110)).GetDepthItem().SetQuantity(20)
+	issues = d.Validate(depth_types.CheckTotals)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, depth_types.IntegrityTotals, issues[0].Type)
+
+	assert.Empty(t, d.Validate(depth_types.CheckStale(time.Minute)))
+	d.ApplyPartialDepth(1, []*items_types.Bid{items_types.NewBid(100, 10)}, []*items_types.Ask{items_types.NewAsk(110, 10)})
+	assert.Empty(t, d.Validate(depth_types.CheckStale(time.Minute)))
+	time.Sleep(10 * time.Millisecond)
+	issues = d.Validate(depth_types.CheckStale(time.Millisecond))
+	assert.Len(t, issues, 1)
+	assert.Equal(t, depth_types.IntegrityStale, issues[0].Type)
+}
+
+func TestIntegrityTimer(t *testing.T) {
+	d := depth_types.New(degree, "BTCUSDT", nil, nil)
+	failed := d.Subscribe(10, depth_types.DropNewest, depth_types.DepthEventIntegrityFailed)
+	d.Lock()
+	d.ApplyPartialDepth(1, []*items_types.Bid{items_types.NewBid(100, 10)}, []*items_types.Ask{items_types.NewAsk(110, 10)})
+	d.EnableIntegrity(0, true, depth_types.CheckStale(time.Millisecond))
+	d.StartIntegrityTimer(5 * time.Millisecond)
+	d.Unlock()
+
+	select {
+	case event := <-failed.C():
+		assert.Equal(t, depth_types.IntegrityStale, event.Issues[0].Type)
+	case <-time.After(timeOut):
+		t.Fatal("stale book wasn't detected")
+	}
+	d.Lock()
+	d.DisableIntegrity()
+	// Без Init ресинхронізація не запускається
+	assert.True(t, d.IsSynced())
+	d.Unlock()
+}
+
+func TestIntegrityStaleWhileSyncing(t *testing.T) {
+	release := make(chan struct{})
+	d := depth_types.New(degree, "BTCUSDT", nil, func(d *depth_types.Depths) types.InitFunction {
+		return func() error {
+			// Знімок завис
+			<-release
+			return nil
+		}
+	})
+	defer close(release)
+	d.Lock()
+	defer d.Unlock()
+	d.EnableIntegrity(0, true, depth_types.DefaultIntegrityChecks()...)
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 1, LastUpdateID: 2,
+		Bids: []items_types.PriceLevel{{Price: 120, Quantity: 1}}})
+	assert.Equal(t, depth_types.SyncStateSyncing, d.GetSyncState())
+	assert.Empty(t, d.CheckIntegrity())
+
+	d.EnableIntegrity(0, true, depth_types.CheckStale(time.Millisecond), depth_types.CheckCrossed)
+	time.Sleep(5 * time.Millisecond)
+	issues := d.CheckIntegrity()
+	assert.Len(t, issues, 1)
+	assert.Equal(t, depth_types.IntegrityStale, issues[0].Type)
+	assert.Equal(t, depth_types.SyncStateSyncing, d.GetSyncState())
+}
+
+func TestIntegrityTotalsToleratesFloatDrift(t *testing.T) {
+	d := depth_types.New(degree, "BTCUSDT", nil, nil)
+	d.Lock()
+	defer d.Unlock()
+	// Великі кількості, які потім знімаються, лишають в інкрементальних сумах похибку float
+	for i := 0; i < 200000; i++ {
+		price := items_types.PriceType(60000.1 + float64(i%500)*0.1)
+		d.GetAsks().UpdateLevel(price, items_types.QuantityType(float64((i*7919)%100000)/100+0.001))
+	}
+	for i := 0; i < 500; i++ {
+		d.GetAsks().UpdateLevel(items_types.PriceType(60000.1+float64(i)*0.1), 0)
+	}
+	d.GetAsks().UpdateLevel(60000.1, 0.001)
+	assert.Empty(t, d.Validate(depth_types.CheckTotals))
+}
@@ -0,0 +1,195 @@
+package types
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/fr0ster/go-trading-utils/utils/decimal"
+)
+
+const (
+	// Точність ключа ціни в дереві, Binance не має тіків менших за 1e-8
+	PriceKeyScale = 8
+
+	priceKeyOverflow = math.MaxInt64
+)
+
+// PriceKey повертає ключ дерева для ціни: її найкоротше десяткове представлення,
+// тобто рядок ціни з API, округлене до PriceKeyScale знаків як ціле число
+func PriceKey(price PriceType) int64 {
+	key, ok := decimal.ScaledFromFloat(float64(price), PriceKeyScale)
+	if !ok || key == priceKeyOverflow {
+		return priceKeyOverflow
+	}
+	return key
+}
+
+// PriceKeyFromDecimal повертає ключ дерева для точної ціни без перетворення через float64
+func PriceKeyFromDecimal(price decimal.Decimal) int64 {
+	key, ok := price.Scaled(PriceKeyScale)
+	if !ok || key == priceKeyOverflow {
+		return priceKeyOverflow
+	}
+	return key
+}
+
+// Ціни поза діапазоном ключа порівнюються як float64
+func lessKey(key, thanKey int64, price, thanPrice PriceType) bool {
+	if key == priceKeyOverflow || thanKey == priceKeyOverflow {
+		return price < thanPrice
+	}
+	return key < thanKey
+}
+
+func equalKey(key, thanKey int64, price, thanPrice PriceType) bool {
+	if key == priceKeyOverflow || thanKey == priceKeyOverflow {
+		return price == thanPrice
+	}
+	return key == thanKey
+}
+
+// NewPriceLevel створює рівень з точних значень, ключ дерева береться з точної ціни, а не з float64
+func NewPriceLevel(price, quantity decimal.Decimal) PriceLevel {
+	return PriceLevel{
+		Price:    PriceFromDecimal(price),
+		Quantity: QuantityFromDecimal(quantity),
+		key:      PriceKeyFromDecimal(price),
+	}
+}
+
+// ParsePriceLevel розбирає рівень з рядків ціни та кількості API біржі без проміжного float64 для ключа
+func ParsePriceLevel(price, quantity string) (level PriceLevel, err error) {
+	p, err := decimal.Parse(price)
+	if err != nil {
+		return
+	}
+	q, err := decimal.Parse(quantity)
+	if err != nil {
+		return
+	}
+	return NewPriceLevel(p, q), nil
+}
+
+// GetKey повертає ключ дерева рівня
+func (l PriceLevel) GetKey() int64 {
+	if l.key == 0 {
+		return PriceKey(l.Price)
+	}
+	return l.key
+}
+
+// NewFromDecimal створює рівень стакану з точних значень, наприклад розібраних з рядків API
+func NewFromDecimal(price, quantity decimal.Decimal) *DepthItem {
+	return NewFromLevel(NewPriceLevel(price, quantity))
+}
+
+// NewFromLevel створює рівень стакану з ключем дерева level
+func NewFromLevel(level PriceLevel) *DepthItem {
+	return &DepthItem{price: level.Price, quantity: level.Quantity, key: level.GetKey()}
+}
+
+func NewAskFromLevel(level PriceLevel) *Ask {
+	return (*Ask)(NewFromLevel(level))
+}
+
+func NewBidFromLevel(level PriceLevel) *Bid {
+	return (*Bid)(NewFromLevel(level))
+}
+
+// SetPriceLevel встановлює ціну та ключ дерева рівня level, кількість не змінюється
+func (i *DepthItem) SetPriceLevel(level PriceLevel) {
+	if i != nil {
+		i.price = level.Price
+		i.key = level.GetKey()
+	}
+}
+
+// GetPriceDecimal повертає ціну рівня як точне десяткове число
+func (i *DepthItem) GetPriceDecimal() decimal.Decimal {
+	return i.GetPrice().Decimal()
+}
+
+// GetQuantityDecimal повертає кількість рівня як точне десяткове число
+func (i *DepthItem) GetQuantityDecimal() decimal.Decimal {
+	return i.GetQuantity().Decimal()
+}
+
+func PriceFromDecimal(d decimal.Decimal) PriceType {
+	return PriceType(d.Float64())
+}
+
+func QuantityFromDecimal(d decimal.Decimal) QuantityType {
+	return QuantityType(d.Float64())
+}
+
+func ValueFromDecimal(d decimal.Decimal) ValueType {
+	return ValueType(d.Float64())
+}
+
+// Decimal повертає найкоротше десяткове представлення ціни, для неможливих значень - нуль
+func (p PriceType) Decimal() decimal.Decimal {
+	d, _ := decimal.NewFromFloat(float64(p))
+	return d
+}
+
+// Decimal повертає найкоротше десяткове представлення кількості, для неможливих значень - нуль
+func (q QuantityType) Decimal() decimal.Decimal {
+	d, _ := decimal.NewFromFloat(float64(q))
+	return d
+}
+
+// Decimal повертає найкоротше десяткове представлення вартості, для неможливих значень - нуль
+func (v ValueType) Decimal() decimal.Decimal {
+	d, _ := decimal.NewFromFloat(float64(v))
+	return d
+}
+
+// Format повертає ціну з scale знаками після коми, округлену до найближчого, рядком для запиту до біржі
+func (p PriceType) Format(scale int) string {
+	if d, err := p.Decimal().TryRescale(clampScale(scale), decimal.RoundHalfUp); err == nil {
+		return d.String()
+	}
+	// Ціна, яка з scale знаками не вміщується в decimal
+	return strconv.FormatFloat(float64(p), 'f', int(clampScale(scale)), 64)
+}
+
+// Format повертає кількість з scale знаками після коми, округлену вниз,
+// щоб ордер не перевищив доступну кількість, рядком для запиту до біржі.
+// До переходу на decimal кількість в ордерах округлювалась до найближчого.
+func (q QuantityType) Format(scale int) string {
+	if d, err := q.Decimal().TryRescale(clampScale(scale), decimal.RoundFloor); err == nil {
+		return d.String()
+	}
+	pow := math.Pow10(int(clampScale(scale)))
+	return strconv.FormatFloat(math.Floor(float64(q)*pow)/pow, 'f', int(clampScale(scale)), 64)
+}
+
+// RoundToStep округлює value до кратного step в десятковій арифметиці,
+// щоб 0.29 з кроком 0.01 лишалось 0.29, а не 0.28
+func RoundToStep(value float64, step decimal.Decimal, mode decimal.RoundingMode) float64 {
+	d, err := decimal.NewFromFloat(value)
+	if err != nil {
+		return value
+	}
+	if d, err = d.TryRoundToStep(step, mode); err == nil {
+		return d.Float64()
+	}
+	// Значення, яке з кроком не вміщується в decimal, округлюється у float64
+	if step.Sign() <= 0 {
+		return value
+	}
+	steps := value / step.Float64()
+	switch mode {
+	case decimal.RoundFloor:
+		steps = math.Floor(steps)
+	case decimal.RoundCeil:
+		steps = math.Ceil(steps)
+	default:
+		steps = math.Round(steps)
+	}
+	return steps * step.Float64()
+}
+
+func clampScale(scale int) int32 {
+	return int32(min(max(scale, 0), decimal.MaxScale))
+}
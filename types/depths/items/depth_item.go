@@ -10,6 +10,10 @@ type (
 	DepthItem struct {
 		price    PriceType
 		quantity QuantityType
+		// Ключ дерева - ціна з фіксованою точністю, щоб 1e-12 похибки float не давали різні рівні
+		key int64
+		// Покоління стакану, якому належить рівень, див. Acquire
+		generation uint64
 	}
 	DepthFilter   func(*DepthItem) bool
 	DepthTester   func(result *DepthItem, target *DepthItem) bool
@@ -18,18 +22,18 @@ type (
 
 // Функції для btree.Btree
 func (i *DepthItem) Less(than btree.Item) bool {
-	return i.price < than.(*DepthItem).price
+	return lessKey(i.key, than.(*DepthItem).key, i.price, than.(*DepthItem).price)
 }
 
 func (i *DepthItem) Equal(than btree.Item) bool {
-	return i.price == than.(*DepthItem).price
+	return equalKey(i.key, than.(*DepthItem).key, i.price, than.(*DepthItem).price)
 }
 func (i *Ask) Less(than btree.Item) bool {
-	return i.price < than.(*Ask).price
+	return lessKey(i.key, than.(*Ask).key, i.price, than.(*Ask).price)
 }
 
 func (i *Ask) Equal(than btree.Item) bool {
-	return i.price == than.(*Ask).price
+	return equalKey(i.key, than.(*Ask).key, i.price, than.(*Ask).price)
 }
 
 func (i *Ask) GetDepthItem() *DepthItem {
@@ -41,11 +45,11 @@ func (i *Ask) GetDepthItem() *DepthItem {
 }
 
 func (i *Bid) Less(than btree.Item) bool {
-	return i.price < than.(*Bid).price
+	return lessKey(i.key, than.(*Bid).key, i.price, than.(*Bid).price)
 }
 
 func (i *Bid) Equal(than btree.Item) bool {
-	return i.price == than.(*Bid).price
+	return equalKey(i.key, than.(*Bid).key, i.price, than.(*Bid).price)
 }
 
 func (i *Bid) GetDepthItem() *DepthItem {
@@ -68,6 +72,7 @@ func (i *DepthItem) GetPrice() PriceType {
 func (i *DepthItem) SetPrice(price PriceType) {
 	if i != nil {
 		i.price = price
+		i.key = PriceKey(price)
 	}
 }
 
@@ -101,24 +106,24 @@ func (d *DepthItem) GetQuantityDeviation(middle QuantityType) float64 {
 // Конструктори
 func New(price PriceType, quantity ...QuantityType) *DepthItem {
 	if len(quantity) > 0 {
-		return &DepthItem{price: price, quantity: quantity[0]}
+		return &DepthItem{price: price, quantity: quantity[0], key: PriceKey(price)}
 	} else {
-		return &DepthItem{price: price}
+		return &DepthItem{price: price, key: PriceKey(price)}
 	}
 }
 
 func NewAsk(price PriceType, quantity ...QuantityType) *Ask {
 	if len(quantity) > 0 {
-		return &Ask{price: price, quantity: quantity[0]}
+		return &Ask{price: price, quantity: quantity[0], key: PriceKey(price)}
 	} else {
-		return &Ask{price: price}
+		return &Ask{price: price, key: PriceKey(price)}
 	}
 }
 
 func NewBid(price PriceType, quantity ...QuantityType) *Bid {
 	if len(quantity) > 0 {
-		return &Bid{price: price, quantity: quantity[0]}
+		return &Bid{price: price, quantity: quantity[0], key: PriceKey(price)}
 	} else {
-		return &Bid{price: price}
+		return &Bid{price: price, key: PriceKey(price)}
 	}
 }
@@ -16,6 +16,7 @@ type (
 		// Дані по ціні
 		price    PriceType
 		quantity QuantityType
+		value    ValueType
 		minMax   *btree.BTree
 		depths   *btree.BTree
 	}
@@ -37,18 +38,35 @@ func (i *NormalizedItem) GetNormalizedPrice() PriceType {
 
 func (i *NormalizedItem) Add(price PriceType, quantity QuantityType) {
 	normalizedPrice := getNormalizedPrice(price, i.exp, i.roundUp)
-	if normalizedPrice == i.price {
+	if normalizedPrice == i.price && quantity != 0 {
+		// Якщо рівень вже є, спочатку прибираємо його стару кількість
+		if old := i.GetDepth(price); old != nil {
+			i.Delete(price, old.GetQuantity())
+		}
 		i.quantity += quantity
+		i.value += ValueType(price) * ValueType(quantity)
 		i.depths.ReplaceOrInsert(New(price, quantity))
-		i.minMax.ReplaceOrInsert(NewQuantityItem(normalizedPrice, quantity, i.degree))
+		if minMax := i.GetMinMax(quantity); minMax != nil {
+			minMax.Add(price, quantity)
+		} else {
+			i.minMax.ReplaceOrInsert(NewQuantityItem(price, quantity, i.degree))
+		}
 	}
 }
 
 func (i *NormalizedItem) Delete(price PriceType, quantity QuantityType) {
-	if old := i.GetDepth(price); old != nil {
-		i.quantity -= quantity
+	old := i.GetDepth(price)
+	if old == nil {
+		return
+	}
+	i.quantity -= old.GetQuantity()
+	i.value -= old.GetValue()
+	if minMax := i.GetMinMax(old.GetQuantity()); minMax != nil {
+		minMax.Delete(price, old.GetQuantity())
+		if minMax.IsShouldDelete() {
+			i.minMax.Delete(minMax)
+		}
 	}
-	i.minMax.Delete(NewQuantityItem(price, quantity, i.degree))
 	i.depths.Delete(New(price))
 }
 
@@ -61,6 +79,22 @@ func (i *NormalizedItem) SetQuantity(quantity QuantityType) {
 	i.quantity = quantity
 }
 
+func (i *NormalizedItem) GetValue() ValueType {
+	return i.value
+}
+
+func (i *NormalizedItem) Count() int {
+	return i.depths.Len()
+}
+
+// GetMaxDepth повертає найбільший за кількістю рівень стакану в межах кошика
+func (i *NormalizedItem) GetMaxDepth() *DepthItem {
+	if val := i.minMax.Max(); val != nil {
+		return val.(*QuantityItem).GetDepthMax()
+	}
+	return nil
+}
+
 func (i *NormalizedItem) GetMinMaxes() *btree.BTree {
 	return i.minMax
 }
@@ -68,7 +102,9 @@ func (i *NormalizedItem) GetMinMaxes() *btree.BTree {
 // Робота зі стаканом
 func (i *NormalizedItem) GetDepth(price PriceType) (depthItem *DepthItem) {
 	if i.depths != nil {
-		depthItem = i.depths.Get(New(price)).(*DepthItem)
+		if val := i.depths.Get(New(price)); val != nil {
+			depthItem = val.(*DepthItem)
+		}
 	}
 	return
 }
@@ -122,10 +158,11 @@ func NewNormalizedItem(price PriceType, degree int, exp int, roundUp bool, quant
 		// Дані по ціні
 		price:    normalizedPrice,
 		quantity: quantity,
+		value:    ValueType(price) * ValueType(quantity),
 		minMax:   btree.New(degree),
 		depths:   btree.New(degree)}
 	if quantity != 0 {
-		item.minMax.ReplaceOrInsert(NewQuantityItem(normalizedPrice, quantity, degree))
+		item.minMax.ReplaceOrInsert(NewQuantityItem(price, quantity, degree))
 		item.depths.ReplaceOrInsert(New(price, quantity))
 	}
 	return item
@@ -0,0 +1,49 @@
+package types
+
+import "sync"
+
+type (
+	// PriceLevel - рівень стакану за значенням, використовується в подіях стріму,
+	// щоб розбір подій не алокував окремий вузол на кожен рівень.
+	// Рівень з ParsePriceLevel або NewPriceLevel несе ключ дерева з точної ціни,
+	// для рівня, заданого лише Price, ключ рахується з float64.
+	PriceLevel struct {
+		Price    PriceType
+		Quantity QuantityType
+		key      int64
+	}
+)
+
+var itemPool = sync.Pool{New: func() any { return new(DepthItem) }}
+
+// Acquire повертає рівень з пулу, generation - покоління стакану-власника,
+// тільки власник цього покоління може змінювати рівень на місці
+func Acquire(price PriceType, quantity QuantityType, generation uint64) *DepthItem {
+	return AcquireLevel(PriceLevel{Price: price, Quantity: quantity}, generation)
+}
+
+// AcquireLevel - Acquire з ключем дерева рівня level
+func AcquireLevel(level PriceLevel, generation uint64) *DepthItem {
+	item := itemPool.Get().(*DepthItem)
+	item.price = level.Price
+	item.quantity = level.Quantity
+	item.key = level.GetKey()
+	item.generation = generation
+	return item
+}
+
+// Release повертає рівень у пул, після виклику рівень не можна використовувати
+func Release(item *DepthItem) {
+	if item != nil {
+		*item = DepthItem{}
+		itemPool.Put(item)
+	}
+}
+
+// GetGeneration повертає покоління стакану, який створив рівень, 0 - рівень створено поза пулом
+func (i *DepthItem) GetGeneration() uint64 {
+	if i != nil {
+		return i.generation
+	}
+	return 0
+}
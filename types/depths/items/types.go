@@ -1,5 +1,10 @@
 package types
 
+// Ціни, кількості та вартості зберігаються в стакані й рахуються у float64, разом з сумами та аналітикою.
+// Через decimal точні лише ключ дерева (PriceKey, ParsePriceLevel), округлення до кроку (RoundToStep)
+// та рядки ордерів (Format, Processor.FormatPrice, Processor.FormatQuantity).
+// Зберігання стакану та арифметика процесора в decimal.Decimal - окрема задача:
+// int64 в decimal не вміщує вартості та їх суми з масштабом добутку ціни на кількість.
 type (
 	PriceType                  float64
 	PricePercentType           float64
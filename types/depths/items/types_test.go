@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	"github.com/fr0ster/go-trading-utils/utils/decimal"
+	"github.com/google/btree"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -25,3 +27,66 @@ func TestGetNormalizedPrice(t *testing.T) {
 	price = types.NewNormalizedItem(1.941, 3, 0, true).GetNormalizedPrice()
 	assert.Equal(t, types.PriceType(2.0), price)
 }
+
+func TestPriceKey(t *testing.T) {
+	tree := btree.New(3)
+	price := 0.1 + 0.2
+	tree.ReplaceOrInsert(types.New(types.PriceType(price), 1))
+	// Похибка float не створює окремий рівень
+	tree.ReplaceOrInsert(types.New(0.3, 2))
+	assert.Equal(t, 1, tree.Len())
+	assert.Equal(t, types.QuantityType(2), tree.Get(types.New(0.3)).(*types.DepthItem).GetQuantity())
+	tree.ReplaceOrInsert(types.New(0.30000001, 3))
+	assert.Equal(t, 2, tree.Len())
+
+	item := types.NewFromDecimal(decimal.MustParse("67100.10"), decimal.MustParse("0.29"))
+	assert.Equal(t, "67100.1", item.GetPriceDecimal().String())
+	assert.Equal(t, "0.29", item.GetQuantityDecimal().String())
+	// Рівень з рядка API та рівень з float64 потрапляють в один ключ
+	tree.ReplaceOrInsert(types.NewFromDecimal(decimal.MustParse("0.30000000"), decimal.MustParse("4")))
+	assert.Equal(t, 2, tree.Len())
+	assert.Equal(t, types.QuantityType(4), tree.Get(types.New(types.PriceType(price))).(*types.DepthItem).GetQuantity())
+	assert.Equal(t, types.PriceKeyFromDecimal(decimal.MustParse("67100.1")), types.PriceKey(67100.1))
+}
+
+func TestParsePriceLevel(t *testing.T) {
+	// Ціни, які float64 не розрізняє, мають різні ключі, коли розібрані з рядків API
+	low, err := types.ParsePriceLevel("1234567891.12345678", "1")
+	assert.NoError(t, err)
+	high, err := types.ParsePriceLevel("1234567891.12345679", "2")
+	assert.NoError(t, err)
+	assert.Equal(t, low.Price, high.Price)
+	assert.Equal(t, types.PriceKey(low.Price), types.PriceKey(high.Price))
+	assert.Less(t, low.GetKey(), high.GetKey())
+
+	tree := btree.New(3)
+	tree.ReplaceOrInsert(types.NewFromLevel(low))
+	tree.ReplaceOrInsert(types.NewFromLevel(high))
+	assert.Equal(t, 2, tree.Len())
+	assert.Equal(t, types.QuantityType(2), tree.Max().(*types.DepthItem).GetQuantity())
+
+	// Рівень без ключа використовує ключ з float64
+	level := types.PriceLevel{Price: 0.1 + 0.2, Quantity: 1}
+	assert.Equal(t, types.PriceKey(0.3), level.GetKey())
+
+	_, err = types.ParsePriceLevel("1.2.3", "1")
+	assert.ErrorIs(t, err, decimal.ErrSyntax)
+	_, err = types.ParsePriceLevel("1", "x")
+	assert.ErrorIs(t, err, decimal.ErrSyntax)
+}
+
+func TestFormat(t *testing.T) {
+	assert.Equal(t, "0.29", types.QuantityType(0.29).Format(2))
+	assert.Equal(t, "0.28", types.QuantityType(0.289).Format(2))
+	assert.Equal(t, "1.01", types.PriceType(1.005).Format(2))
+	assert.Equal(t, "67100", types.PriceType(67100.04).Format(1))
+	assert.Equal(t, "3", types.QuantityType(3).Format(-1))
+
+	// Кількість в ордерах тепер округлюється вниз, раніше ConvFloat64ToStr давав "0.29"
+	assert.Equal(t, "0.28", types.QuantityType(0.285).Format(2))
+	assert.Equal(t, "0.29", types.PriceType(0.285).Format(2))
+
+	// Значення поза діапазоном decimal не панікують
+	assert.Equal(t, "200000000000.00000000", types.PriceType(2e11).Format(8))
+	assert.Equal(t, "200000000000.00000000", types.QuantityType(2e11).Format(8))
+}
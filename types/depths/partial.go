@@ -0,0 +1,93 @@
+package depth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/btree"
+
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+const (
+	// Спотовий стрім часткового стакану має лише 100ms та 1000ms
+	DepthStreamRate1000ms DepthStreamRate = DepthStreamRate(1000 * time.Millisecond)
+)
+
+// Validate перевіряє, що кількість рівнів підтримується стрімом часткового стакану
+func (l DepthStreamLevel) Validate() error {
+	switch l {
+	case DepthStreamLevel5, DepthStreamLevel10, DepthStreamLevel20:
+		return nil
+	default:
+		return fmt.Errorf("depth stream level %d isn't supported, use 5, 10 or 20", l)
+	}
+}
+
+// Validate перевіряє, що частота оновлення підтримується стрімами стакану
+func (r DepthStreamRate) Validate() error {
+	switch r {
+	case DepthStreamRate100ms, DepthStreamRate250ms, DepthStreamRate500ms, DepthStreamRate1000ms:
+		return nil
+	default:
+		return fmt.Errorf("depth stream rate %v isn't supported, use 100ms, 250ms, 500ms or 1000ms", time.Duration(r))
+	}
+}
+
+// SetPartialDepth позначає стакан, який оновлюється стрімом часткового стакану
+func (d *Depths) SetPartialDepth(partial bool) {
+	d.partialDepth = partial
+}
+
+// IsPartialDepth повертає true, якщо стакан оновлюється стрімом часткового стакану
+func (d *Depths) IsPartialDepth() bool {
+	return d.partialDepth
+}
+
+// ApplyPartialDepth замінює обидві сторони стакану топ-N знімком зі стріму часткового стакану.
+// Кожне повідомлення такого стріму - повний знімок, тому REST знімок та перевірка безперервності не потрібні.
+// Рівні, яких немає в знімку, видаляються, тому підписники отримують тільки реальні зміни.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) ApplyPartialDepth(lastUpdateID int64, bids []*items_types.Bid, asks []*items_types.Ask) {
+	// Повідомлення, що прийшло не по порядку
+	if lastUpdateID < d.LastUpdateID {
+		return
+	}
+	keepBids := make(map[int64]bool, len(bids))
+	for _, bid := range bids {
+		keepBids[items_types.PriceKey(bid.GetDepthItem().GetPrice())] = true
+	}
+	for _, price := range missingPrices(d.bids.GetTree(), keepBids) {
+		d.bids.Delete(items_types.NewBid(price))
+	}
+	for _, bid := range bids {
+		d.bids.Update(bid)
+	}
+	keepAsks := make(map[int64]bool, len(asks))
+	for _, ask := range asks {
+		keepAsks[items_types.PriceKey(ask.GetDepthItem().GetPrice())] = true
+	}
+	for _, price := range missingPrices(d.asks.GetTree(), keepAsks) {
+		d.asks.Delete(items_types.NewAsk(price))
+	}
+	for _, ask := range asks {
+		d.asks.Update(ask)
+	}
+	d.LastUpdateID = lastUpdateID
+	d.setSyncState(SyncStateSynced)
+	d.resyncFailedAt = time.Time{}
+	d.Trim()
+	d.UpdateAnalytics()
+	d.PublishEvents(false)
+	d.afterUpdate()
+}
+
+func missingPrices(tree *btree.BTree, keep map[int64]bool) (prices []items_types.PriceType) {
+	tree.Ascend(func(i btree.Item) bool {
+		if price := i.(*items_types.DepthItem).GetPrice(); !keep[items_types.PriceKey(price)] {
+			prices = append(prices, price)
+		}
+		return true
+	})
+	return
+}
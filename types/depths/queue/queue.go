@@ -0,0 +1,320 @@
+package queue
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	orders_types "github.com/fr0ster/go-trading-utils/types/orders"
+	aggtrade_types "github.com/fr0ster/go-trading-utils/types/trades/aggtrade"
+	"github.com/fr0ster/go-trading-utils/utils"
+)
+
+const (
+	// Скільки оновлень стакану чекати на угоди після зменшення рівня, бо стріми угод та стакану не синхронізовані.
+	// Зменшення, яке за цей час не пояснили угоди, вважається скасуванням.
+	settleUpdates = 2
+)
+
+type (
+	// CancelModel розподіляє скасований на рівні обсяг cancelled між заявками перед нами (ahead)
+	// та за нами (behind) і повертає нову кількість перед нами
+	CancelModel func(ahead, behind, cancelled items_types.QuantityType) items_types.QuantityType
+	// Position - оцінка місця нашого ордера в черзі рівня
+	Position struct {
+		OrderID  int64
+		Side     types.DepthSide
+		Price    items_types.PriceType
+		Quantity items_types.QuantityType
+		// Обсяг перед нами в черзі, на момент розміщення та поточний
+		InitialAhead items_types.QuantityType
+		Ahead        items_types.QuantityType
+		// Обсяг, який став у чергу після нас
+		Behind items_types.QuantityType
+		// Обсяг угод по ціні ордера з моменту розміщення
+		Traded   items_types.QuantityType
+		PlacedAt time.Time
+		// Ціну пробито угодами, рівень виконано повністю
+		Crossed bool
+
+		// Наш ордер ще не з'явився в стакані, перше збільшення рівня - це він
+		unseen items_types.QuantityType
+		// Угоди, ще не враховані зменшенням рівня
+		pending items_types.QuantityType
+		// Зменшення рівня, ще не пояснене угодами, та оновлення стакану, коли воно змінилось
+		unexplained        items_types.QuantityType
+		unexplainedAt      int64
+		unexplainedUpdates int
+	}
+	// Estimator оцінює чергу перед нашими лімітними ордерами по угодах AggTrades та змінах рівнів depth.Depths
+	Estimator struct {
+		mutex        sync.Mutex
+		depths       *depth_types.Depths
+		subscription *depth_types.Subscription
+		model        CancelModel
+		positions    map[int64]*Position
+		closed       bool
+	}
+)
+
+// ProportionalCancelModel - скасування рівномірно розподілені по черзі
+func ProportionalCancelModel(ahead, behind, cancelled items_types.QuantityType) items_types.QuantityType {
+	if ahead+behind <= 0 {
+		return 0
+	}
+	return clamp(ahead - cancelled*ahead/(ahead+behind))
+}
+
+// PessimisticCancelModel - скасовують тільки заявки за нами, поки вони є
+func PessimisticCancelModel(ahead, behind, cancelled items_types.QuantityType) items_types.QuantityType {
+	if cancelled <= behind {
+		return ahead
+	}
+	return clamp(ahead - (cancelled - behind))
+}
+
+// OptimisticCancelModel - скасовують насамперед заявки перед нами
+func OptimisticCancelModel(ahead, behind, cancelled items_types.QuantityType) items_types.QuantityType {
+	return clamp(ahead - cancelled)
+}
+
+// QueueRatio повертає частку рівня перед нами, 0 - ми перші в черзі
+func (p *Position) QueueRatio() float64 {
+	total := p.Ahead + p.Quantity + p.Behind
+	if total <= 0 {
+		return 0
+	}
+	return float64(p.Ahead / total)
+}
+
+// New створює оцінювач, model == nil означає ProportionalCancelModel.
+// Блокує trades, тому не можна викликати під блокуванням AggTrades.
+func New(depths *depth_types.Depths, trades *aggtrade_types.AggTrades, model CancelModel) *Estimator {
+	if model == nil {
+		model = ProportionalCancelModel
+	}
+	this := &Estimator{
+		depths:    depths,
+		model:     model,
+		positions: make(map[int64]*Position),
+	}
+	this.subscription = depths.SubscribeCallback(this.onDepthEvent,
+		depth_types.DepthEventLevelAdded,
+		depth_types.DepthEventLevelChanged,
+		depth_types.DepthEventLevelRemoved,
+		depth_types.DepthEventResynced,
+		depth_types.DepthEventUpdated)
+	if trades != nil {
+		trades.Lock()
+		trades.AddTradeHandler(this.onTrade)
+		trades.Unlock()
+	}
+	return this
+}
+
+// Close відписує оцінювач від подій стакану, угоди після цього ігноруються
+func (e *Estimator) Close() {
+	e.mutex.Lock()
+	e.closed = true
+	e.mutex.Unlock()
+	e.depths.Unsubscribe(e.subscription)
+}
+
+// Add починає відстежувати лімітний ордер. Кількість рівня на момент виклику вважається чергою перед ордером,
+// тому Add потрібно викликати одразу після розміщення, до появи ордера в стакані.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (e *Estimator) Add(order *orders_types.Order) (err error) {
+	var side types.DepthSide
+	switch order.Side {
+	case types.SideType(types.SideTypeBuy):
+		side = types.DepthSideBid
+	case types.SideType(types.SideTypeSell):
+		side = types.DepthSideAsk
+	default:
+		return fmt.Errorf("order %v has unknown side %v", order.OrderID, order.Side)
+	}
+	price := items_types.PriceType(utils.ConvStrToFloat64(order.Price))
+	if price <= 0 {
+		return fmt.Errorf("order %v has invalid price %v", order.OrderID, order.Price)
+	}
+	quantity := items_types.QuantityType(utils.ConvStrToFloat64(order.OrigQuantity) - utils.ConvStrToFloat64(order.ExecutedQuantity))
+	placedAt := time.Now()
+	if order.Time > 0 {
+		placedAt = time.UnixMilli(order.Time)
+	}
+	ahead := e.levelQuantity(side, price)
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.positions[order.OrderID] = &Position{
+		OrderID:      order.OrderID,
+		Side:         side,
+		Price:        price,
+		Quantity:     quantity,
+		InitialAhead: ahead,
+		Ahead:        ahead,
+		PlacedAt:     placedAt,
+		unseen:       quantity,
+	}
+	return
+}
+
+// Remove припиняє відстеження ордера, наприклад після виконання або скасування
+func (e *Estimator) Remove(orderID int64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	delete(e.positions, orderID)
+}
+
+// Get повертає копію поточної оцінки черги ордера
+func (e *Estimator) Get(orderID int64) (position Position, ok bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if p, exists := e.positions[orderID]; exists {
+		return *p, true
+	}
+	return
+}
+
+// GetFillProbability оцінює ймовірність повного виконання ордера за horizon.
+// Обсяг угод по ціні ордера вважається експоненційно розподіленим із середнім,
+// отриманим з темпу угод з моменту розміщення, тому без угод по ціні ймовірність 0.
+func (e *Estimator) GetFillProbability(orderID int64, horizon time.Duration) (probability float64, err error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	p, ok := e.positions[orderID]
+	if !ok {
+		return 0, fmt.Errorf("order %v is not tracked", orderID)
+	}
+	if p.Crossed {
+		return 1, nil
+	}
+	elapsed := time.Since(p.PlacedAt)
+	if p.Traded <= 0 || elapsed <= 0 {
+		return 0, nil
+	}
+	expected := float64(p.Traded) * float64(horizon) / float64(elapsed)
+	return math.Exp(-float64(p.Ahead+p.Quantity) / expected), nil
+}
+
+// Покупець-мейкер означає, що угода виконала bid, інакше - ask
+func (e *Estimator) onTrade(trade *aggtrade_types.AggTrade) {
+	side := types.DepthSideAsk
+	if trade.IsBuyerMaker {
+		side = types.DepthSideBid
+	}
+	e.AddTrade(side,
+		items_types.PriceType(utils.ConvStrToFloat64(trade.Price)),
+		items_types.QuantityType(utils.ConvStrToFloat64(trade.Quantity)))
+}
+
+// AddTrade враховує угоду по пасивній стороні side, для угод не з AggTrades.
+// Угоди виконують чергу з голови, тому спочатку зменшують обсяг перед нами.
+func (e *Estimator) AddTrade(side types.DepthSide, price items_types.PriceType, quantity items_types.QuantityType) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.closed {
+		return
+	}
+	key := items_types.PriceKey(price)
+	for _, p := range e.positions {
+		if p.Side != side {
+			continue
+		}
+		positionKey := items_types.PriceKey(p.Price)
+		switch {
+		case key == positionKey:
+			p.Traded += quantity
+			// Зменшення рівня могло прийти раніше за угоду, тоді воно вже пояснене
+			explained := items_types.QuantityType(math.Min(float64(quantity), float64(p.unexplained)))
+			p.unexplained -= explained
+			p.pending += quantity - explained
+			p.Ahead = clamp(p.Ahead - quantity)
+		case side == types.DepthSideBid && key < positionKey, side == types.DepthSideAsk && key > positionKey:
+			// Угода за гіршою ціною означає, що наш рівень вже виконано повністю
+			p.Ahead = 0
+			p.Crossed = true
+		}
+	}
+}
+
+// Обробник викликається під блокуванням Depths
+func (e *Estimator) onDepthEvent(event *depth_types.DepthEvent) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if event.Type == depth_types.DepthEventResynced {
+		// Черга не може бути більшою за рівень після знімку
+		for _, p := range e.positions {
+			level := e.levelQuantity(p.Side, p.Price)
+			if p.Ahead > level {
+				p.Ahead = level
+			}
+			p.pending = 0
+			p.unexplained, p.unexplainedUpdates = 0, 0
+		}
+		return
+	}
+	if event.Type == depth_types.DepthEventUpdated {
+		e.settle(event.LastUpdateID)
+		return
+	}
+	key := items_types.PriceKey(event.Price)
+	for _, p := range e.positions {
+		if p.Side != event.Side || items_types.PriceKey(p.Price) != key {
+			continue
+		}
+		delta := event.Quantity - event.OldQuantity
+		if delta > 0 {
+			// Наш ордер з'являється в стакані, решта стає в чергу за нами
+			own := items_types.QuantityType(math.Min(float64(delta), float64(p.unseen)))
+			p.unseen -= own
+			p.Behind += delta - own
+			continue
+		}
+		decrease := -delta
+		explained := items_types.QuantityType(math.Min(float64(decrease), float64(p.pending)))
+		p.pending -= explained
+		if rest := decrease - explained; rest > 0 {
+			// Угоди можуть прийти пізніше, скасування враховується після settleUpdates оновлень
+			p.unexplained += rest
+			p.unexplainedAt = event.LastUpdateID
+			p.unexplainedUpdates = 0
+		}
+	}
+}
+
+// Зменшення, не пояснені угодами за settleUpdates оновлень, передаються в CancelModel.
+// Викликається з обробника подій, тобто під блокуванням Depths.
+func (e *Estimator) settle(lastUpdateID int64) {
+	for _, p := range e.positions {
+		if p.unexplained <= 0 || p.unexplainedAt == lastUpdateID {
+			continue
+		}
+		p.unexplainedUpdates++
+		if p.unexplainedUpdates < settleUpdates {
+			continue
+		}
+		cancelled := p.unexplained
+		p.unexplained, p.unexplainedUpdates = 0, 0
+		ahead := e.model(p.Ahead, p.Behind, cancelled)
+		p.Behind = clamp(p.Behind - (cancelled - (p.Ahead - ahead)))
+		p.Ahead = ahead
+	}
+}
+
+func (e *Estimator) levelQuantity(side types.DepthSide, price items_types.PriceType) items_types.QuantityType {
+	if side == types.DepthSideAsk {
+		return e.depths.GetAsks().Get(items_types.NewAsk(price)).GetDepthItem().GetQuantity()
+	}
+	return e.depths.GetBids().Get(items_types.NewBid(price)).GetDepthItem().GetQuantity()
+}
+
+func clamp(quantity items_types.QuantityType) items_types.QuantityType {
+	if quantity < 0 {
+		return 0
+	}
+	return quantity
+}
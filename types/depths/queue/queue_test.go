@@ -0,0 +1,97 @@
+package queue_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	queue_types "github.com/fr0ster/go-trading-utils/types/depths/queue"
+	orders_types "github.com/fr0ster/go-trading-utils/types/orders"
+	aggtrade_types "github.com/fr0ster/go-trading-utils/types/trades/aggtrade"
+)
+
+func TestQueueEstimator(t *testing.T) {
+	d := depth_types.New(3, "BTCUSDT", nil, nil)
+	d.ApplySnapshot(1,
+		[]*items_types.Bid{items_types.NewBid(100, 10), items_types.NewBid(99, 10)},
+		[]*items_types.Ask{items_types.NewAsk(101, 10)})
+	trades := aggtrade_types.New(make(chan struct{}), "BTCUSDT", nil, nil)
+	estimator := queue_types.New(d, trades, queue_types.ProportionalCancelModel)
+	trade := func(id int64, price, quantity string) {
+		trades.Lock()
+		defer trades.Unlock()
+		trades.Update(&aggtrade_types.AggTrade{AggTradeID: id, Price: price, Quantity: quantity, IsBuyerMaker: true})
+	}
+	update := func(id int64, quantity items_types.QuantityType) {
+		d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: id, LastUpdateID: id,
+			Bids: []items_types.PriceLevel{{Price: 100, Quantity: quantity}}})
+	}
+	// Оновлення без змін рівня 100
+	idle := func(ids ...int64) {
+		for _, id := range ids {
+			d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: id, LastUpdateID: id})
+		}
+	}
+
+	err := estimator.Add(&orders_types.Order{OrderID: 1, Price: "100", OrigQuantity: "2",
+		Side: types.SideType(types.SideTypeBuy), Time: time.Now().Add(-10 * time.Second).UnixMilli()})
+	assert.Nil(t, err)
+	assert.NotNil(t, estimator.Add(&orders_types.Order{OrderID: 2, Price: "100", OrigQuantity: "2"}))
+
+	// Наш ордер з'явився в стакані, за ним стало ще 3
+	update(2, 12)
+	update(3, 15)
+	// Угоди виконують голову черги
+	trade(1, "100", "4")
+	update(4, 11)
+	position, ok := estimator.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, items_types.QuantityType(10), position.InitialAhead)
+	assert.Equal(t, items_types.QuantityType(6), position.Ahead)
+	assert.Equal(t, items_types.QuantityType(3), position.Behind)
+	assert.Equal(t, items_types.QuantityType(4), position.Traded)
+
+	// Скасування розподіляються пропорційно 6:3, коли угоди за settle вікно їх не пояснили
+	update(5, 8)
+	position, _ = estimator.Get(1)
+	assert.Equal(t, items_types.QuantityType(6), position.Ahead)
+	idle(6, 7)
+	position, _ = estimator.Get(1)
+	assert.Equal(t, items_types.QuantityType(4), position.Ahead)
+	assert.Equal(t, items_types.QuantityType(2), position.Behind)
+	assert.InDelta(t, 0.5, position.QueueRatio(), 1e-9)
+
+	// Темп 4 за 10 секунд, за 10 секунд потрібно виконати 4 перед нами та 2 наших
+	probability, err := estimator.GetFillProbability(1, 10*time.Second)
+	assert.Nil(t, err)
+	assert.InDelta(t, math.Exp(-1.5), probability, 0.01)
+
+	// Зменшення рівня прийшло раніше за угоду - черга зменшується один раз
+	update(8, 7)
+	trade(2, "100", "1")
+	idle(9, 10)
+	position, _ = estimator.Get(1)
+	assert.Equal(t, items_types.QuantityType(3), position.Ahead)
+	assert.Equal(t, items_types.QuantityType(2), position.Behind)
+
+	// Угода за гіршою ціною - рівень виконано повністю
+	trade(3, "99", "1")
+	probability, _ = estimator.GetFillProbability(1, time.Second)
+	assert.Equal(t, 1.0, probability)
+
+	estimator.Remove(1)
+	_, ok = estimator.Get(1)
+	assert.False(t, ok)
+}
+
+func TestCancelModels(t *testing.T) {
+	assert.Equal(t, items_types.QuantityType(6), queue_types.PessimisticCancelModel(6, 3, 2))
+	assert.Equal(t, items_types.QuantityType(4), queue_types.PessimisticCancelModel(6, 3, 5))
+	assert.Equal(t, items_types.QuantityType(1), queue_types.OptimisticCancelModel(6, 3, 5))
+	assert.Equal(t, items_types.QuantityType(0), queue_types.OptimisticCancelModel(6, 3, 10))
+}
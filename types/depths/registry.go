@@ -0,0 +1,371 @@
+package depth
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/fr0ster/go-trading-utils/types"
+)
+
+const (
+	// Обмеження Binance на кількість стрімів в одному з'єднанні - 1024,
+	// але довжина URL комбінованого стріму обмежує нас раніше
+	DefaultRegistrySymbolsPerStream = 200
+	// Одночасно завантажується не більше DefaultRegistrySnapshotWorkers знімків,
+	// не частіше одного за DefaultRegistrySnapshotInterval, щоб масова ресинхронізація не вичерпала ліміт ваги REST
+	DefaultRegistrySnapshotWorkers  = 4
+	DefaultRegistrySnapshotInterval = 100 * time.Millisecond
+
+	registryReconnectDelay = time.Second
+)
+
+type (
+	// RegistryStreamFunction запускає один комбінований стрім стаканів для переданих символів
+	RegistryStreamFunction func(symbols []string) (doneC, stopC chan struct{}, err error)
+	// Registry - набір стаканів для багатьох символів, які оновлюються через комбіновані стріми.
+	// Кожне з'єднання обслуговує до symbolsPerStream символів, нові символи спочатку додаються
+	// до з'єднань з вільним місцем, решта отримує нові з'єднання.
+	// Знімки стаканів завантажуються обмеженою кількістю воркерів з мінімальним інтервалом.
+	Registry struct {
+		mutex            sync.RWMutex
+		degree           int
+		symbolsPerStream int
+		books            map[string]*Depths
+		connections      []*registryConnection
+		startStream      RegistryStreamFunction
+		initCreator      func(*Depths) types.InitFunction
+		isStarted        bool
+		snapshots        chan struct{}
+		snapshotMutex    sync.Mutex
+		snapshotInterval time.Duration
+		nextSnapshot     time.Time
+	}
+	registryConnection struct {
+		symbols map[string]bool
+		stopC   chan struct{}
+		stopped bool
+		// Змінюється при перезапуску стріму з новим списком символів
+		generation int
+	}
+)
+
+// NewRegistry створює реєстр стаканів.
+// streamCreator - адаптер біржі для комбінованого стріму, initCreator - завантаження знімку для кожного стакану.
+func NewRegistry(
+	degree int,
+	symbolsPerStream int,
+	streamCreator func(*Registry) RegistryStreamFunction,
+	initCreator func(*Depths) types.InitFunction) *Registry {
+	if symbolsPerStream <= 0 {
+		symbolsPerStream = DefaultRegistrySymbolsPerStream
+	}
+	this := &Registry{
+		degree:           degree,
+		symbolsPerStream: symbolsPerStream,
+		books:            make(map[string]*Depths),
+		snapshots:        make(chan struct{}, DefaultRegistrySnapshotWorkers),
+		snapshotInterval: DefaultRegistrySnapshotInterval,
+	}
+	if initCreator != nil {
+		this.initCreator = func(d *Depths) types.InitFunction {
+			return this.throttle(initCreator(d))
+		}
+	}
+	if streamCreator != nil {
+		this.startStream = streamCreator(this)
+	}
+	return this
+}
+
+// SetSnapshotLimits змінює обмеження завантаження знімків: не більше workers одночасно
+// та не частіше одного за interval. Викликається до Start.
+func (r *Registry) SetSnapshotLimits(workers int, interval time.Duration) {
+	if workers < 1 {
+		workers = 1
+	}
+	r.snapshotMutex.Lock()
+	defer r.snapshotMutex.Unlock()
+	r.snapshots = make(chan struct{}, workers)
+	r.snapshotInterval = interval
+}
+
+// Add додає символи до реєстру. Якщо реєстр запущено, символи додаються до з'єднань з вільним місцем,
+// стрім такого з'єднання перезапускається з новим списком, для решти відкриваються нові з'єднання.
+func (r *Registry) Add(symbols ...string) (err error) {
+	r.mutex.Lock()
+	added := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(symbol)
+		if _, ok := r.books[symbol]; ok {
+			continue
+		}
+		r.books[symbol] = New(r.degree, symbol, nil, r.initCreator)
+		added = append(added, symbol)
+	}
+	isStarted := r.isStarted
+	var extended []*registryConnection
+	if isStarted {
+		extended, added = r.fill(added)
+	}
+	r.mutex.Unlock()
+	for _, connection := range extended {
+		if err = r.restart(connection); err != nil {
+			return
+		}
+	}
+	if isStarted {
+		err = r.connect(added)
+	}
+	return
+}
+
+// Remove прибирає символи з реєстру.
+// З'єднання закривається, коли в ньому не лишається символів, події видалених символів до того ігноруються.
+func (r *Registry) Remove(symbols ...string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(symbol)
+		delete(r.books, symbol)
+		for _, connection := range r.connections {
+			delete(connection.symbols, symbol)
+		}
+	}
+	connections := r.connections[:0]
+	for _, connection := range r.connections {
+		if len(connection.symbols) == 0 {
+			connection.stop()
+			continue
+		}
+		connections = append(connections, connection)
+	}
+	r.connections = connections
+}
+
+// Get повертає стакан символу або nil
+func (r *Registry) Get(symbol string) *Depths {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.books[strings.ToUpper(symbol)]
+}
+
+// Symbols повертає відсортований список символів реєстру
+func (r *Registry) Symbols() (symbols []string) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	symbols = make([]string, 0, len(r.books))
+	for symbol := range r.books {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return
+}
+
+// Count повертає кількість символів в реєстрі
+func (r *Registry) Count() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.books)
+}
+
+// ConnectionsCount повертає кількість відкритих з'єднань
+func (r *Registry) ConnectionsCount() int {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.connections)
+}
+
+// Resync перезавантажує знімок одного стакану, не зачіпаючи інші
+func (r *Registry) Resync(symbol string) (err error) {
+	book := r.Get(symbol)
+	if book == nil {
+		return fmt.Errorf("symbol %v is not registered", symbol)
+	}
+	book.Lock()
+	defer book.Unlock()
+	return book.Resync()
+}
+
+// ProcessUpdate передає подію стріму в стакан символу, події невідомих символів ігноруються
+func (r *Registry) ProcessUpdate(symbol string, update *DepthUpdate) {
+	book := r.Get(symbol)
+	if book == nil {
+		return
+	}
+	book.Lock()
+	defer book.Unlock()
+	book.ProcessUpdate(update)
+}
+
+// Start відкриває комбіновані стріми для всіх символів реєстру
+func (r *Registry) Start() (err error) {
+	if r.startStream == nil || r.initCreator == nil {
+		return errors.New("initial functions for Streams and Data are not initialized")
+	}
+	r.mutex.Lock()
+	if r.isStarted {
+		r.mutex.Unlock()
+		return
+	}
+	r.isStarted = true
+	symbols := make([]string, 0, len(r.books))
+	for symbol := range r.books {
+		symbols = append(symbols, symbol)
+	}
+	r.mutex.Unlock()
+	sort.Strings(symbols)
+	return r.connect(symbols)
+}
+
+// Stop закриває всі з'єднання, стакани лишаються в реєстрі
+func (r *Registry) Stop() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, connection := range r.connections {
+		connection.stop()
+	}
+	r.connections = nil
+	r.isStarted = false
+}
+
+func (r *Registry) connect(symbols []string) (err error) {
+	for start := 0; start < len(symbols); start += r.symbolsPerStream {
+		end := start + r.symbolsPerStream
+		if end > len(symbols) {
+			end = len(symbols)
+		}
+		connection := &registryConnection{symbols: make(map[string]bool)}
+		for _, symbol := range symbols[start:end] {
+			connection.symbols[symbol] = true
+		}
+		r.mutex.Lock()
+		r.connections = append(r.connections, connection)
+		r.mutex.Unlock()
+		if err = r.serve(connection); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// fill додає символи до з'єднань з вільним місцем, повертає змінені з'єднання та символи, які не вмістились.
+// Виклик повинен виконуватись під блокуванням реєстру.
+func (r *Registry) fill(symbols []string) (extended []*registryConnection, rest []string) {
+	rest = symbols
+	for _, connection := range r.connections {
+		if len(rest) == 0 {
+			break
+		}
+		room := r.symbolsPerStream - len(connection.symbols)
+		if connection.stopped || room <= 0 {
+			continue
+		}
+		if room > len(rest) {
+			room = len(rest)
+		}
+		for _, symbol := range rest[:room] {
+			connection.symbols[symbol] = true
+		}
+		rest = rest[room:]
+		extended = append(extended, connection)
+	}
+	return
+}
+
+// restart закриває поточний стрім з'єднання та відкриває новий з актуальним списком символів.
+// Події, пропущені під час перезапуску, стакани виявлять самі та ресинхронізуються.
+func (r *Registry) restart(connection *registryConnection) error {
+	r.mutex.Lock()
+	connection.generation++
+	if connection.stopC != nil {
+		close(connection.stopC)
+		connection.stopC = nil
+	}
+	r.mutex.Unlock()
+	return r.serve(connection)
+}
+
+// serve запускає стрім з'єднання та перепідключає його, якщо стрім завершився не через Stop/Remove/restart.
+// Пропущені за час перепідключення події стакани виявлять самі та ресинхронізуються.
+func (r *Registry) serve(connection *registryConnection) (err error) {
+	r.mutex.RLock()
+	symbols := connection.getSymbols()
+	generation := connection.generation
+	r.mutex.RUnlock()
+	doneC, stopC, err := r.startStream(symbols)
+	if err != nil {
+		return
+	}
+	r.mutex.Lock()
+	if connection.stopped || connection.generation != generation {
+		r.mutex.Unlock()
+		close(stopC)
+		return
+	}
+	connection.stopC = stopC
+	r.mutex.Unlock()
+	go func() {
+		<-doneC
+		for {
+			r.mutex.RLock()
+			stopped := connection.stopped || connection.generation != generation
+			r.mutex.RUnlock()
+			if stopped {
+				return
+			}
+			err := r.serve(connection)
+			if err == nil {
+				return
+			}
+			logrus.Errorf("Depths registry reconnect error: %v", err)
+			time.Sleep(registryReconnectDelay)
+		}
+	}()
+	return
+}
+
+// throttle обмежує кількість одночасних завантажень знімків та їх частоту
+func (r *Registry) throttle(init types.InitFunction) types.InitFunction {
+	return func() error {
+		r.snapshotMutex.Lock()
+		snapshots := r.snapshots
+		r.snapshotMutex.Unlock()
+		snapshots <- struct{}{}
+		defer func() { <-snapshots }()
+		r.snapshotMutex.Lock()
+		now := time.Now()
+		if r.nextSnapshot.Before(now) {
+			r.nextSnapshot = now
+		}
+		wait := r.nextSnapshot.Sub(now)
+		r.nextSnapshot = r.nextSnapshot.Add(r.snapshotInterval)
+		r.snapshotMutex.Unlock()
+		time.Sleep(wait)
+		return init()
+	}
+}
+
+func (c *registryConnection) getSymbols() (symbols []string) {
+	symbols = make([]string, 0, len(c.symbols))
+	for symbol := range c.symbols {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return
+}
+
+func (c *registryConnection) stop() {
+	if c.stopped {
+		return
+	}
+	c.stopped = true
+	if c.stopC != nil {
+		close(c.stopC)
+	}
+}
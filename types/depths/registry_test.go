@@ -0,0 +1,124 @@
+package depth_test
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+type fakeStreams struct {
+	mutex   sync.Mutex
+	streams [][]string
+	stops   []chan struct{}
+}
+
+func (f *fakeStreams) creator(r *depth_types.Registry) depth_types.RegistryStreamFunction {
+	return func(symbols []string) (doneC, stopC chan struct{}, err error) {
+		f.mutex.Lock()
+		defer f.mutex.Unlock()
+		doneC = make(chan struct{})
+		stopC = make(chan struct{})
+		go func() {
+			<-stopC
+			close(doneC)
+		}()
+		f.streams = append(f.streams, symbols)
+		f.stops = append(f.stops, stopC)
+		return
+	}
+}
+
+func (f *fakeStreams) getStreams() [][]string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return append([][]string(nil), f.streams...)
+}
+
+func TestRegistry(t *testing.T) {
+	streams := &fakeStreams{}
+	done := make(chan struct{}, 1)
+	r := depth_types.NewRegistry(degree, 2, streams.creator, snapshotInitCreator(100, done))
+
+	assert.Nil(t, r.Add("btcusdt", "ETHUSDT", "BNBUSDT"))
+	assert.Equal(t, []string{"BNBUSDT", "BTCUSDT", "ETHUSDT"}, r.Symbols())
+	assert.Nil(t, r.Start())
+	assert.Equal(t, [][]string{{"BNBUSDT", "BTCUSDT"}, {"ETHUSDT"}}, streams.getStreams())
+	assert.Equal(t, 2, r.ConnectionsCount())
+
+	// Новий символ додається до з'єднання з вільним місцем, повне з'єднання не перезапускається
+	assert.Nil(t, r.Add("SOLUSDT", "BTCUSDT"))
+	assert.Equal(t, [][]string{{"BNBUSDT", "BTCUSDT"}, {"ETHUSDT"}, {"ETHUSDT", "SOLUSDT"}}, streams.getStreams())
+	assert.Equal(t, 2, r.ConnectionsCount())
+	// Місця немає - нове з'єднання
+	assert.Nil(t, r.Add("ADAUSDT"))
+	assert.Equal(t, []string{"ADAUSDT"}, streams.getStreams()[3])
+	assert.Equal(t, 3, r.ConnectionsCount())
+	r.Remove("ADAUSDT")
+
+	// Подія маршрутизується тільки в стакан свого символу
+	r.ProcessUpdate("BTCUSDT", &depth_types.DepthUpdate{FirstUpdateID: 95, LastUpdateID: 101,
+		Asks: []items_types.PriceLevel{{Price: 115, Quantity: 1}}})
+	waitSnapshot(t, done)
+	btc := r.Get("BTCUSDT")
+	btc.Lock()
+	assert.True(t, btc.IsSynced())
+	assert.NotNil(t, btc.GetAsks().Get(items_types.NewAsk(115)))
+	btc.Unlock()
+	assert.Equal(t, depth_types.SyncStateUnsynced, r.Get("ETHUSDT").GetSyncState())
+	r.ProcessUpdate("XRPUSDT", &depth_types.DepthUpdate{FirstUpdateID: 1, LastUpdateID: 2})
+
+	// Ресинхронізація одного стакану
+	assert.Nil(t, r.Resync("BTCUSDT"))
+	waitSnapshot(t, done)
+	assert.Equal(t, int64(1), r.Get("BTCUSDT").GetResyncCount())
+	assert.Equal(t, int64(0), r.Get("ETHUSDT").GetResyncCount())
+	assert.NotNil(t, r.Resync("XRPUSDT"))
+
+	// З'єднання закривається, коли в ньому не лишилось символів
+	r.Remove("ETHUSDT")
+	assert.Nil(t, r.Get("ETHUSDT"))
+	assert.Equal(t, 2, r.ConnectionsCount())
+	r.Remove("BNBUSDT", "BTCUSDT")
+	assert.Equal(t, 1, r.ConnectionsCount())
+	assert.Equal(t, 4, len(streams.getStreams()))
+
+	r.Stop()
+	assert.Equal(t, 0, r.ConnectionsCount())
+	assert.Equal(t, []string{"SOLUSDT"}, r.Symbols())
+}
+
+func TestRegistrySnapshotThrottle(t *testing.T) {
+	var mutex sync.Mutex
+	times := make([]time.Time, 0)
+	done := make(chan struct{}, 3)
+	r := depth_types.NewRegistry(degree, 0, nil, func(d *depth_types.Depths) types.InitFunction {
+		return func() error {
+			mutex.Lock()
+			times = append(times, time.Now())
+			mutex.Unlock()
+			done <- struct{}{}
+			return nil
+		}
+	})
+	interval := 50 * time.Millisecond
+	r.SetSnapshotLimits(1, interval)
+	assert.Nil(t, r.Add("BTCUSDT", "ETHUSDT", "BNBUSDT"))
+	for _, symbol := range r.Symbols() {
+		assert.Nil(t, r.Resync(symbol))
+	}
+	for i := 0; i < 3; i++ {
+		waitSnapshot(t, done)
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	assert.GreaterOrEqual(t, times[1].Sub(times[0]), interval-5*time.Millisecond)
+	assert.GreaterOrEqual(t, times[2].Sub(times[1]), interval-5*time.Millisecond)
+}
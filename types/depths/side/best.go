@@ -0,0 +1,94 @@
+package side
+
+import (
+	"fmt"
+	"math"
+
+	depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	"github.com/google/btree"
+)
+
+// GetBest повертає кращий рівень: найнижчий ask або найвищий bid
+func (d *Side[T]) GetBest() (best *items_types.DepthItem, err error) {
+	if d.direction == depths_types.UP {
+		return d.tree.GetMinPrice()
+	}
+	return d.tree.GetMaxPrice()
+}
+
+// IsBetter перевіряє, що price краща за than для цієї сторони
+func (d *Side[T]) IsBetter(price, than items_types.PriceType) bool {
+	if d.direction == depths_types.UP {
+		return items_types.PriceKey(price) < items_types.PriceKey(than)
+	}
+	return items_types.PriceKey(price) > items_types.PriceKey(than)
+}
+
+// IsWorse перевіряє, що price гірша за than для цієї сторони
+func (d *Side[T]) IsWorse(price, than items_types.PriceType) bool {
+	return d.IsBetter(than, price)
+}
+
+// BetterThan обходить рівні, кращі за price, від кращого рівня
+func (d *Side[T]) BetterThan(price items_types.PriceType, iterator func(item T) bool) {
+	pivot := items_types.New(price)
+	if d.direction == depths_types.UP {
+		d.tree.GetTree().AscendLessThan(pivot, wrap(iterator))
+	} else {
+		d.tree.GetTree().DescendGreaterThan(pivot, wrap(iterator))
+	}
+}
+
+// WorseThan обходить рівні, гірші за price, від найближчого до price
+func (d *Side[T]) WorseThan(price items_types.PriceType, iterator func(item T) bool) {
+	pivot := items_types.New(price)
+	key := items_types.PriceKey(price)
+	skipPivot := func(i btree.Item) bool {
+		if items_types.PriceKey(i.(*items_types.DepthItem).GetPrice()) == key {
+			return true
+		}
+		return iterator(T(i.(*items_types.DepthItem)))
+	}
+	if d.direction == depths_types.UP {
+		d.tree.GetTree().AscendGreaterOrEqual(pivot, skipPivot)
+	} else {
+		d.tree.GetTree().DescendLessOrEqual(pivot, skipPivot)
+	}
+}
+
+// GetDistanceTicks повертає відстань price від кращої ціни в кроках tickSize,
+// додатну для гірших цін та від'ємну для кращих
+func (d *Side[T]) GetDistanceTicks(price, tickSize items_types.PriceType) (ticks int64, err error) {
+	if tickSize <= 0 {
+		return 0, fmt.Errorf("invalid tick size %v", tickSize)
+	}
+	best, err := d.GetBest()
+	if err != nil {
+		return
+	}
+	return int64(math.Round(float64(d.distance(best.GetPrice(), price) / tickSize))), nil
+}
+
+// GetDistancePercent повертає відстань price від кращої ціни у відсотках від неї,
+// додатну для гірших цін та від'ємну для кращих
+func (d *Side[T]) GetDistancePercent(price items_types.PriceType) (percent items_types.PricePercentType, err error) {
+	best, err := d.GetBest()
+	if err != nil {
+		return
+	}
+	return items_types.PricePercentType(d.distance(best.GetPrice(), price) * 100 / best.GetPrice()), nil
+}
+
+func (d *Side[T]) distance(best, price items_types.PriceType) items_types.PriceType {
+	if d.direction == depths_types.UP {
+		return price - best
+	}
+	return best - price
+}
+
+func wrap[T Item](iterator func(item T) bool) btree.ItemIterator {
+	return func(i btree.Item) bool {
+		return iterator(T(i.(*items_types.DepthItem)))
+	}
+}
@@ -0,0 +1,32 @@
+package side
+
+import (
+	depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func New[T Item](degree int, symbol string) *Side[T] {
+	return &Side[T]{tree: depths_types.New(degree, symbol), direction: direction[T]()}
+}
+
+// Symbol implements depth_interface.Depths.
+func (d *Side[T]) Symbol() string {
+	return d.tree.Symbol()
+}
+
+func (d *Side[T]) Degree() int {
+	return d.tree.Degree()
+}
+
+// Direction повертає напрям від кращої ціни до гіршої, UP для Ask та DOWN для Bid
+func (d *Side[T]) Direction() depths_types.UpOrDown {
+	return d.direction
+}
+
+func direction[T Item]() depths_types.UpOrDown {
+	var item T
+	if _, ok := any(item).(*items_types.Ask); ok {
+		return depths_types.UP
+	}
+	return depths_types.DOWN
+}
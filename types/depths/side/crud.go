@@ -0,0 +1,85 @@
+package side
+
+import (
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func (d *Side[T]) Get(item T) T {
+	if val := d.tree.Get((*items_types.DepthItem)(item)); val != nil {
+		return T(val)
+	} else {
+		return nil
+	}
+}
+
+func (d *Side[T]) Set(item T) (err error) {
+	return d.tree.Set((*items_types.DepthItem)(item))
+}
+
+func (d *Side[T]) Delete(item T) {
+	d.tree.Delete((*items_types.DepthItem)(item))
+}
+
+func (d *Side[T]) Update(item T) bool {
+	return d.tree.Update((*items_types.DepthItem)(item))
+}
+
+// UpdateLevel встановлює кількість рівня без алокацій, нульова кількість видаляє рівень
+func (d *Side[T]) UpdateLevel(price items_types.PriceType, quantity items_types.QuantityType) {
+	d.tree.UpdateLevel(price, quantity)
+}
+
+// ApplyLevel - UpdateLevel з ключем дерева рівня level
+func (d *Side[T]) ApplyLevel(level items_types.PriceLevel) {
+	d.tree.ApplyLevel(level)
+}
+
+// Count implements depth_interface.Depths.
+func (d *Side[T]) Count() int {
+	return d.tree.Count()
+}
+
+func (d *Side[T]) GetSummaQuantity() items_types.QuantityType {
+	return d.tree.GetSummaQuantity()
+}
+
+func (d *Side[T]) GetSummaValue() items_types.ValueType {
+	return d.tree.GetSummaValue()
+}
+
+func (d *Side[T]) GetMiddleQuantity() items_types.QuantityType {
+	return d.tree.GetMiddleQuantity()
+}
+
+func (d *Side[T]) GetMiddleValue() items_types.ValueType {
+	return d.tree.GetMiddleValue()
+}
+
+func (d *Side[T]) GetMinPrice() (min *items_types.DepthItem, err error) {
+	return d.tree.GetMinPrice()
+}
+
+func (d *Side[T]) GetMaxPrice() (max *items_types.DepthItem, err error) {
+	return d.tree.GetMaxPrice()
+}
+
+func (d *Side[T]) GetDeltaPrice() (delta items_types.PriceType, err error) {
+	return d.tree.GetDeltaPrice()
+}
+
+// GetDeltaPricePercent повертає ширину сторони у відсотках від кращої ціни
+func (d *Side[T]) GetDeltaPricePercent() (delta items_types.PricePercentType, err error) {
+	return d.tree.GetDeltaPricePercent(d.direction)
+}
+
+func (d *Side[T]) GetStandardDeviation() float64 {
+	return d.tree.GetStandardDeviation()
+}
+
+func (d *Side[T]) NextPriceUp(percent items_types.PricePercentType) items_types.PriceType {
+	return d.tree.NextPriceUp(percent)
+}
+
+func (d *Side[T]) NextPriceDown(percent items_types.PricePercentType) items_types.PriceType {
+	return d.tree.NextPriceDown(percent)
+}
@@ -0,0 +1,14 @@
+package side
+
+import (
+	depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func (d *Side[T]) EstimateByQuantity(targetQuantity items_types.QuantityType) *depths_types.ExecutionEstimate {
+	return d.tree.EstimateByQuantity(targetQuantity, d.direction)
+}
+
+func (d *Side[T]) EstimateByValue(targetValue items_types.ValueType) *depths_types.ExecutionEstimate {
+	return d.tree.EstimateByValue(targetValue, d.direction)
+}
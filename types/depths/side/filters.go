@@ -0,0 +1,20 @@
+package side
+
+import (
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func (d *Side[T]) GetFiltered(f ...items_types.DepthFilter) (side *Side[T]) {
+	side = New[T](d.Degree(), d.Symbol())
+	side.SetTree(d.tree.GetFiltered(d.direction, f...).GetTree())
+	return
+}
+
+func (d *Side[T]) GetSummaByPriceRange(
+	first,
+	last items_types.PriceType,
+	f ...items_types.DepthFilter) (
+	value items_types.ValueType,
+	quantity items_types.QuantityType) {
+	return d.tree.GetSummaByPriceRange(first, last, f...)
+}
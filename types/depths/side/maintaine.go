@@ -0,0 +1,47 @@
+package side
+
+import (
+	depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	"github.com/google/btree"
+)
+
+// Get implements depth_interface.Depths.
+func (d *Side[T]) GetTree() *btree.BTree {
+	return d.tree.GetTree()
+}
+
+// Set implements depth_interface.Depths.
+func (d *Side[T]) SetTree(tree *btree.BTree) {
+	d.tree.SetTree(tree)
+}
+
+// Clear implements depth_interface.Depths.
+func (d *Side[T]) Clear() {
+	d.tree.Clear()
+}
+
+// RestrictUp implements depth_interface.Depths.
+func (d *Side[T]) RestrictUp(price items_types.PriceType) {
+	d.tree.RestrictUp(price)
+}
+
+// RestrictDown implements depth_interface.Depths.
+func (d *Side[T]) RestrictDown(price items_types.PriceType) {
+	d.tree.RestrictDown(price)
+}
+
+// KeepLevels лишає count кращих рівнів
+func (d *Side[T]) KeepLevels(count int) {
+	d.tree.KeepLevels(count, d.direction)
+}
+
+// AddChangeHandler додає обробник змін рівнів стакану
+func (d *Side[T]) AddChangeHandler(handler depths_types.ChangeHandler) {
+	d.tree.AddChangeHandler(handler)
+}
+
+// Clone повертає copy-on-write копію стакану
+func (d *Side[T]) Clone() *Side[T] {
+	return &Side[T]{tree: d.tree.Clone(), direction: d.direction}
+}
@@ -0,0 +1,24 @@
+package side
+
+import (
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func (d *Side[T]) GetSummaByPrice(targetPrice items_types.PriceType, firstMax ...bool) (
+	item *items_types.DepthItem,
+	value items_types.ValueType,
+	quantity items_types.QuantityType) {
+	return d.tree.GetSummaByPrice(targetPrice, d.direction, firstMax...)
+}
+
+// GetSummaByPricePercent рахується від найкращої ціни сторони, як інші читання
+func (d *Side[T]) GetSummaByPricePercent(targetPrice items_types.PricePercentType, firstMax ...bool) (
+	item *items_types.DepthItem,
+	value items_types.ValueType,
+	quantity items_types.QuantityType) {
+	return d.tree.GetSummaByPricePercent(targetPrice, d.direction, firstMax...)
+}
+
+func (d *Side[T]) GetMinMaxByPrice() (min, max *items_types.DepthItem, err error) {
+	return d.tree.GetMinMaxByPrice(d.direction)
+}
@@ -0,0 +1,24 @@
+package side
+
+import (
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+// Відбираємо по сумі
+func (d *Side[T]) GetSummaByQuantity(targetSumma items_types.QuantityType, firstMax ...bool) (
+	item *items_types.DepthItem,
+	value items_types.ValueType,
+	quantity items_types.QuantityType) {
+	return d.tree.GetSummaByQuantity(targetSumma, d.direction, firstMax...)
+}
+
+func (d *Side[T]) GetSummaByQuantityPercent(target items_types.PricePercentType, firstMax ...bool) (
+	item *items_types.DepthItem,
+	value items_types.ValueType,
+	quantity items_types.QuantityType) {
+	return d.tree.GetSummaByQuantityPercent(target, d.direction, firstMax...)
+}
+
+func (d *Side[T]) GetMinMaxByQuantity() (min, max *items_types.DepthItem) {
+	return d.tree.GetMinMaxByQuantity(d.direction)
+}
@@ -0,0 +1,24 @@
+package side
+
+import (
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+// Відбираємо по сумі
+func (d *Side[T]) GetSummaByValue(targetSumma items_types.ValueType, firstMax ...bool) (
+	item *items_types.DepthItem,
+	summaValue items_types.ValueType,
+	summaQuantity items_types.QuantityType) {
+	return d.tree.GetSummaByValue(targetSumma, d.direction, firstMax...)
+}
+
+func (d *Side[T]) GetSummaByValuePercent(target items_types.PricePercentType, firstMax ...bool) (
+	item *items_types.DepthItem,
+	summaValue items_types.ValueType,
+	summaQuantity items_types.QuantityType) {
+	return d.tree.GetSummaByValuePercent(target, d.direction, firstMax...)
+}
+
+func (d *Side[T]) GetMinMaxByValue() (min, max *items_types.DepthItem) {
+	return d.tree.GetMinMaxByValue(d.direction)
+}
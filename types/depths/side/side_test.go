@@ -0,0 +1,140 @@
+package side_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	side_types "github.com/fr0ster/go-trading-utils/types/depths/side"
+)
+
+const (
+	degree = 3
+)
+
+func newAsks() *side_types.Side[*items_types.Ask] {
+	asks := side_types.New[*items_types.Ask](degree, "BTCUSDT")
+	for _, price := range []items_types.PriceType{100, 200, 300, 400, 500} {
+		asks.Set(items_types.NewAsk(price, items_types.QuantityType(price/10)))
+	}
+	return asks
+}
+
+func newBids() *side_types.Side[*items_types.Bid] {
+	bids := side_types.New[*items_types.Bid](degree, "BTCUSDT")
+	for _, price := range []items_types.PriceType{100, 200, 300, 400, 500} {
+		bids.Set(items_types.NewBid(price, items_types.QuantityType(price/10)))
+	}
+	return bids
+}
+
+func TestDirection(t *testing.T) {
+	assert.Equal(t, depths_types.UP, newAsks().Direction())
+	assert.Equal(t, depths_types.DOWN, newBids().Direction())
+}
+
+func TestGetSummaByPricePercentFollowsDirection(t *testing.T) {
+	// Відсоток рахується від найкращої ціни сторони: для asks вгору від мінімальної, для bids вниз від максимальної
+	asks, bids := newAsks(), newBids()
+	item, value, quantity := asks.GetSummaByPricePercent(25)
+	assert.Equal(t, items_types.PriceType(200), item.GetPrice())
+	assert.Equal(t, items_types.QuantityType(30), quantity)
+	assert.Equal(t, items_types.ValueType(5000), value)
+
+	item, value, quantity = bids.GetSummaByPricePercent(25)
+	assert.Equal(t, items_types.PriceType(400), item.GetPrice())
+	assert.Equal(t, items_types.QuantityType(90), quantity)
+	assert.Equal(t, items_types.ValueType(41000), value)
+
+	for _, side := range []interface {
+		GetMinMaxByPrice() (*items_types.DepthItem, *items_types.DepthItem, error)
+	}{asks, bids} {
+		min, max, err := side.GetMinMaxByPrice()
+		assert.Nil(t, err)
+		assert.Equal(t, items_types.PriceType(100), min.GetPrice())
+		assert.Equal(t, items_types.PriceType(500), max.GetPrice())
+	}
+}
+
+func TestGetBestAndIsBetter(t *testing.T) {
+	asks, bids := newAsks(), newBids()
+	best, err := asks.GetBest()
+	assert.Nil(t, err)
+	assert.Equal(t, items_types.PriceType(100), best.GetPrice())
+	best, err = bids.GetBest()
+	assert.Nil(t, err)
+	assert.Equal(t, items_types.PriceType(500), best.GetPrice())
+
+	assert.True(t, asks.IsBetter(100, 200))
+	assert.False(t, asks.IsBetter(200, 100))
+	assert.True(t, asks.IsWorse(200, 100))
+	assert.True(t, bids.IsBetter(200, 100))
+	assert.False(t, bids.IsBetter(100, 200))
+	assert.True(t, bids.IsWorse(100, 200))
+	// Похибка float не робить однакові ціни кращими
+	assert.False(t, asks.IsBetter(0.1+0.2, 0.3))
+	assert.False(t, bids.IsBetter(0.1+0.2, 0.3))
+
+	_, err = side_types.New[*items_types.Ask](degree, "BTCUSDT").GetBest()
+	assert.Error(t, err)
+}
+
+func TestBetterAndWorseThan(t *testing.T) {
+	collect := func(walk func(items_types.PriceType, func(*items_types.Ask) bool), price items_types.PriceType) (prices []items_types.PriceType) {
+		walk(price, func(item *items_types.Ask) bool {
+			prices = append(prices, item.GetDepthItem().GetPrice())
+			return true
+		})
+		return
+	}
+	asks := newAsks()
+	assert.Equal(t, []items_types.PriceType{100, 200}, collect(asks.BetterThan, 300))
+	assert.Equal(t, []items_types.PriceType{400, 500}, collect(asks.WorseThan, 300))
+
+	bids := newBids()
+	var better, worse []items_types.PriceType
+	bids.BetterThan(300, func(item *items_types.Bid) bool {
+		better = append(better, item.GetDepthItem().GetPrice())
+		return true
+	})
+	bids.WorseThan(300, func(item *items_types.Bid) bool {
+		worse = append(worse, item.GetDepthItem().GetPrice())
+		return true
+	})
+	assert.Equal(t, []items_types.PriceType{500, 400}, better)
+	assert.Equal(t, []items_types.PriceType{200, 100}, worse)
+}
+
+func TestGetDistance(t *testing.T) {
+	asks, bids := newAsks(), newBids()
+
+	ticks, err := asks.GetDistanceTicks(101.5, 0.5)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), ticks)
+	ticks, err = asks.GetDistanceTicks(99, 0.5)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-2), ticks)
+	ticks, err = bids.GetDistanceTicks(499, 0.5)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), ticks)
+	ticks, err = bids.GetDistanceTicks(501, 0.5)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(-2), ticks)
+	_, err = asks.GetDistanceTicks(100, 0)
+	assert.Error(t, err)
+
+	percent, err := asks.GetDistancePercent(110)
+	assert.Nil(t, err)
+	assert.InDelta(t, 10, float64(percent), 1e-9)
+	percent, err = bids.GetDistancePercent(450)
+	assert.Nil(t, err)
+	assert.InDelta(t, 10, float64(percent), 1e-9)
+	percent, err = bids.GetDistancePercent(550)
+	assert.Nil(t, err)
+	assert.InDelta(t, -10, float64(percent), 1e-9)
+
+	_, err = side_types.New[*items_types.Bid](degree, "BTCUSDT").GetDistancePercent(100)
+	assert.Error(t, err)
+}
@@ -0,0 +1,19 @@
+package side
+
+import (
+	depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+type (
+	// Item - тип рівня сторони, *items_types.Ask або *items_types.Bid
+	Item interface {
+		*items_types.Ask | *items_types.Bid
+	}
+	// Side - сторона стакану, напрям якої задається типом рівня:
+	// для Ask кращою є нижча ціна, для Bid - вища
+	Side[T Item] struct {
+		tree      *depths_types.Depths
+		direction depths_types.UpOrDown
+	}
+)
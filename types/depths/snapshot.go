@@ -0,0 +1,78 @@
+package depth
+
+import (
+	"time"
+
+	asks_types "github.com/fr0ster/go-trading-utils/types/depths/asks"
+	bids_types "github.com/fr0ster/go-trading-utils/types/depths/bids"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+type (
+	// DepthSnapshot - незмінний знімок обох сторін стакану на момент LastUpdateID.
+	// Читання знімку не потребує блокування Depths і не заважає оновленню стакану.
+	DepthSnapshot struct {
+		symbol       string
+		asks         *asks_types.Asks
+		bids         *bids_types.Bids
+		lastUpdateID int64
+		time         time.Time
+	}
+)
+
+// Snapshot повертає знімок стакану, блокування Depths береться лише на час копіювання.
+// Не можна викликати під блокуванням Depths, для цього є SnapshotLocked.
+func (d *Depths) Snapshot() *DepthSnapshot {
+	d.Lock()
+	defer d.Unlock()
+	return d.SnapshotLocked()
+}
+
+// SnapshotLocked повертає знімок стакану, виклик повинен виконуватись під блокуванням Depths
+func (d *Depths) SnapshotLocked() *DepthSnapshot {
+	return &DepthSnapshot{
+		symbol:       d.symbol,
+		asks:         d.asks.Clone(),
+		bids:         d.bids.Clone(),
+		lastUpdateID: d.LastUpdateID,
+		time:         time.Now(),
+	}
+}
+
+func (s *DepthSnapshot) Symbol() string {
+	return s.symbol
+}
+
+// GetAsks повертає asks знімку, змінювати їх не можна
+func (s *DepthSnapshot) GetAsks() *asks_types.Asks {
+	return s.asks
+}
+
+// GetBids повертає bids знімку, змінювати їх не можна
+func (s *DepthSnapshot) GetBids() *bids_types.Bids {
+	return s.bids
+}
+
+// GetLastUpdateID повертає версію стакану, з якої зроблено знімок
+func (s *DepthSnapshot) GetLastUpdateID() int64 {
+	return s.lastUpdateID
+}
+
+// GetTime повертає час створення знімку
+func (s *DepthSnapshot) GetTime() time.Time {
+	return s.time
+}
+
+// GetMidPrice повертає середину спреду знімку
+func (s *DepthSnapshot) GetMidPrice() (mid items_types.PriceType, err error) {
+	ask, err := s.asks.GetMinPrice()
+	if err != nil {
+		return
+	}
+	bid, err := s.bids.GetMaxPrice()
+	if err != nil {
+		return
+	}
+	mid = (ask.GetPrice() + bid.GetPrice()) / 2
+	return
+}
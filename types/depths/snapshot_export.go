@@ -0,0 +1,274 @@
+package depth
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/btree"
+
+	asks_types "github.com/fr0ster/go-trading-utils/types/depths/asks"
+	bids_types "github.com/fr0ster/go-trading-utils/types/depths/bids"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+const (
+	snapshotMagic   = "DPTH"
+	snapshotVersion = 1
+	// Ступінь дерев знімку, прочитаного з файлу
+	snapshotDegree = 32
+	// Обмеження для пошкодженого файлу: довжина символу та кількість рівнів однієї сторони.
+	// REST /depth повертає не більше 5000 рівнів, локальний стакан може накопичити більше.
+	maxSnapshotSymbolLength = 64
+	maxSnapshotLevels       = 1 << 20
+)
+
+type (
+	// Рівень як у REST /depth: ["ціна", "кількість"], числа без лапок теж приймаються
+	levelRecord [2]json.Number
+	// snapshotRecord - формат REST /depth, доповнений символом та часом.
+	// Відповідь Binance без symbol та time теж читається, для ф'ючерсів час береться з E.
+	snapshotRecord struct {
+		Symbol       string        `json:"symbol,omitempty"`
+		LastUpdateID int64         `json:"lastUpdateId"`
+		Time         int64         `json:"time,omitempty"`
+		EventTime    int64         `json:"E,omitempty"`
+		Bids         []levelRecord `json:"bids"`
+		Asks         []levelRecord `json:"asks"`
+	}
+)
+
+func (l levelRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]string{string(l[0]), string(l[1])})
+}
+
+// WriteJSON записує знімок у форматі REST /depth з символом та часом в мілісекундах
+func (s *DepthSnapshot) WriteJSON(w io.Writer) error {
+	record := snapshotRecord{
+		Symbol:       s.symbol,
+		LastUpdateID: s.lastUpdateID,
+		Bids:         make([]levelRecord, 0, s.bids.Count()),
+		Asks:         make([]levelRecord, 0, s.asks.Count()),
+	}
+	if !s.time.IsZero() {
+		record.Time = s.time.UnixMilli()
+	}
+	s.eachLevel(
+		func(price items_types.PriceType, quantity items_types.QuantityType) {
+			record.Bids = append(record.Bids, levelRecord{formatNumber(float64(price)), formatNumber(float64(quantity))})
+		},
+		func(price items_types.PriceType, quantity items_types.QuantityType) {
+			record.Asks = append(record.Asks, levelRecord{formatNumber(float64(price)), formatNumber(float64(quantity))})
+		})
+	return json.NewEncoder(w).Encode(record)
+}
+
+// ReadSnapshotJSON читає знімок, записаний WriteJSON, або відповідь REST /depth споту чи ф'ючерсів
+func ReadSnapshotJSON(r io.Reader) (snapshot *DepthSnapshot, err error) {
+	var record snapshotRecord
+	if err = json.NewDecoder(r).Decode(&record); err != nil {
+		return
+	}
+	snapshot = newEmptySnapshot(record.Symbol, record.LastUpdateID)
+	switch {
+	case record.Time > 0:
+		snapshot.time = time.UnixMilli(record.Time)
+	case record.EventTime > 0:
+		snapshot.time = time.UnixMilli(record.EventTime)
+	}
+	for i, level := range record.Bids {
+		price, quantity, err := parseLevel(level)
+		if err != nil {
+			return nil, fmt.Errorf("bids[%v]: %w", i, err)
+		}
+		snapshot.bids.Set(items_types.NewBid(price, quantity))
+	}
+	for i, level := range record.Asks {
+		price, quantity, err := parseLevel(level)
+		if err != nil {
+			return nil, fmt.Errorf("asks[%v]: %w", i, err)
+		}
+		snapshot.asks.Set(items_types.NewAsk(price, quantity))
+	}
+	return
+}
+
+// WriteBinary записує знімок у компактному двійковому форматі:
+// "DPTH", версія, символ, LastUpdateID, час в наносекундах, потім bids та asks як кількість рівнів
+// та пари float64 ціна/кількість. Цілі числа записуються як varint, float64 - little-endian.
+func (s *DepthSnapshot) WriteBinary(w io.Writer) (err error) {
+	buffer := make([]byte, 0, 64+16*(s.bids.Count()+s.asks.Count()))
+	buffer = append(buffer, snapshotMagic...)
+	buffer = append(buffer, snapshotVersion)
+	buffer = binary.AppendUvarint(buffer, uint64(len(s.symbol)))
+	buffer = append(buffer, s.symbol...)
+	buffer = binary.AppendVarint(buffer, s.lastUpdateID)
+	var at int64
+	if !s.time.IsZero() {
+		at = s.time.UnixNano()
+	}
+	buffer = binary.AppendVarint(buffer, at)
+	appendLevel := func(price items_types.PriceType, quantity items_types.QuantityType) {
+		buffer = binary.LittleEndian.AppendUint64(buffer, math.Float64bits(float64(price)))
+		buffer = binary.LittleEndian.AppendUint64(buffer, math.Float64bits(float64(quantity)))
+	}
+	buffer = binary.AppendUvarint(buffer, uint64(s.bids.Count()))
+	s.eachLevel(appendLevel, nil)
+	buffer = binary.AppendUvarint(buffer, uint64(s.asks.Count()))
+	s.eachLevel(nil, appendLevel)
+	_, err = w.Write(buffer)
+	return
+}
+
+// ReadSnapshotBinary читає знімок, записаний WriteBinary
+func ReadSnapshotBinary(r io.Reader) (snapshot *DepthSnapshot, err error) {
+	reader := bufio.NewReader(r)
+	header := make([]byte, len(snapshotMagic)+1)
+	if _, err = io.ReadFull(reader, header); err != nil {
+		return
+	}
+	if string(header[:len(snapshotMagic)]) != snapshotMagic {
+		return nil, errors.New("invalid depth snapshot header")
+	}
+	if version := header[len(snapshotMagic)]; version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported depth snapshot version %v", version)
+	}
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return
+	}
+	if length > maxSnapshotSymbolLength {
+		return nil, fmt.Errorf("depth snapshot symbol length %v exceeds %v", length, maxSnapshotSymbolLength)
+	}
+	symbol := make([]byte, length)
+	if _, err = io.ReadFull(reader, symbol); err != nil {
+		return
+	}
+	lastUpdateID, err := binary.ReadVarint(reader)
+	if err != nil {
+		return
+	}
+	at, err := binary.ReadVarint(reader)
+	if err != nil {
+		return
+	}
+	snapshot = newEmptySnapshot(string(symbol), lastUpdateID)
+	if at != 0 {
+		snapshot.time = time.Unix(0, at)
+	}
+	readLevels := func(set func(price items_types.PriceType, quantity items_types.QuantityType)) (err error) {
+		count, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return
+		}
+		if count > maxSnapshotLevels {
+			return fmt.Errorf("level count %v exceeds %v", count, maxSnapshotLevels)
+		}
+		level := make([]byte, 16)
+		for i := uint64(0); i < count; i++ {
+			if _, err = io.ReadFull(reader, level); err != nil {
+				return
+			}
+			price := math.Float64frombits(binary.LittleEndian.Uint64(level[:8]))
+			quantity := math.Float64frombits(binary.LittleEndian.Uint64(level[8:]))
+			if err = checkLevel(price, quantity); err != nil {
+				return fmt.Errorf("level %v: %w", i, err)
+			}
+			set(items_types.PriceType(price), items_types.QuantityType(quantity))
+		}
+		return
+	}
+	if err = readLevels(func(price items_types.PriceType, quantity items_types.QuantityType) {
+		snapshot.bids.Set(items_types.NewBid(price, quantity))
+	}); err != nil {
+		return nil, fmt.Errorf("bids: %w", err)
+	}
+	if err = readLevels(func(price items_types.PriceType, quantity items_types.QuantityType) {
+		snapshot.asks.Set(items_types.NewAsk(price, quantity))
+	}); err != nil {
+		return nil, fmt.Errorf("asks: %w", err)
+	}
+	return
+}
+
+// LoadSnapshot замінює стакан знімком, наприклад прочитаним з файлу, та застосовує буфер подій як ApplySnapshot.
+// Знімок без символу підходить для будь-якого стакану.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) LoadSnapshot(snapshot *DepthSnapshot) error {
+	if snapshot.symbol != "" && snapshot.symbol != d.symbol {
+		return fmt.Errorf("snapshot symbol %v doesn't match %v", snapshot.symbol, d.symbol)
+	}
+	bids := make([]*items_types.Bid, 0, snapshot.bids.Count())
+	asks := make([]*items_types.Ask, 0, snapshot.asks.Count())
+	snapshot.eachLevel(
+		func(price items_types.PriceType, quantity items_types.QuantityType) {
+			bids = append(bids, items_types.NewBid(price, quantity))
+		},
+		func(price items_types.PriceType, quantity items_types.QuantityType) {
+			asks = append(asks, items_types.NewAsk(price, quantity))
+		})
+	d.ApplySnapshot(snapshot.lastUpdateID, bids, asks)
+	return nil
+}
+
+// Обходить bids від кращого до гіршого, потім asks від кращого до гіршого, nil пропускає сторону
+func (s *DepthSnapshot) eachLevel(bid, ask func(price items_types.PriceType, quantity items_types.QuantityType)) {
+	if bid != nil {
+		s.bids.GetTree().Descend(func(i btree.Item) bool {
+			bid(i.(*items_types.DepthItem).GetPrice(), i.(*items_types.DepthItem).GetQuantity())
+			return true
+		})
+	}
+	if ask != nil {
+		s.asks.GetTree().Ascend(func(i btree.Item) bool {
+			ask(i.(*items_types.DepthItem).GetPrice(), i.(*items_types.DepthItem).GetQuantity())
+			return true
+		})
+	}
+}
+
+func newEmptySnapshot(symbol string, lastUpdateID int64) *DepthSnapshot {
+	return &DepthSnapshot{
+		symbol:       symbol,
+		asks:         asks_types.New(snapshotDegree, symbol),
+		bids:         bids_types.New(snapshotDegree, symbol),
+		lastUpdateID: lastUpdateID,
+	}
+}
+
+func parseLevel(level levelRecord) (price items_types.PriceType, quantity items_types.QuantityType, err error) {
+	p, err := strconv.ParseFloat(string(level[0]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid price %q", level[0])
+	}
+	q, err := strconv.ParseFloat(string(level[1]), 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid quantity %q", level[1])
+	}
+	if err = checkLevel(p, q); err != nil {
+		return 0, 0, err
+	}
+	return items_types.PriceType(p), items_types.QuantityType(q), nil
+}
+
+// Рівень знімку повинен мати скінченні додатні ціну та кількість,
+// інакше він зіпсує суми стакану та ключ ціни
+func checkLevel(price, quantity float64) error {
+	if math.IsNaN(price) || math.IsInf(price, 0) || price <= 0 {
+		return fmt.Errorf("invalid price %v", price)
+	}
+	if math.IsNaN(quantity) || math.IsInf(quantity, 0) || quantity <= 0 {
+		return fmt.Errorf("invalid quantity %v", quantity)
+	}
+	return nil
+}
+
+func formatNumber(value float64) json.Number {
+	return json.Number(strconv.FormatFloat(value, 'f', -1, 64))
+}
@@ -0,0 +1,145 @@
+package depth_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func assertLoaded(t *testing.T, snapshot *depth_types.DepthSnapshot) {
+	d := depth_types.New(degree, "BTCUSDT", nil, nil)
+	d.Lock()
+	defer d.Unlock()
+	assert.NoError(t, d.LoadSnapshot(snapshot))
+	assert.True(t, d.IsSynced())
+	assert.Equal(t, int64(10), d.LastUpdateID)
+	assert.Equal(t, 5, d.GetAsks().Count())
+	assert.Equal(t, 5, d.GetBids().Count())
+	assert.Equal(t, items_types.QuantityType(90), d.GetAsks().GetSummaQuantity())
+	assert.Equal(t, items_types.QuantityType(90), d.GetBids().GetSummaQuantity())
+	assert.Equal(t, items_types.ValueType(72000), d.GetAsks().GetSummaValue())
+	assert.Empty(t, d.Validate())
+}
+
+func TestSnapshotJSON(t *testing.T) {
+	ds := depth_types.New(degree, "BTCUSDT", nil, nil)
+	initDepths(ds)
+	ds.LastUpdateID = 10
+	snapshot := ds.Snapshot()
+
+	var buffer bytes.Buffer
+	assert.NoError(t, snapshot.WriteJSON(&buffer))
+	assert.Contains(t, buffer.String(), `"bids":[["500","10"],["400","20"]`)
+	loaded, err := depth_types.ReadSnapshotJSON(&buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "BTCUSDT", loaded.Symbol())
+	assert.Equal(t, snapshot.GetTime().UnixMilli(), loaded.GetTime().UnixMilli())
+	assertLoaded(t, loaded)
+
+	other := depth_types.New(degree, "ETHUSDT", nil, nil)
+	other.Lock()
+	assert.Error(t, other.LoadSnapshot(loaded))
+	other.Unlock()
+}
+
+func TestSnapshotBinary(t *testing.T) {
+	ds := depth_types.New(degree, "BTCUSDT", nil, nil)
+	initDepths(ds)
+	ds.LastUpdateID = 10
+	snapshot := ds.Snapshot()
+
+	var buffer bytes.Buffer
+	assert.NoError(t, snapshot.WriteBinary(&buffer))
+	loaded, err := depth_types.ReadSnapshotBinary(bytes.NewReader(buffer.Bytes()))
+	assert.NoError(t, err)
+	assert.Equal(t, "BTCUSDT", loaded.Symbol())
+	assert.True(t, snapshot.GetTime().Equal(loaded.GetTime()))
+	assertLoaded(t, loaded)
+
+	_, err = depth_types.ReadSnapshotBinary(bytes.NewReader(buffer.Bytes()[:buffer.Len()-1]))
+	assert.Error(t, err)
+	_, err = depth_types.ReadSnapshotBinary(strings.NewReader("JSON{}"))
+	assert.Error(t, err)
+}
+
+func TestSnapshotBinaryCorrupt(t *testing.T) {
+	header := func(symbolLength uint64) []byte {
+		buffer := append([]byte("DPTH"), 1)
+		return binary.AppendUvarint(buffer, symbolLength)
+	}
+	// Довжина символу з пошкодженого файлу не повинна виділяти пам'ять
+	_, err := depth_types.ReadSnapshotBinary(bytes.NewReader(header(1 << 62)))
+	assert.ErrorContains(t, err, "symbol length")
+
+	buffer := append(header(7), "BTCUSDT"...)
+	buffer = binary.AppendVarint(buffer, 10)
+	buffer = binary.AppendVarint(buffer, 0)
+	buffer = binary.AppendUvarint(buffer, 1<<40)
+	_, err = depth_types.ReadSnapshotBinary(bytes.NewReader(buffer))
+	assert.EqualError(t, err, "bids: level count 1099511627776 exceeds 1048576")
+
+	// Рівні з NaN, Inf, нулем або від'ємним значенням відкидаються з номером рівня
+	level := func(buffer []byte, price, quantity float64) []byte {
+		buffer = binary.LittleEndian.AppendUint64(buffer, math.Float64bits(price))
+		return binary.LittleEndian.AppendUint64(buffer, math.Float64bits(quantity))
+	}
+	prefix := append(header(7), "BTCUSDT"...)
+	prefix = binary.AppendVarint(prefix, 10)
+	prefix = binary.AppendVarint(prefix, 0)
+	for _, test := range []struct {
+		price, quantity float64
+		message         string
+	}{
+		{math.NaN(), 1, "bids: level 1: invalid price NaN"},
+		{math.Inf(1), 1, "bids: level 1: invalid price +Inf"},
+		{0, 1, "bids: level 1: invalid price 0"},
+		{-100, 1, "bids: level 1: invalid price -100"},
+		{100, math.NaN(), "bids: level 1: invalid quantity NaN"},
+		{100, 0, "bids: level 1: invalid quantity 0"},
+		{100, -1, "bids: level 1: invalid quantity -1"},
+	} {
+		buffer := binary.AppendUvarint(append([]byte(nil), prefix...), 2)
+		buffer = level(buffer, 200, 1)
+		buffer = level(buffer, test.price, test.quantity)
+		buffer = binary.AppendUvarint(buffer, 0)
+		_, err = depth_types.ReadSnapshotBinary(bytes.NewReader(buffer))
+		assert.EqualError(t, err, test.message)
+	}
+	buffer = binary.AppendUvarint(append([]byte(nil), prefix...), 0)
+	buffer = binary.AppendUvarint(buffer, 1)
+	buffer = level(buffer, math.Inf(-1), 1)
+	_, err = depth_types.ReadSnapshotBinary(bytes.NewReader(buffer))
+	assert.EqualError(t, err, "asks: level 0: invalid price -Inf")
+}
+
+func TestSnapshotBinanceREST(t *testing.T) {
+	// Відповідь GET /fapi/v1/depth, збережена curl
+	response := `{"lastUpdateId":1027024,"E":1589436922972,"T":1589436922959,
+		"bids":[["4.00000000","431.00000000"]],"asks":[["4.00000200","12.00000000"],["4.10000000","1"]]}`
+	snapshot, err := depth_types.ReadSnapshotJSON(strings.NewReader(response))
+	assert.NoError(t, err)
+	assert.Equal(t, "", snapshot.Symbol())
+	assert.Equal(t, int64(1589436922972), snapshot.GetTime().UnixMilli())
+	assert.Equal(t, 2, snapshot.GetAsks().Count())
+
+	d := depth_types.New(degree, "BTCUSDT", nil, nil)
+	d.Lock()
+	defer d.Unlock()
+	assert.NoError(t, d.LoadSnapshot(snapshot))
+	assert.Equal(t, int64(1027024), d.LastUpdateID)
+	assert.Equal(t, items_types.QuantityType(431), d.GetBids().Get(items_types.NewBid(4)).GetDepthItem().GetQuantity())
+
+	_, err = depth_types.ReadSnapshotJSON(strings.NewReader(`{"lastUpdateId":1,"bids":[["x","1"]],"asks":[]}`))
+	assert.Error(t, err)
+	_, err = depth_types.ReadSnapshotJSON(strings.NewReader(`{"lastUpdateId":1,"bids":[],"asks":[["1","1"],["-1","1"]]}`))
+	assert.ErrorContains(t, err, "asks[1]")
+	_, err = depth_types.ReadSnapshotJSON(strings.NewReader(`{"lastUpdateId":1,"bids":[["1","0"]],"asks":[]}`))
+	assert.ErrorContains(t, err, "bids[0]: invalid quantity 0")
+}
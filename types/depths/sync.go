@@ -0,0 +1,236 @@
+package depth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+const (
+	// Стакан не синхронізовано, знімок ще не запитано
+	SyncStateUnsynced SyncState = iota
+	// Запитано знімок стакану, події накопичуються в буфері
+	SyncStateSyncing
+	// Стакан синхронізовано, події застосовуються одразу
+	SyncStateSynced
+	// Синхронізація неможлива, Init не задано
+	SyncStateFailed
+
+	defaultMaxSyncBuffer = 1000
+	// DefaultResyncRetryInterval - пауза після невдалого завантаження знімку,
+	// щоб помилка REST /depth не повторювалась з кожною подією стріму
+	DefaultResyncRetryInterval = 5 * time.Second
+)
+
+type (
+	SyncState int
+	// DepthUpdate - нормалізована подія diff-стріму стакану
+	// FirstUpdateID/LastUpdateID - U/u з події біржі,
+	// PrevLastUpdateID - pu для ф'ючерсів, для споту 0.
+	// Стакан не зберігає подію після ProcessUpdate, тому адаптер може перевикористовувати її між подіями.
+	DepthUpdate struct {
+		FirstUpdateID    int64
+		LastUpdateID     int64
+		PrevLastUpdateID int64
+		Bids             []items_types.PriceLevel
+		Asks             []items_types.PriceLevel
+	}
+)
+
+func (s SyncState) String() string {
+	switch s {
+	case SyncStateUnsynced:
+		return "UNSYNCED"
+	case SyncStateSyncing:
+		return "SYNCING"
+	case SyncStateSynced:
+		return "SYNCED"
+	case SyncStateFailed:
+		return "FAILED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+func (u *DepthUpdate) clone() *DepthUpdate {
+	return &DepthUpdate{
+		FirstUpdateID:    u.FirstUpdateID,
+		LastUpdateID:     u.LastUpdateID,
+		PrevLastUpdateID: u.PrevLastUpdateID,
+		Bids:             append([]items_types.PriceLevel(nil), u.Bids...),
+		Asks:             append([]items_types.PriceLevel(nil), u.Asks...),
+	}
+}
+
+func (u *DepthUpdate) isFutures() bool {
+	return u.PrevLastUpdateID != 0
+}
+
+// GetSyncState повертає поточний стан синхронізації стакану, можна викликати без блокування Depths
+func (d *Depths) GetSyncState() SyncState {
+	return SyncState(d.syncState.Load())
+}
+
+// IsSynced - чи можна довіряти стакану
+func (d *Depths) IsSynced() bool {
+	return d.GetSyncState() == SyncStateSynced
+}
+
+// GetResyncCount повертає кількість ресинхронізацій через пропуски в подіях
+func (d *Depths) GetResyncCount() int64 {
+	return d.resyncCount.Load()
+}
+
+func (d *Depths) setSyncState(state SyncState) {
+	d.syncState.Store(int32(state))
+}
+
+// SetMaxSyncBuffer задає максимальну кількість подій в буфері під час синхронізації
+func (d *Depths) SetMaxSyncBuffer(size int) {
+	if size > 0 {
+		d.maxSyncBuffer = size
+	}
+}
+
+// SetResyncRetryInterval задає паузу після невдалого завантаження знімку, 0 - без паузи
+func (d *Depths) SetResyncRetryInterval(interval time.Duration) {
+	if interval >= 0 {
+		d.resyncRetryInterval = interval
+	}
+}
+
+// ProcessUpdate застосовує подію diff-стріму згідно з правилами Binance
+// (U <= lastUpdateId+1 <= u для першої події, далі U == u+1 для споту або pu == u для ф'ючерсів).
+// Поки знімок стакану завантажується, події накопичуються в буфері.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) ProcessUpdate(update *DepthUpdate) {
+	switch d.GetSyncState() {
+	case SyncStateUnsynced, SyncStateFailed:
+		d.bufferUpdate(update)
+		d.Resync()
+	case SyncStateSyncing:
+		d.bufferUpdate(update)
+	case SyncStateSynced:
+		d.applySynced(update)
+	}
+}
+
+// ApplySnapshot замінює обидві сторони стакану знімком з REST
+// та застосовує накопичені в буфері події.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) ApplySnapshot(lastUpdateID int64, bids []*items_types.Bid, asks []*items_types.Ask) {
+	d.bids.Clear()
+	for _, bid := range bids {
+		d.bids.Update(bid)
+	}
+	d.asks.Clear()
+	for _, ask := range asks {
+		d.asks.Update(ask)
+	}
+	d.LastUpdateID = lastUpdateID
+	d.setSyncState(SyncStateSynced)
+	d.resyncFailedAt = time.Time{}
+	d.isFirstAfterSnapshot = true
+	d.Trim()
+	d.UpdateAnalytics()
+	d.PublishEvents(true)
+	d.afterUpdate()
+	buffer := d.syncBuffer
+	d.syncBuffer = nil
+	for _, update := range buffer {
+		if d.GetSyncState() != SyncStateSynced {
+			// Під час застосування буфера знайдено пропуск, решта подій вже в новому буфері
+			d.bufferUpdate(update)
+			continue
+		}
+		d.applySynced(update)
+	}
+}
+
+// Resync запускає завантаження знімку стакану у фоні, події до його завершення буферизуються.
+// Без Init стакан переходить в SyncStateFailed і повертається помилка.
+// Після невдалого завантаження нова спроба можлива не раніше паузи SetResyncRetryInterval.
+// Виклик повинен виконуватись під блокуванням Depths.
+func (d *Depths) Resync() error {
+	switch d.GetSyncState() {
+	case SyncStateSyncing:
+		// Знімок вже завантажується
+		return nil
+	case SyncStateSynced:
+		d.resyncCount.Add(1)
+	}
+	if wait := d.resyncRetryInterval - time.Since(d.resyncFailedAt); !d.resyncFailedAt.IsZero() && wait > 0 {
+		// Стакан з пропуском не можна вважати синхронізованим до нового знімку
+		d.setSyncState(SyncStateUnsynced)
+		return fmt.Errorf("depths %v: resync delayed for %v after error", d.symbol, wait.Round(time.Millisecond))
+	}
+	if d.Init == nil {
+		d.setSyncState(SyncStateFailed)
+		return errors.New("depths " + d.symbol + ": init function is not set")
+	}
+	d.setSyncState(SyncStateSyncing)
+	if d.syncStartedAt.IsZero() {
+		d.syncStartedAt = time.Now()
+	}
+	go func() {
+		if err := d.Init(); err != nil {
+			logrus.Errorf("Depths %v resync error: %v", d.symbol, err)
+			d.Lock()
+			defer d.Unlock()
+			d.resyncFailedAt = time.Now()
+			d.syncState.CompareAndSwap(int32(SyncStateSyncing), int32(SyncStateUnsynced))
+		}
+	}()
+	return nil
+}
+
+func (d *Depths) bufferUpdate(update *DepthUpdate) {
+	if len(d.syncBuffer) >= d.maxSyncBuffer {
+		// Найстаріша подія втрачається, якщо знімок її не покриє - буде нова ресинхронізація
+		d.syncBuffer = d.syncBuffer[1:]
+	}
+	d.syncBuffer = append(d.syncBuffer, update.clone())
+}
+
+func (d *Depths) applySynced(update *DepthUpdate) {
+	// Застарілі події, які вже враховані в знімку
+	if update.isFutures() && update.LastUpdateID < d.LastUpdateID ||
+		!update.isFutures() && update.LastUpdateID <= d.LastUpdateID {
+		return
+	}
+	if !d.isContinuous(update) {
+		d.syncBuffer = nil
+		d.Resync()
+		d.bufferUpdate(update)
+		return
+	}
+	for _, bid := range update.Bids {
+		d.bids.ApplyLevel(bid)
+	}
+	for _, ask := range update.Asks {
+		d.asks.ApplyLevel(ask)
+	}
+	d.LastUpdateID = update.LastUpdateID
+	d.isFirstAfterSnapshot = false
+	d.Trim()
+	d.UpdateAnalytics()
+	d.PublishEvents(false)
+	d.afterUpdate()
+}
+
+func (d *Depths) isContinuous(update *DepthUpdate) bool {
+	if d.isFirstAfterSnapshot {
+		if update.isFutures() {
+			return update.FirstUpdateID <= d.LastUpdateID && update.LastUpdateID >= d.LastUpdateID
+		}
+		return update.FirstUpdateID <= d.LastUpdateID+1 && update.LastUpdateID >= d.LastUpdateID+1
+	}
+	if update.isFutures() {
+		return update.PrevLastUpdateID == d.LastUpdateID
+	}
+	return update.FirstUpdateID == d.LastUpdateID+1
+}
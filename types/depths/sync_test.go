@@ -0,0 +1,377 @@
+package depth_test
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+func snapshotInitCreator(lastUpdateID int64, done chan struct{}) func(d *depth_types.Depths) types.InitFunction {
+	return func(d *depth_types.Depths) types.InitFunction {
+		return func() (err error) {
+			d.Lock()
+			defer d.Unlock()
+			d.ApplySnapshot(
+				lastUpdateID,
+				[]*items_types.Bid{items_types.NewBid(100, 10), items_types.NewBid(90, 10)},
+				[]*items_types.Ask{items_types.NewAsk(110, 10), items_types.NewAsk(120, 10)})
+			done <- struct{}{}
+			return
+		}
+	}
+}
+
+func waitSnapshot(t *testing.T, done chan struct{}) {
+	select {
+	case <-done:
+	case <-time.After(timeOut):
+		t.Fatal("snapshot wasn't loaded")
+	}
+}
+
+func TestSpotSyncStateMachine(t *testing.T) {
+	done := make(chan struct{}, 1)
+	d := depth_types.New(degree, "BTCUSDT", nil, snapshotInitCreator(100, done))
+	assert.Equal(t, depth_types.SyncStateUnsynced, d.GetSyncState())
+
+	d.Lock()
+	// Стара подія, буде відкинута після знімку
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 90, LastUpdateID: 95,
+		Bids: []items_types.PriceLevel{{Price: 80, Quantity: 10}}})
+	assert.Equal(t, depth_types.SyncStateSyncing, d.GetSyncState())
+	// Подія, що перекриває знімок
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 96, LastUpdateID: 105,
+		Bids: []items_types.PriceLevel{{Price: 100, Quantity: 0}}})
+	d.Unlock()
+	waitSnapshot(t, done)
+
+	d.Lock()
+	defer d.Unlock()
+	assert.True(t, d.IsSynced())
+	assert.Equal(t, int64(105), d.LastUpdateID)
+	assert.Nil(t, d.GetBids().Get(items_types.NewBid(80)))
+	assert.Nil(t, d.GetBids().Get(items_types.NewBid(100)))
+	assert.Equal(t, 1, d.GetBids().Count())
+
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 106, LastUpdateID: 107,
+		Asks: []items_types.PriceLevel{{Price: 130, Quantity: 5}}})
+	assert.Equal(t, int64(107), d.LastUpdateID)
+	assert.Equal(t, 3, d.GetAsks().Count())
+	assert.Equal(t, int64(0), d.GetResyncCount())
+
+	// Пропуск в подіях
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 110, LastUpdateID: 111})
+	assert.Equal(t, depth_types.SyncStateSyncing, d.GetSyncState())
+	assert.Equal(t, int64(1), d.GetResyncCount())
+}
+
+func TestFuturesSyncStateMachine(t *testing.T) {
+	done := make(chan struct{}, 1)
+	d := depth_types.New(degree, "BTCUSDT", nil, snapshotInitCreator(100, done))
+
+	d.Lock()
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 95, LastUpdateID: 100, PrevLastUpdateID: 94})
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 101, LastUpdateID: 103, PrevLastUpdateID: 100,
+		Asks: []items_types.PriceLevel{{Price: 110, Quantity: 20}}})
+	d.Unlock()
+	waitSnapshot(t, done)
+
+	d.Lock()
+	defer d.Unlock()
+	assert.True(t, d.IsSynced())
+	assert.Equal(t, int64(103), d.LastUpdateID)
+	assert.Equal(t, items_types.QuantityType(20), d.GetAsks().Get(items_types.NewAsk(110)).GetDepthItem().GetQuantity())
+
+	// pu не збігається з попереднім u
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 104, LastUpdateID: 106, PrevLastUpdateID: 102})
+	assert.False(t, d.IsSynced())
+	assert.Equal(t, int64(1), d.GetResyncCount())
+}
+
+func TestDepthEventsSubscription(t *testing.T) {
+	done := make(chan struct{}, 1)
+	d := depth_types.New(degree, "BTCUSDT", nil, snapshotInitCreator(100, done))
+	events := d.Subscribe(100, depth_types.DropNewest)
+	spreads := make([]items_types.PriceType, 0)
+	d.SubscribeCallback(func(event *depth_types.DepthEvent) {
+		spreads = append(spreads, event.Spread)
+	}, depth_types.DepthEventSpreadChanged)
+	small := d.Subscribe(1, depth_types.DropOldest)
+
+	d.Lock()
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 95, LastUpdateID: 100})
+	d.Unlock()
+	waitSnapshot(t, done)
+
+	d.Lock()
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 101, LastUpdateID: 102,
+		Bids: []items_types.PriceLevel{{Price: 100, Quantity: 5}},
+		Asks: []items_types.PriceLevel{{Price: 110, Quantity: 0}, {Price: 115, Quantity: 3}}})
+	d.Unlock()
+
+	received := make([]depth_types.DepthEventType, 0)
+	for len(events.C()) > 0 {
+		received = append(received, (<-events.C()).Type)
+	}
+	assert.Equal(t, []depth_types.DepthEventType{
+		depth_types.DepthEventResynced,
+		depth_types.DepthEventBestAskChanged,
+		depth_types.DepthEventBestBidChanged,
+		depth_types.DepthEventSpreadChanged,
+		depth_types.DepthEventLevelChanged,
+		depth_types.DepthEventLevelRemoved,
+		depth_types.DepthEventLevelAdded,
+		depth_types.DepthEventBestAskChanged,
+		depth_types.DepthEventBestBidChanged,
+		depth_types.DepthEventSpreadChanged,
+	}, received)
+	assert.Equal(t, []items_types.PriceType{10, 15}, spreads)
+
+	// В буфері на одну подію лишається остання
+	assert.Equal(t, int64(9), small.GetDropped())
+	last := <-small.C()
+	assert.Equal(t, depth_types.DepthEventSpreadChanged, last.Type)
+	assert.Equal(t, items_types.PriceType(10), last.OldSpread)
+	assert.Equal(t, int64(102), last.LastUpdateID)
+
+	d.Unsubscribe(small)
+	_, ok := <-small.C()
+	assert.False(t, ok)
+}
+
+func TestUnsubscribeDuringProcessUpdate(t *testing.T) {
+	done := make(chan struct{}, 1)
+	d := depth_types.New(degree, "BTCUSDT", nil, snapshotInitCreator(100, done))
+	d.Lock()
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 95, LastUpdateID: 100})
+	d.Unlock()
+	waitSnapshot(t, done)
+
+	subscriptions := make(chan *depth_types.Subscription, 100)
+	stop := make(chan struct{})
+	unsubscribed := make(chan struct{})
+	go func() {
+		defer close(unsubscribed)
+		for {
+			select {
+			case subscription := <-subscriptions:
+				// Без блокування Depths, як Detach теплової карти або Close черги
+				d.Unsubscribe(subscription)
+			case <-stop:
+				return
+			}
+		}
+	}()
+	for i := int64(0); i < 1000; i++ {
+		subscriptions <- d.Subscribe(1, depth_types.DropOldest)
+		d.Lock()
+		d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 101 + i, LastUpdateID: 101 + i,
+			Bids: []items_types.PriceLevel{{Price: 100, Quantity: items_types.QuantityType(i%10 + 1)}}})
+		d.Unlock()
+	}
+	close(stop)
+	<-unsubscribed
+	assert.True(t, d.IsSynced())
+}
+
+func TestPartialDepth(t *testing.T) {
+	d := depth_types.New(degree, "BTCUSDT", nil, nil)
+	events := d.Subscribe(100, depth_types.DropNewest,
+		depth_types.DepthEventLevelAdded, depth_types.DepthEventLevelChanged, depth_types.DepthEventLevelRemoved)
+	d.ApplyPartialDepth(10,
+		[]*items_types.Bid{items_types.NewBid(100, 1), items_types.NewBid(99, 2)},
+		[]*items_types.Ask{items_types.NewAsk(101, 1), items_types.NewAsk(102, 2)})
+	assert.True(t, d.IsSynced())
+	assert.Equal(t, 4, len(events.C()))
+	for len(events.C()) > 0 {
+		<-events.C()
+	}
+
+	// Новий знімок повністю замінює обидві сторони
+	d.ApplyPartialDepth(11,
+		[]*items_types.Bid{items_types.NewBid(100, 1), items_types.NewBid(98, 3)},
+		[]*items_types.Ask{items_types.NewAsk(101, 5), items_types.NewAsk(102, 2)})
+	assert.Equal(t, int64(11), d.LastUpdateID)
+	assert.Nil(t, d.GetBids().Get(items_types.NewBid(99)))
+	assert.Equal(t, items_types.QuantityType(4), d.GetBids().GetSummaQuantity())
+	assert.Equal(t, items_types.QuantityType(7), d.GetAsks().GetSummaQuantity())
+	received := make([]depth_types.DepthEventType, 0)
+	for len(events.C()) > 0 {
+		received = append(received, (<-events.C()).Type)
+	}
+	assert.ElementsMatch(t, []depth_types.DepthEventType{
+		depth_types.DepthEventLevelRemoved,
+		depth_types.DepthEventLevelAdded,
+		depth_types.DepthEventLevelChanged,
+	}, received)
+
+	// Повідомлення не по порядку ігнорується
+	d.ApplyPartialDepth(9, nil, nil)
+	assert.Equal(t, 2, d.GetAsks().Count())
+
+	assert.Nil(t, depth_types.DepthStreamLevel20.Validate())
+	assert.NotNil(t, depth_types.DepthStreamLevel(15).Validate())
+	assert.Nil(t, depth_types.DepthStreamRate250ms.Validate())
+	assert.NotNil(t, depth_types.DepthStreamRate(time.Second*2).Validate())
+}
+
+func TestStreamStartRequiresInitForDiffStream(t *testing.T) {
+	var starts int32
+	streamCreator := func(*depth_types.Depths) types.StreamFunction {
+		return func() (doneC, stopC chan struct{}, err error) {
+			atomic.AddInt32(&starts, 1)
+			return nil, make(chan struct{}, 1), nil
+		}
+	}
+	d := depth_types.New(degree, "BTCUSDT", streamCreator, nil)
+	assert.Error(t, d.StreamStart())
+	assert.Equal(t, int32(0), atomic.LoadInt32(&starts))
+
+	// Стакан часткового стріму стартує без Init
+	d.SetPartialDepth(true)
+	assert.True(t, d.IsPartialDepth())
+	assert.NoError(t, d.StreamStart())
+	assert.Equal(t, int32(1), atomic.LoadInt32(&starts))
+	assert.NoError(t, d.StreamStop())
+}
+
+// Подію буферизовано під час синхронізації, а адаптер вже перевикористав її буфери
+func TestReusedUpdateWhileSyncing(t *testing.T) {
+	done := make(chan struct{}, 1)
+	d := depth_types.New(degree, "BTCUSDT", nil, snapshotInitCreator(100, done))
+	update := &depth_types.DepthUpdate{FirstUpdateID: 100, LastUpdateID: 101,
+		Bids: []items_types.PriceLevel{{Price: 95, Quantity: 5}}}
+	d.Lock()
+	d.ProcessUpdate(update)
+	update.Bids[0] = items_types.PriceLevel{Price: 85, Quantity: 7}
+	d.Unlock()
+	waitSnapshot(t, done)
+	d.Lock()
+	defer d.Unlock()
+	assert.Equal(t, items_types.QuantityType(5), d.GetBids().Get(items_types.NewBid(95)).GetDepthItem().GetQuantity())
+	assert.Nil(t, d.GetBids().Get(items_types.NewBid(85)))
+}
+
+func benchmarkDepths() *depth_types.Depths {
+	d := depth_types.New(degree, "BTCUSDT", nil, nil)
+	bids := make([]*items_types.Bid, 0, 1000)
+	asks := make([]*items_types.Ask, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		bids = append(bids, items_types.NewBid(items_types.PriceType(10000-i), 1))
+		asks = append(asks, items_types.NewAsk(items_types.PriceType(10001+i), 1))
+	}
+	d.Lock()
+	d.ApplySnapshot(1, bids, asks)
+	d.Unlock()
+	return d
+}
+
+// Пропускна здатність на одну diff-подію з 20 рівнями та алокації на рівень (allocs/level)
+func BenchmarkProcessUpdate(b *testing.B) {
+	const levels = 10
+	d := benchmarkDepths()
+	update := &depth_types.DepthUpdate{
+		Bids: make([]items_types.PriceLevel, levels),
+		Asks: make([]items_types.PriceLevel, levels),
+	}
+	d.Lock()
+	defer d.Unlock()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 0; j < levels; j++ {
+			quantity := items_types.QuantityType((i + j) % 5) // кожен п'ятий рівень видаляється
+			update.Bids[j] = items_types.PriceLevel{Price: items_types.PriceType(10000 - (i*levels+j)%1000), Quantity: quantity}
+			update.Asks[j] = items_types.PriceLevel{Price: items_types.PriceType(10001 + (i*levels+j)%1000), Quantity: quantity}
+		}
+		update.FirstUpdateID = int64(i) + 2
+		update.LastUpdateID = int64(i) + 2
+		d.ProcessUpdate(update)
+	}
+	b.StopTimer()
+	runtime.ReadMemStats(&after)
+	b.ReportMetric(float64(after.Mallocs-before.Mallocs)/float64(b.N*levels*2), "allocs/level")
+}
+
+func TestResyncWithoutInit(t *testing.T) {
+	d := depth_types.New(degree, "BTCUSDT", nil, nil)
+	d.Lock()
+	defer d.Unlock()
+	assert.NotNil(t, d.Resync())
+	assert.Equal(t, depth_types.SyncStateFailed, d.GetSyncState())
+	assert.Equal(t, "FAILED", d.GetSyncState().String())
+	// Події буферизуються, стан не змінюється на SYNCING
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 1, LastUpdateID: 2})
+	assert.Equal(t, depth_types.SyncStateFailed, d.GetSyncState())
+}
+
+func TestSyncStateReadsDuringResync(t *testing.T) {
+	done := make(chan struct{}, 1)
+	d := depth_types.New(degree, "BTCUSDT", nil, snapshotInitCreator(100, done))
+	stop := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				// Читання без блокування Depths
+				d.GetSyncState()
+				d.IsSynced()
+				d.GetResyncCount()
+			}
+		}
+	}()
+	d.Lock()
+	assert.Nil(t, d.Resync())
+	d.Unlock()
+	waitSnapshot(t, done)
+	close(stop)
+	assert.True(t, d.IsSynced())
+}
+
+func TestResyncRetryInterval(t *testing.T) {
+	var calls atomic.Int32
+	failed := make(chan struct{}, 10)
+	d := depth_types.New(degree, "BTCUSDT", nil, func(d *depth_types.Depths) types.InitFunction {
+		return func() error {
+			calls.Add(1)
+			failed <- struct{}{}
+			return errors.New("-1003 too many requests")
+		}
+	})
+	d.SetResyncRetryInterval(100 * time.Millisecond)
+	event := func(id int64) {
+		d.Lock()
+		defer d.Unlock()
+		d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: id, LastUpdateID: id})
+	}
+	event(1)
+	waitSnapshot(t, failed)
+	assert.Eventually(t, func() bool { return d.GetSyncState() == depth_types.SyncStateUnsynced }, timeOut, time.Millisecond)
+
+	// Події під час паузи не запитують знімок повторно
+	for id := int64(2); id < 20; id++ {
+		event(id)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, depth_types.SyncStateUnsynced, d.GetSyncState())
+	d.Lock()
+	assert.ErrorContains(t, d.Resync(), "delayed")
+	d.Unlock()
+
+	time.Sleep(110 * time.Millisecond)
+	event(20)
+	waitSnapshot(t, failed)
+	assert.Equal(t, int32(2), calls.Load())
+}
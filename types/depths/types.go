@@ -2,6 +2,7 @@ package depth
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/fr0ster/go-trading-utils/types"
@@ -41,12 +42,33 @@ type (
 		mutex        *sync.Mutex
 		LastUpdateID int64
 
+		// Стан та лічильник читаються без блокування Depths
+		syncState            atomic.Int32
+		syncBuffer           []*DepthUpdate
+		maxSyncBuffer        int
+		isFirstAfterSnapshot bool
+		resyncCount          atomic.Int64
+		// Невдале завантаження знімку, до кінця паузи нова ресинхронізація не запускається
+		resyncFailedAt      time.Time
+		resyncRetryInterval time.Duration
+
+		groupedViews map[groupedKey]*GroupedView
+		analytics    *analytics
+		publisher    publisher
+		bounds       bounds
+		integrity    *integrity
+		updatedAt    time.Time
+		// Перша спроба синхронізації, від неї рахується застарілість стакану без жодного оновлення
+		syncStartedAt time.Time
+
 		stop             chan struct{}
 		resetEvent       chan error
 		isStartedStream  bool
 		timeOut          time.Duration
 		startDepthStream types.StreamFunction
 		Init             types.InitFunction
+		// Стрім часткового стакану надсилає повні топ-N знімки, Init для нього не потрібен
+		partialDepth bool
 	}
 )
 
@@ -74,12 +96,12 @@ func (d *Depths) GetBids() *bids_types.Bids {
 }
 
 func (a *Depths) UpdateAsk(item *items_types.Ask) bool {
-	a.bids.Delete(items_types.NewBid(item.GetDepthItem().GetPrice(), item.GetDepthItem().GetQuantity()))
+	a.bids.UpdateLevel(item.GetDepthItem().GetPrice(), 0)
 	return a.asks.Update(item)
 }
 
 func (a *Depths) UpdateBid(item *items_types.Bid) bool {
-	a.asks.Delete(items_types.NewAsk(item.GetDepthItem().GetPrice(), item.GetDepthItem().GetQuantity()))
+	a.asks.UpdateLevel(item.GetDepthItem().GetPrice(), 0)
 	return a.bids.Update(item)
 }
 
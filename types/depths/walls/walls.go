@@ -0,0 +1,377 @@
+package walls
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/btree"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+const (
+	// Стіна - рівень з кількістю не меншою за multiplier * середню кількість на рівні сторони
+	ThresholdMiddle ThresholdType = iota
+	// Стіна - рівень з кількістю не меншою за середню + multiplier * стандартне відхилення сторони
+	ThresholdDeviation
+)
+
+const (
+	WallAppeared WallEventType = "WALL_APPEARED"
+	WallChanged  WallEventType = "WALL_CHANGED"
+	WallConsumed WallEventType = "WALL_CONSUMED"
+	// Стіну знято без достатніх угод по її ціні - ймовірний спуфінг, навіть якщо вона була найкращим рівнем
+	WallPulled WallEventType = "WALL_PULLED"
+	// Рівень залишився в стакані, але перестав бути стіною через незначне зменшення або зростання порогу
+	WallFaded WallEventType = "WALL_FADED"
+
+	// Частка зменшення стіни, яка повинна бути покрита угодами, щоб вважати стіну виконаною.
+	// Без угод стіна вважається знятою, навіть якщо ціна до неї дійшла.
+	consumedTradeRatio = 0.5
+	// Частка MaxQuantity, на яку повинна зменшитись стіна, що залишилась в стакані, щоб вважати її знятою
+	pulledDecreaseRatio = 0.5
+
+	// Скільки цін знятих стін зберігається для кожної сторони
+	DefaultMaxPulled = 1000
+)
+
+type (
+	ThresholdType int
+	WallEventType string
+	// Wall - велика заявка в стакані та її історія
+	Wall struct {
+		Side            types.DepthSide
+		Price           items_types.PriceType
+		Quantity        items_types.QuantityType
+		InitialQuantity items_types.QuantityType
+		MaxQuantity     items_types.QuantityType
+		// Обсяг угод по ціні стіни за час її існування
+		TradedQuantity items_types.QuantityType
+		// Скільки разів стіну по цій ціні вже знімали раніше
+		PulledBefore int
+		AppearedAt   time.Time
+		UpdatedAt    time.Time
+	}
+	WallEvent struct {
+		Type         WallEventType
+		Wall         Wall
+		LastUpdateID int64
+		Time         time.Time
+	}
+	WallEventHandler func(event *WallEvent)
+	// Tracker відстежує стіни в стакані по подіях depth.Depths
+	Tracker struct {
+		mutex        sync.Mutex
+		depths       *depth_types.Depths
+		subscription *depth_types.Subscription
+		threshold    ThresholdType
+		multiplier   float64
+		walls        map[types.DepthSide]map[int64]*Wall
+		pulled       map[types.DepthSide]map[int64]*pulledPrice
+		maxPulled    int
+		handlers     []WallEventHandler
+		cache        map[types.DepthSide]thresholdCache
+	}
+	pulledPrice struct {
+		count    int
+		pulledAt time.Time
+	}
+	thresholdCache struct {
+		lastUpdateID int64
+		value        items_types.QuantityType
+	}
+)
+
+// New створює трекер та підписується на події стакану.
+// Стакан сканується одразу, тому виклик повинен виконуватись під блокуванням Depths.
+func New(depths *depth_types.Depths, threshold ThresholdType, multiplier float64) *Tracker {
+	this := &Tracker{
+		depths:     depths,
+		threshold:  threshold,
+		multiplier: multiplier,
+		walls:      newSides[*Wall](),
+		pulled:     newSides[*pulledPrice](),
+		maxPulled:  DefaultMaxPulled,
+		cache:      make(map[types.DepthSide]thresholdCache),
+	}
+	this.Scan()
+	this.subscription = depths.SubscribeCallback(this.onDepthEvent,
+		depth_types.DepthEventLevelAdded,
+		depth_types.DepthEventLevelChanged,
+		depth_types.DepthEventLevelRemoved,
+		depth_types.DepthEventResynced,
+		depth_types.DepthEventUpdated)
+	return this
+}
+
+// Close відписує трекер від подій стакану
+func (t *Tracker) Close() {
+	t.depths.Unsubscribe(t.subscription)
+}
+
+// AddHandler додає обробник подій стін.
+// Обробники викликаються під блокуванням Depths, тому повинні бути швидкими.
+func (t *Tracker) AddHandler(handler WallEventHandler) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.handlers = append(t.handlers, handler)
+}
+
+// SetMaxPulled змінює, скільки цін знятих стін зберігається для кожної сторони, 0 - без обмеження.
+// Понад обмеження забуваються ціни, стіни на яких знімали найдавніше.
+func (t *Tracker) SetMaxPulled(limit int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.maxPulled = limit
+}
+
+// AddTrade враховує угоду по ціні price, щоб відрізнити виконану стіну від знятої.
+// Може викликатись з обробника стріму угод без блокування Depths.
+func (t *Tracker) AddTrade(price items_types.PriceType, quantity items_types.QuantityType) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	key := items_types.PriceKey(price)
+	for _, walls := range t.walls {
+		if wall, ok := walls[key]; ok {
+			wall.TradedQuantity += quantity
+		}
+	}
+}
+
+// GetWalls повертає копії поточних стін сторони, від найближчої до спреду
+func (t *Tracker) GetWalls(side types.DepthSide) (walls []Wall) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	walls = make([]Wall, 0, len(t.walls[side]))
+	for _, wall := range t.walls[side] {
+		walls = append(walls, *wall)
+	}
+	sort.Slice(walls, func(i, j int) bool {
+		if side == types.DepthSideAsk {
+			return walls[i].Price < walls[j].Price
+		}
+		return walls[i].Price > walls[j].Price
+	})
+	return
+}
+
+// GetPulledCount повертає, скільки разів знімали стіну по ціні price
+func (t *Tracker) GetPulledCount(side types.DepthSide, price items_types.PriceType) int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.pulledCount(side, items_types.PriceKey(price))
+}
+
+// GetThreshold повертає поточний поріг кількості для стіни на стороні side
+func (t *Tracker) GetThreshold(side types.DepthSide) items_types.QuantityType {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.getThreshold(side)
+}
+
+// Scan перевіряє всі рівні стакану, виклик повинен виконуватись під блокуванням Depths
+func (t *Tracker) Scan() {
+	t.mutex.Lock()
+	events := make([]*WallEvent, 0)
+	for _, side := range []types.DepthSide{types.DepthSideAsk, types.DepthSideBid} {
+		for _, item := range t.levels(side) {
+			if event := t.evaluate(side, item.GetPrice(), item.GetQuantity()); event != nil {
+				events = append(events, event)
+			}
+		}
+	}
+	handlers := t.handlers
+	t.mutex.Unlock()
+	notify(handlers, events)
+}
+
+func (t *Tracker) onDepthEvent(event *depth_types.DepthEvent) {
+	if event.Type == depth_types.DepthEventResynced {
+		// Стакан замінено знімком, причину зникнення старих стін встановити неможливо
+		t.mutex.Lock()
+		t.walls = newSides[*Wall]()
+		t.cache = make(map[types.DepthSide]thresholdCache)
+		t.mutex.Unlock()
+		t.Scan()
+		return
+	}
+	t.mutex.Lock()
+	var events []*WallEvent
+	if event.Type == depth_types.DepthEventUpdated {
+		// Поріг залежить від усіх рівнів сторони, тому стіни без власних змін перевіряються після кожного оновлення
+		events = t.recheck()
+	} else if wallEvent := t.evaluate(event.Side, event.Price, event.Quantity); wallEvent != nil {
+		events = append(events, wallEvent)
+	}
+	handlers := t.handlers
+	t.mutex.Unlock()
+	notify(handlers, events)
+}
+
+func (t *Tracker) recheck() (events []*WallEvent) {
+	for _, side := range []types.DepthSide{types.DepthSideAsk, types.DepthSideBid} {
+		for _, wall := range t.walls[side] {
+			if event := t.evaluate(side, wall.Price, t.quantity(side, wall.Price)); event != nil {
+				events = append(events, event)
+			}
+		}
+	}
+	return
+}
+
+func (t *Tracker) quantity(side types.DepthSide, price items_types.PriceType) items_types.QuantityType {
+	if side == types.DepthSideAsk {
+		return t.depths.GetAsks().Get(items_types.NewAsk(price)).GetDepthItem().GetQuantity()
+	}
+	return t.depths.GetBids().Get(items_types.NewBid(price)).GetDepthItem().GetQuantity()
+}
+
+func (t *Tracker) evaluate(side types.DepthSide, price items_types.PriceType, quantity items_types.QuantityType) (event *WallEvent) {
+	now := time.Now()
+	key := items_types.PriceKey(price)
+	wall, exists := t.walls[side][key]
+	isWall := quantity > 0 && quantity >= t.getThreshold(side)
+	switch {
+	case !exists && isWall:
+		wall = &Wall{
+			Side:            side,
+			Price:           price,
+			Quantity:        quantity,
+			InitialQuantity: quantity,
+			MaxQuantity:     quantity,
+			PulledBefore:    t.pulledCount(side, key),
+			AppearedAt:      now,
+			UpdatedAt:       now,
+		}
+		t.walls[side][key] = wall
+		event = t.newEvent(WallAppeared, wall, now)
+	case exists && isWall:
+		if wall.Quantity == quantity {
+			return
+		}
+		wall.Quantity = quantity
+		wall.MaxQuantity = items_types.QuantityType(math.Max(float64(wall.MaxQuantity), float64(quantity)))
+		wall.UpdatedAt = now
+		event = t.newEvent(WallChanged, wall, now)
+	case exists && !isWall:
+		decrease := wall.Quantity - quantity
+		wall.Quantity = quantity
+		wall.UpdatedAt = now
+		delete(t.walls[side], key)
+		switch {
+		case quantity > 0 && wall.MaxQuantity-quantity < wall.MaxQuantity*pulledDecreaseRatio:
+			// Поріг виріс або рівень трохи зменшився, це не спуфінг
+			event = t.newEvent(WallFaded, wall, now)
+		case wall.TradedQuantity > 0 && wall.TradedQuantity >= decrease*consumedTradeRatio:
+			event = t.newEvent(WallConsumed, wall, now)
+		default:
+			t.addPulled(side, key, now)
+			event = t.newEvent(WallPulled, wall, now)
+		}
+	}
+	return
+}
+
+func (t *Tracker) pulledCount(side types.DepthSide, key int64) int {
+	if pulled, ok := t.pulled[side][key]; ok {
+		return pulled.count
+	}
+	return 0
+}
+
+func (t *Tracker) addPulled(side types.DepthSide, key int64, now time.Time) {
+	pulled, ok := t.pulled[side][key]
+	if !ok {
+		if t.maxPulled > 0 && len(t.pulled[side]) >= t.maxPulled {
+			// Забуваємо ціну, стіну на якій знімали найдавніше
+			var (
+				oldest   int64
+				oldestAt time.Time
+			)
+			for key, pulled := range t.pulled[side] {
+				if oldestAt.IsZero() || pulled.pulledAt.Before(oldestAt) {
+					oldest, oldestAt = key, pulled.pulledAt
+				}
+			}
+			delete(t.pulled[side], oldest)
+		}
+		pulled = &pulledPrice{}
+		t.pulled[side][key] = pulled
+	}
+	pulled.count++
+	pulled.pulledAt = now
+}
+
+// Поріг рахується один раз на кожну версію стакану, бо стандартне відхилення потребує проходу по стороні
+func (t *Tracker) getThreshold(side types.DepthSide) items_types.QuantityType {
+	if cache, ok := t.cache[side]; ok && cache.lastUpdateID == t.depths.LastUpdateID && t.depths.LastUpdateID != 0 {
+		return cache.value
+	}
+	var (
+		count     int
+		middle    items_types.QuantityType
+		deviation float64
+	)
+	if side == types.DepthSideAsk {
+		count = t.depths.GetAsks().Count()
+		if count > 0 {
+			middle = t.depths.GetAsks().GetMiddleQuantity()
+			if t.threshold == ThresholdDeviation {
+				deviation = t.depths.GetAsks().GetStandardDeviation()
+			}
+		}
+	} else {
+		count = t.depths.GetBids().Count()
+		if count > 0 {
+			middle = t.depths.GetBids().GetMiddleQuantity()
+			if t.threshold == ThresholdDeviation {
+				deviation = t.depths.GetBids().GetStandardDeviation()
+			}
+		}
+	}
+	value := items_types.QuantityType(math.Inf(1))
+	if count > 0 {
+		if t.threshold == ThresholdDeviation {
+			value = middle + items_types.QuantityType(t.multiplier*deviation)
+		} else {
+			value = middle * items_types.QuantityType(t.multiplier)
+		}
+	}
+	t.cache[side] = thresholdCache{lastUpdateID: t.depths.LastUpdateID, value: value}
+	return value
+}
+
+func (t *Tracker) levels(side types.DepthSide) (items []*items_types.DepthItem) {
+	tree := t.depths.GetBids().GetTree()
+	if side == types.DepthSideAsk {
+		tree = t.depths.GetAsks().GetTree()
+	}
+	tree.Ascend(func(i btree.Item) bool {
+		items = append(items, i.(*items_types.DepthItem))
+		return true
+	})
+	return
+}
+
+func (t *Tracker) newEvent(eventType WallEventType, wall *Wall, now time.Time) *WallEvent {
+	return &WallEvent{Type: eventType, Wall: *wall, LastUpdateID: t.depths.LastUpdateID, Time: now}
+}
+
+func notify(handlers []WallEventHandler, events []*WallEvent) {
+	for _, event := range events {
+		for _, handler := range handlers {
+			handler(event)
+		}
+	}
+}
+
+func newSides[T any]() map[types.DepthSide]map[int64]T {
+	return map[types.DepthSide]map[int64]T{
+		types.DepthSideAsk: make(map[int64]T),
+		types.DepthSideBid: make(map[int64]T),
+	}
+}
@@ -0,0 +1,144 @@
+package walls_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	walls_types "github.com/fr0ster/go-trading-utils/types/depths/walls"
+)
+
+func TestWallTracker(t *testing.T) {
+	d := depth_types.New(3, "BTCUSDT", nil, nil)
+	d.ApplySnapshot(1,
+		[]*items_types.Bid{items_types.NewBid(500, 10), items_types.NewBid(400, 10), items_types.NewBid(300, 100), items_types.NewBid(200, 10)},
+		[]*items_types.Ask{items_types.NewAsk(600, 10), items_types.NewAsk(700, 10), items_types.NewAsk(800, 100), items_types.NewAsk(900, 10)})
+
+	tracker := walls_types.New(d, walls_types.ThresholdMiddle, 2)
+	events := make([]*walls_types.WallEvent, 0)
+	tracker.AddHandler(func(event *walls_types.WallEvent) {
+		events = append(events, event)
+	})
+	assert.Equal(t, items_types.QuantityType(65), tracker.GetThreshold(types.DepthSideBid))
+	assert.Equal(t, items_types.PriceType(300), tracker.GetWalls(types.DepthSideBid)[0].Price)
+	assert.Equal(t, items_types.PriceType(800), tracker.GetWalls(types.DepthSideAsk)[0].Price)
+
+	// Стіну знято, хоча ціна до неї не дійшла
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 2, LastUpdateID: 2,
+		Bids: []items_types.PriceLevel{{Price: 300, Quantity: 0}}})
+	assert.Equal(t, 1, len(events))
+	assert.Equal(t, walls_types.WallPulled, events[0].Type)
+	assert.Equal(t, 1, tracker.GetPulledCount(types.DepthSideBid, 300))
+	assert.Empty(t, tracker.GetWalls(types.DepthSideBid))
+
+	// Стіну виконано угодами
+	tracker.AddTrade(800, 100)
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 3, LastUpdateID: 3,
+		Asks: []items_types.PriceLevel{{Price: 800, Quantity: 0}}})
+	assert.Equal(t, 2, len(events))
+	assert.Equal(t, walls_types.WallConsumed, events[1].Type)
+	assert.Equal(t, items_types.QuantityType(100), events[1].Wall.TradedQuantity)
+
+	// Нова стіна росте
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 4, LastUpdateID: 4,
+		Bids: []items_types.PriceLevel{{Price: 450, Quantity: 200}}})
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 5, LastUpdateID: 5,
+		Bids: []items_types.PriceLevel{{Price: 450, Quantity: 250}}})
+	assert.Equal(t, 4, len(events))
+	assert.Equal(t, walls_types.WallAppeared, events[2].Type)
+	assert.Equal(t, walls_types.WallChanged, events[3].Type)
+	wall := tracker.GetWalls(types.DepthSideBid)[0]
+	assert.Equal(t, items_types.QuantityType(200), wall.InitialQuantity)
+	assert.Equal(t, items_types.QuantityType(250), wall.MaxQuantity)
+
+	// Ціна дійшла до стіни, але угод по ній не було - стіну знято з вершини стакану
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 6, LastUpdateID: 6,
+		Bids: []items_types.PriceLevel{{Price: 500, Quantity: 0}, {Price: 450, Quantity: 0}}})
+	assert.Equal(t, walls_types.WallPulled, events[len(events)-1].Type)
+
+	// Стіну на найкращому рівні вибрано угодами
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 7, LastUpdateID: 7,
+		Bids: []items_types.PriceLevel{{Price: 450, Quantity: 300}}})
+	assert.Equal(t, walls_types.WallAppeared, events[len(events)-1].Type)
+	tracker.AddTrade(450, 300)
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 8, LastUpdateID: 8,
+		Bids: []items_types.PriceLevel{{Price: 450, Quantity: 0}}})
+	assert.Equal(t, walls_types.WallConsumed, events[len(events)-1].Type)
+
+	tracker.Close()
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 9, LastUpdateID: 9,
+		Bids: []items_types.PriceLevel{{Price: 450, Quantity: 1000}}})
+	assert.Empty(t, tracker.GetWalls(types.DepthSideBid))
+}
+
+func TestWallFadesWhenThresholdRises(t *testing.T) {
+	d := depth_types.New(3, "BTCUSDT", nil, nil)
+	d.ApplySnapshot(1,
+		[]*items_types.Bid{items_types.NewBid(500, 10), items_types.NewBid(400, 10), items_types.NewBid(300, 100), items_types.NewBid(200, 10)},
+		[]*items_types.Ask{items_types.NewAsk(600, 10)})
+
+	tracker := walls_types.New(d, walls_types.ThresholdMiddle, 2)
+	events := make([]*walls_types.WallEvent, 0)
+	tracker.AddHandler(func(event *walls_types.WallEvent) {
+		events = append(events, event)
+	})
+	assert.Len(t, tracker.GetWalls(types.DepthSideBid), 1)
+
+	// Інші рівні виросли, поріг піднявся до 115, стіна перевіряється без зміни власного рівня
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 2, LastUpdateID: 2,
+		Bids: []items_types.PriceLevel{{Price: 400, Quantity: 60}, {Price: 200, Quantity: 60}}})
+	assert.Equal(t, items_types.QuantityType(115), tracker.GetThreshold(types.DepthSideBid))
+	assert.Len(t, events, 1)
+	assert.Equal(t, walls_types.WallFaded, events[0].Type)
+	assert.Equal(t, items_types.QuantityType(100), events[0].Wall.Quantity)
+	assert.Equal(t, 0, tracker.GetPulledCount(types.DepthSideBid, 300))
+	assert.Empty(t, tracker.GetWalls(types.DepthSideBid))
+
+	// Незначне зменшення стіни нижче порогу також не вважається зняттям
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 3, LastUpdateID: 3,
+		Bids: []items_types.PriceLevel{{Price: 300, Quantity: 200}}})
+	assert.Equal(t, walls_types.WallAppeared, events[len(events)-1].Type)
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 4, LastUpdateID: 4,
+		Bids: []items_types.PriceLevel{{Price: 300, Quantity: 110}}})
+	assert.Equal(t, walls_types.WallFaded, events[len(events)-1].Type)
+	assert.Equal(t, 0, tracker.GetPulledCount(types.DepthSideBid, 300))
+
+	// Стіна знову з'явилась без історії зняття
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 5, LastUpdateID: 5,
+		Bids: []items_types.PriceLevel{{Price: 300, Quantity: 400}}})
+	assert.Equal(t, walls_types.WallAppeared, events[len(events)-1].Type)
+	assert.Equal(t, 0, events[len(events)-1].Wall.PulledBefore)
+
+	// Зменшення більше ніж на половину без угод вважається зняттям, навіть якщо рівень лишився
+	d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: 6, LastUpdateID: 6,
+		Bids: []items_types.PriceLevel{{Price: 300, Quantity: 50}}})
+	assert.Equal(t, walls_types.WallPulled, events[len(events)-1].Type)
+	assert.Equal(t, 1, tracker.GetPulledCount(types.DepthSideBid, 300))
+	tracker.Close()
+}
+
+func TestWallTrackerMaxPulled(t *testing.T) {
+	d := depth_types.New(3, "BTCUSDT", nil, nil)
+	d.ApplySnapshot(1,
+		[]*items_types.Bid{items_types.NewBid(500, 10), items_types.NewBid(100, 10)},
+		[]*items_types.Ask{items_types.NewAsk(600, 10)})
+	tracker := walls_types.New(d, walls_types.ThresholdMiddle, 2)
+	tracker.SetMaxPulled(2)
+
+	// Кожна стіна з'являється та знімається, зберігаються тільки дві останні ціни
+	updateID := int64(1)
+	for _, price := range []items_types.PriceType{300, 200, 300, 400} {
+		for _, quantity := range []items_types.QuantityType{1000, 0} {
+			updateID++
+			d.ProcessUpdate(&depth_types.DepthUpdate{FirstUpdateID: updateID, LastUpdateID: updateID,
+				Bids: []items_types.PriceLevel{{Price: price, Quantity: quantity}}})
+		}
+	}
+	assert.Equal(t, 2, tracker.GetPulledCount(types.DepthSideBid, 300))
+	assert.Equal(t, 1, tracker.GetPulledCount(types.DepthSideBid, 400))
+	assert.Equal(t, 0, tracker.GetPulledCount(types.DepthSideBid, 200))
+	tracker.Close()
+}
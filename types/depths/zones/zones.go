@@ -0,0 +1,282 @@
+package zones
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/btree"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	heatmap_types "github.com/fr0ster/go-trading-utils/types/depths/heatmap"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	kline_types "github.com/fr0ster/go-trading-utils/types/klines"
+	"github.com/fr0ster/go-trading-utils/utils"
+)
+
+const (
+	Support    ZoneType = "SUPPORT"
+	Resistance ZoneType = "RESISTANCE"
+)
+
+type (
+	ZoneType string
+	// Zone - діапазон цін з підтвердженою ліквідністю або розворотами ціни
+	Zone struct {
+		Type ZoneType
+		Low  items_types.PriceType
+		High items_types.PriceType
+		// Сила зони, див. Config
+		Strength float64
+		// Частка зрізів історії, в яких кошики зони мали велику кількість, 0..1
+		Persistence float64
+		// Середня кількість в зоні по зрізах, де вона була великою
+		Quantity items_types.QuantityType
+		// Кількість локальних максимумів та мінімумів свічок в зоні
+		Swings int
+		// Кількість свічок, діапазон яких зачепив зону
+		Touches int
+	}
+	// Config - параметри пошуку зон
+	Config struct {
+		// Скільки історії брати до останнього зрізу, 0 - всю
+		Window time.Duration
+		// Кошик великий, якщо його кількість не менша за Multiplier * середню кількість кошиків сторони в зрізі
+		Multiplier float64
+		// Мінімальна частка зрізів з великою кількістю, щоб кошик став зоною
+		MinPersistence float64
+		// Свічка - локальний максимум/мінімум, якщо він не перевищений SwingBars свічками з кожного боку
+		SwingBars int
+		// Зони, між якими не більше MergeDistance, зливаються
+		MergeDistance items_types.PriceType
+		// Strength = Persistence * (1 + Quantity / максимальна Quantity) + SwingWeight * Swings + TouchWeight * Touches
+		SwingWeight float64
+		TouchWeight float64
+	}
+	// Zones - зони підтримки та опору відносно Price, кожен список від найсильнішої зони
+	Zones struct {
+		Price       items_types.PriceType
+		Supports    []Zone
+		Resistances []Zone
+	}
+	candidate struct {
+		low, high   items_types.PriceType
+		persistence float64
+		quantity    items_types.QuantityType
+		swings      int
+	}
+)
+
+// DefaultConfig повертає параметри для зрізів раз на хвилину та годинних свічок
+func DefaultConfig() Config {
+	return Config{
+		Window:         24 * time.Hour,
+		Multiplier:     2,
+		MinPersistence: 0.5,
+		SwingBars:      2,
+		SwingWeight:    0.5,
+		TouchWeight:    0.05,
+	}
+}
+
+// Side повертає сторону стакану, на якій зазвичай стоїть ліквідність зони
+func (z *Zone) Side() types.DepthSide {
+	if z.Type == Support {
+		return types.DepthSideBid
+	}
+	return types.DepthSideAsk
+}
+
+// Find повертає зони з історії ліквідності history та свічок klines відносно ціни price.
+// klines може бути nil, тоді зони будуються тільки з ліквідності. Блокує klines на час читання.
+func Find(history *heatmap_types.History, klines *kline_types.Klines, price items_types.PriceType, config Config) *Zones {
+	candidates := liquidity(history, config)
+	var bars []bar
+	if klines != nil {
+		bars = readBars(klines)
+		candidates = append(candidates, swings(bars, config.SwingBars)...)
+	}
+	merged := merge(candidates, config.MergeDistance)
+	var maxQuantity items_types.QuantityType
+	for _, c := range merged {
+		if c.quantity > maxQuantity {
+			maxQuantity = c.quantity
+		}
+	}
+	zones := &Zones{Price: price}
+	for _, c := range merged {
+		zone := Zone{
+			Low:         c.low,
+			High:        c.high,
+			Persistence: c.persistence,
+			Quantity:    c.quantity,
+			Swings:      c.swings,
+			Touches:     touches(bars, c.low, c.high),
+		}
+		zone.Strength = zone.Persistence + config.SwingWeight*float64(zone.Swings) + config.TouchWeight*float64(zone.Touches)
+		if maxQuantity > 0 {
+			zone.Strength += zone.Persistence * float64(zone.Quantity/maxQuantity)
+		}
+		// Зона, яка містить ціну, відноситься до ближчої межі
+		if zone.High < price || zone.Low <= price && price-zone.Low > zone.High-price {
+			zone.Type = Support
+			zones.Supports = append(zones.Supports, zone)
+		} else {
+			zone.Type = Resistance
+			zones.Resistances = append(zones.Resistances, zone)
+		}
+	}
+	for _, list := range [][]Zone{zones.Supports, zones.Resistances} {
+		sort.SliceStable(list, func(i, j int) bool { return list[i].Strength > list[j].Strength })
+	}
+	return zones
+}
+
+// NextUp повертає найближчу опору вище price з силою не меншою за minStrength,
+// для TP та сітки замість NextPriceUp
+func (z *Zones) NextUp(price items_types.PriceType, minStrength float64) (zone Zone, ok bool) {
+	for _, r := range z.Resistances {
+		if r.Low > price && r.Strength >= minStrength && (!ok || r.Low < zone.Low) {
+			zone, ok = r, true
+		}
+	}
+	return
+}
+
+// NextDown повертає найближчу підтримку нижче price з силою не меншою за minStrength
+func (z *Zones) NextDown(price items_types.PriceType, minStrength float64) (zone Zone, ok bool) {
+	for _, s := range z.Supports {
+		if s.High < price && s.Strength >= minStrength && (!ok || s.High > zone.High) {
+			zone, ok = s, true
+		}
+	}
+	return
+}
+
+// Кошики, кількість яких стабільно велика, по обох сторонах стакану
+func liquidity(history *heatmap_types.History, config Config) (candidates []candidate) {
+	frames := history.GetLastFrames(config.Window)
+	if len(frames) == 0 {
+		return
+	}
+	type stat struct {
+		count    int
+		quantity items_types.QuantityType
+		// Останній зріз, в якому кошик був великим
+		frame int
+	}
+	stats := make(map[int64]*stat)
+	for index, frame := range frames {
+		for _, cells := range [][]heatmap_types.Cell{frame.Asks, frame.Bids} {
+			if len(cells) == 0 {
+				continue
+			}
+			var summa items_types.QuantityType
+			for _, cell := range cells {
+				summa += cell.Quantity
+			}
+			threshold := summa / items_types.QuantityType(len(cells)) * items_types.QuantityType(config.Multiplier)
+			for _, cell := range cells {
+				if cell.Quantity < threshold {
+					continue
+				}
+				s, ok := stats[cell.Bucket]
+				if !ok {
+					s = &stat{frame: -1}
+					stats[cell.Bucket] = s
+				}
+				// Кошик спреду буває на обох сторонах, але зріз рахується один раз, щоб Persistence не перевищила 1
+				if s.frame != index {
+					s.frame = index
+					s.count++
+				}
+				s.quantity += cell.Quantity
+			}
+		}
+	}
+	for bucket, s := range stats {
+		persistence := float64(s.count) / float64(len(frames))
+		if persistence < config.MinPersistence {
+			continue
+		}
+		low := history.GetBucketPrice(bucket)
+		candidates = append(candidates, candidate{
+			low:         low,
+			high:        low + history.GetBucketSize(),
+			persistence: persistence,
+			quantity:    s.quantity / items_types.QuantityType(s.count),
+		})
+	}
+	return
+}
+
+type bar struct {
+	high, low items_types.PriceType
+}
+
+func readBars(klines *kline_types.Klines) (bars []bar) {
+	klines.Lock()
+	defer klines.Unlock()
+	klines.Ascend(func(i btree.Item) bool {
+		kline := i.(*kline_types.Kline)
+		bars = append(bars, bar{
+			high: items_types.PriceType(utils.ConvStrToFloat64(kline.High)),
+			low:  items_types.PriceType(utils.ConvStrToFloat64(kline.Low)),
+		})
+		return true
+	})
+	return
+}
+
+// Локальні максимуми та мінімуми, які не перевищені count свічками з кожного боку
+func swings(bars []bar, count int) (candidates []candidate) {
+	if count <= 0 {
+		return
+	}
+	for i := count; i < len(bars)-count; i++ {
+		isHigh, isLow := true, true
+		for j := i - count; j <= i+count; j++ {
+			if j == i {
+				continue
+			}
+			isHigh = isHigh && bars[j].high < bars[i].high
+			isLow = isLow && bars[j].low > bars[i].low
+		}
+		if isHigh {
+			candidates = append(candidates, candidate{low: bars[i].high, high: bars[i].high, swings: 1})
+		}
+		if isLow {
+			candidates = append(candidates, candidate{low: bars[i].low, high: bars[i].low, swings: 1})
+		}
+	}
+	return
+}
+
+func merge(candidates []candidate, distance items_types.PriceType) (merged []candidate) {
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].low < candidates[j].low })
+	for _, c := range candidates {
+		if n := len(merged); n > 0 && c.low-merged[n-1].high <= distance {
+			last := &merged[n-1]
+			if c.high > last.high {
+				last.high = c.high
+			}
+			last.persistence = math.Max(last.persistence, c.persistence)
+			if c.quantity > last.quantity {
+				last.quantity = c.quantity
+			}
+			last.swings += c.swings
+			continue
+		}
+		merged = append(merged, c)
+	}
+	return
+}
+
+func touches(bars []bar, low, high items_types.PriceType) (count int) {
+	for _, b := range bars {
+		if b.low <= high && b.high >= low {
+			count++
+		}
+	}
+	return
+}
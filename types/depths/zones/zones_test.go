@@ -0,0 +1,84 @@
+package zones_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	heatmap_types "github.com/fr0ster/go-trading-utils/types/depths/heatmap"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	zones_types "github.com/fr0ster/go-trading-utils/types/depths/zones"
+	kline_types "github.com/fr0ster/go-trading-utils/types/klines"
+	"github.com/fr0ster/go-trading-utils/utils"
+)
+
+func TestFindZones(t *testing.T) {
+	history, _ := heatmap_types.New(100, time.Minute, 0)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		history.AddFrame(heatmap_types.Frame{
+			Time: start.Add(time.Duration(i) * time.Minute),
+			Bids: []heatmap_types.Cell{{Bucket: 589, Quantity: 1}, {Bucket: 590, Quantity: 20}, {Bucket: 595, Quantity: 1}, {Bucket: 599, Quantity: 1}},
+			Asks: []heatmap_types.Cell{{Bucket: 600, Quantity: 1}, {Bucket: 605, Quantity: 1}, {Bucket: 610, Quantity: 20}, {Bucket: 612, Quantity: 1}},
+		})
+	}
+	klines := kline_types.New(make(chan struct{}), 3, kline_types.KlineStreamInterval1m, "BTCUSDT", nil, nil)
+	highs := []float64{60500, 60800, 61050, 60700, 60400, 60300, 60600}
+	lows := []float64{59800, 59600, 59700, 59300, 59010, 59400, 59700}
+	for i := range highs {
+		klines.SetKline(&kline_types.Kline{
+			OpenTime:  int64(i) * 60000,
+			CloseTime: int64(i+1)*60000 - 1,
+			High:      utils.ConvFloat64ToStrDefault(highs[i]),
+			Low:       utils.ConvFloat64ToStrDefault(lows[i]),
+		})
+	}
+
+	config := zones_types.DefaultConfig()
+	config.MergeDistance = 50
+	zones := zones_types.Find(history, klines, 60000, config)
+	assert.Equal(t, 1, len(zones.Supports))
+	assert.Equal(t, 1, len(zones.Resistances))
+
+	support := zones.Supports[0]
+	assert.Equal(t, zones_types.Support, support.Type)
+	assert.Equal(t, items_types.PriceType(59000), support.Low)
+	assert.Equal(t, items_types.PriceType(59100), support.High)
+	assert.Equal(t, 1.0, support.Persistence)
+	assert.Equal(t, 1, support.Swings)
+	assert.Equal(t, 1, support.Touches)
+	assert.InDelta(t, 2.55, support.Strength, 1e-9)
+
+	next, ok := zones.NextUp(60000, 0)
+	assert.True(t, ok)
+	assert.Equal(t, items_types.PriceType(61000), next.Low)
+	assert.Equal(t, 1, next.Swings)
+	_, ok = zones.NextUp(60000, 3)
+	assert.False(t, ok)
+	next, ok = zones.NextDown(60000, 0)
+	assert.True(t, ok)
+	assert.Equal(t, items_types.PriceType(59100), next.High)
+
+	// Без свічок зони будуються тільки з ліквідності
+	zones = zones_types.Find(history, nil, 60000, config)
+	assert.Equal(t, 0, zones.Supports[0].Swings)
+	assert.InDelta(t, 2.0, zones.Supports[0].Strength, 1e-9)
+}
+
+func TestZonePersistenceWithSpreadBucket(t *testing.T) {
+	history, _ := heatmap_types.New(100, time.Minute, 0)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		// Кошик 600 великий на обох сторонах одного зрізу
+		history.AddFrame(heatmap_types.Frame{
+			Time: start.Add(time.Duration(i) * time.Minute),
+			Bids: []heatmap_types.Cell{{Bucket: 590, Quantity: 1}, {Bucket: 595, Quantity: 1}, {Bucket: 600, Quantity: 20}},
+			Asks: []heatmap_types.Cell{{Bucket: 600, Quantity: 20}, {Bucket: 605, Quantity: 1}, {Bucket: 610, Quantity: 1}},
+		})
+	}
+	zones := zones_types.Find(history, nil, 70000, zones_types.DefaultConfig())
+	assert.Equal(t, 1, len(zones.Supports))
+	assert.Equal(t, 1.0, zones.Supports[0].Persistence)
+	assert.Equal(t, items_types.QuantityType(40), zones.Supports[0].Quantity)
+}
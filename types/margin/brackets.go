@@ -0,0 +1,71 @@
+package margin
+
+import (
+	"fmt"
+	"sort"
+
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+type (
+	// Bracket - рівень номіналу позиції з максимальним плечем та ставкою підтримуючої маржі.
+	// Cum - сума підтримки, яка віднімається від номінал * MaintMarginRatio.
+	Bracket struct {
+		Bracket          int
+		InitialLeverage  int
+		NotionalFloor    items_types.ValueType
+		NotionalCap      items_types.ValueType
+		MaintMarginRatio float64
+		Cum              items_types.ValueType
+	}
+	// Brackets - рівні пари, впорядковані за NotionalFloor
+	Brackets []Bracket
+)
+
+// NewBrackets впорядковує рівні за номіналом
+func NewBrackets(brackets ...Bracket) Brackets {
+	res := append(Brackets{}, brackets...)
+	sort.Slice(res, func(i, j int) bool { return res[i].NotionalFloor < res[j].NotionalFloor })
+	return res
+}
+
+// Find повертає рівень для номіналу notional
+func (b Brackets) Find(notional items_types.ValueType) (bracket Bracket, err error) {
+	if len(b) == 0 {
+		return Bracket{}, fmt.Errorf("leverage brackets are empty")
+	}
+	for _, bracket = range b {
+		if notional < bracket.NotionalCap {
+			return
+		}
+	}
+	return bracket, fmt.Errorf("notional %v exceeds max notional %v", notional, bracket.NotionalCap)
+}
+
+// MaintenanceMargin повертає підтримуючу маржу для номіналу notional
+func (b Brackets) MaintenanceMargin(notional items_types.ValueType) (items_types.ValueType, error) {
+	bracket, err := b.Find(notional)
+	if err != nil {
+		return 0, err
+	}
+	return notional*items_types.ValueType(bracket.MaintMarginRatio) - bracket.Cum, nil
+}
+
+// MaxNotional повертає найбільший номінал, дозволений з плечем leverage, 0 якщо плече завелике
+func (b Brackets) MaxNotional(leverage int) (notional items_types.ValueType) {
+	for _, bracket := range b {
+		if bracket.InitialLeverage >= leverage {
+			notional = bracket.NotionalCap
+		}
+	}
+	return
+}
+
+// MaxLeverage повертає найбільше плече для номіналу notional
+func (b Brackets) MaxLeverage(notional items_types.ValueType) int {
+	bracket, err := b.Find(notional)
+	if err != nil {
+		return 0
+	}
+	return bracket.InitialLeverage
+}
@@ -0,0 +1,270 @@
+package margin
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+const maxSafeIterations = 100
+
+type (
+	// Position - позиція USDT-M ф'ючерсів в режимі однієї позиції.
+	// Quantity додатна для long та від'ємна для short, IsolatedMargin - гаманець ізольованої позиції.
+	Position struct {
+		Symbol         string
+		Quantity       items_types.QuantityType
+		EntryPrice     items_types.PriceType
+		MarkPrice      items_types.PriceType
+		Leverage       int
+		MarginType     types.MarginType
+		IsolatedMargin items_types.ValueType
+	}
+	// Calculator рахує підтримуючу маржу, ціну ліквідації та безпечний розмір позиції
+	// за рівнями номіналу пар, без звернення до біржі
+	Calculator struct {
+		mutex    sync.Mutex
+		brackets map[string]Brackets
+		Init     types.InitFunction
+	}
+)
+
+// GetNotional повертає номінал позиції за ціною маркування, або за ціною входу, якщо її немає
+func (p Position) GetNotional() items_types.ValueType {
+	return items_types.ValueType(math.Abs(float64(p.Quantity)) * float64(p.price()))
+}
+
+func (p Position) GetUnrealizedPnL() items_types.ValueType {
+	return items_types.ValueType(float64(p.price()-p.EntryPrice) * float64(p.Quantity))
+}
+
+// Add повертає позицію після виконання quantity за ціною price, ціна маркування стає price.
+// Маржа ізольованої позиції збільшується на початкову маржу доданого номіналу
+// та зменшується пропорційно закритій частині.
+func (p Position) Add(side types.OrderSide, quantity items_types.QuantityType, price items_types.PriceType) Position {
+	delta := float64(quantity)
+	if side == types.SideTypeSell {
+		delta = -delta
+	}
+	current := float64(p.Quantity)
+	leverage := float64(p.leverage())
+	switch {
+	case current == 0 || current*delta > 0:
+		p.EntryPrice = items_types.PriceType((float64(p.EntryPrice)*math.Abs(current) + float64(price)*math.Abs(delta)) /
+			(math.Abs(current) + math.Abs(delta)))
+		p.IsolatedMargin += items_types.ValueType(math.Abs(delta) * float64(price) / leverage)
+	case math.Abs(delta) <= math.Abs(current):
+		p.IsolatedMargin -= p.IsolatedMargin * items_types.ValueType(math.Abs(delta)/math.Abs(current))
+	default:
+		p.EntryPrice = price
+		p.IsolatedMargin = items_types.ValueType((math.Abs(delta) - math.Abs(current)) * float64(price) / leverage)
+	}
+	p.Quantity = items_types.QuantityType(current + delta)
+	if p.Quantity == 0 {
+		p.EntryPrice, p.IsolatedMargin = 0, 0
+	}
+	p.MarkPrice = price
+	return p
+}
+
+func (p Position) price() items_types.PriceType {
+	if p.MarkPrice > 0 {
+		return p.MarkPrice
+	}
+	return p.EntryPrice
+}
+
+func (p Position) leverage() int {
+	if p.Leverage > 0 {
+		return p.Leverage
+	}
+	return 1
+}
+
+// New створює калькулятор та завантажує рівні через init, якщо він заданий.
+// Помилка завантаження логується, рівні можна завантажити повторно через Refresh.
+func New(initCreator func(*Calculator) types.InitFunction) *Calculator {
+	this := &Calculator{brackets: make(map[string]Brackets)}
+	if initCreator != nil {
+		this.Init = initCreator(this)
+		if err := this.Init(); err != nil {
+			logrus.Errorf("Can't load leverage brackets: %v", err)
+		}
+	}
+	return this
+}
+
+// Refresh повторно завантажує рівні через Init
+func (c *Calculator) Refresh() error {
+	if c.Init == nil {
+		return errors.New("margin init function is not set")
+	}
+	return c.Init()
+}
+
+func (c *Calculator) SetBrackets(symbol string, brackets Brackets) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.brackets[symbol] = NewBrackets(brackets...)
+}
+
+func (c *Calculator) GetBrackets(symbol string) (Brackets, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	brackets, ok := c.brackets[symbol]
+	if !ok {
+		return nil, fmt.Errorf("leverage brackets for %v not found", symbol)
+	}
+	return brackets, nil
+}
+
+// GetMaintenanceMargin повертає підтримуючу маржу позиції
+func (c *Calculator) GetMaintenanceMargin(position Position) (items_types.ValueType, error) {
+	if position.Quantity == 0 {
+		return 0, nil
+	}
+	brackets, err := c.GetBrackets(position.Symbol)
+	if err != nil {
+		return 0, err
+	}
+	return brackets.MaintenanceMargin(position.GetNotional())
+}
+
+// GetMarginRatio повертає відношення підтримуючої маржі до балансу маржі, 1 і більше - ліквідація.
+// Для ізольованої маржі баланс - IsolatedMargin позиції, для крос - wallet та інші крос позиції others.
+func (c *Calculator) GetMarginRatio(position Position, wallet items_types.ValueType, others ...Position) (ratio float64, err error) {
+	maintenance, err := c.GetMaintenanceMargin(position)
+	if err != nil {
+		return
+	}
+	balance := position.GetUnrealizedPnL()
+	if position.MarginType == types.IsolatedMarginType {
+		balance += position.IsolatedMargin
+	} else {
+		balance += wallet
+		for _, other := range others {
+			otherMaintenance, err := c.GetMaintenanceMargin(other)
+			if err != nil {
+				return 0, err
+			}
+			maintenance += otherMaintenance
+			balance += other.GetUnrealizedPnL()
+		}
+	}
+	if balance <= 0 {
+		return math.Inf(1), nil
+	}
+	return float64(maintenance / balance), nil
+}
+
+// GetLiquidationPrice повертає ціну ліквідації позиції за формулою Binance для режиму однієї позиції,
+// 0 якщо позиції немає або long не ліквідується.
+// Рівень обирається за номіналом на ціні ліквідації.
+// Для крос маржі others - інші крос позиції рахунку, wallet - баланс гаманця.
+func (c *Calculator) GetLiquidationPrice(position Position, wallet items_types.ValueType, others ...Position) (price items_types.PriceType, err error) {
+	if position.Quantity == 0 {
+		return 0, nil
+	}
+	brackets, err := c.GetBrackets(position.Symbol)
+	if err != nil {
+		return
+	}
+	var otherMaintenance, otherPnL items_types.ValueType
+	if position.MarginType == types.IsolatedMarginType {
+		wallet = position.IsolatedMargin
+	} else {
+		for _, other := range others {
+			maintenance, err := c.GetMaintenanceMargin(other)
+			if err != nil {
+				return 0, err
+			}
+			otherMaintenance += maintenance
+			otherPnL += other.GetUnrealizedPnL()
+		}
+	}
+	side := math.Copysign(1, float64(position.Quantity))
+	size := math.Abs(float64(position.Quantity))
+	bracket, _ := brackets.Find(position.GetNotional())
+	for i := 0; i <= len(brackets); i++ {
+		price = items_types.PriceType(
+			(float64(wallet-otherMaintenance+otherPnL+bracket.Cum) - side*size*float64(position.EntryPrice)) /
+				(size*bracket.MaintMarginRatio - side*size))
+		if price < 0 {
+			return 0, nil
+		}
+		next, _ := brackets.Find(items_types.ValueType(size * float64(price)))
+		if next.Bracket == bracket.Bracket {
+			break
+		}
+		bracket = next
+	}
+	return
+}
+
+// GetMaxSafeQuantity повертає найбільшу кількість, яку можна додати до позиції на side за ціною price,
+// щоб початкова маржа не перевищила wallet, номінал не перевищив межу рівня для плеча позиції,
+// а ціна ліквідації була не ближче minDistance відсотків від price.
+// Закриття існуючої позиції завжди безпечне, обмеження діють на кількість понад неї.
+func (c *Calculator) GetMaxSafeQuantity(
+	position Position,
+	side types.OrderSide,
+	price items_types.PriceType,
+	wallet items_types.ValueType,
+	minDistance items_types.PricePercentType,
+	others ...Position) (quantity items_types.QuantityType, err error) {
+	if price <= 0 {
+		return 0, fmt.Errorf("price %v must be positive", price)
+	}
+	brackets, err := c.GetBrackets(position.Symbol)
+	if err != nil {
+		return
+	}
+	var base float64
+	if side == types.SideTypeBuy && position.Quantity < 0 || side == types.SideTypeSell && position.Quantity > 0 {
+		base = math.Abs(float64(position.Quantity))
+	}
+	var otherMargin items_types.ValueType
+	for _, other := range others {
+		otherMargin += other.GetNotional() / items_types.ValueType(other.leverage())
+	}
+	safe := func(quantity float64) bool {
+		after := position.Add(side, items_types.QuantityType(quantity), price)
+		if after.GetNotional() > brackets.MaxNotional(after.leverage()) {
+			return false
+		}
+		added := items_types.ValueType((quantity - base) * float64(price) / float64(after.leverage()))
+		if position.MarginType == types.IsolatedMarginType {
+			if added > wallet {
+				return false
+			}
+		} else if after.GetNotional()/items_types.ValueType(after.leverage())+otherMargin > wallet {
+			return false
+		}
+		liquidation, err := c.GetLiquidationPrice(after, wallet, others...)
+		if err != nil {
+			return false
+		}
+		return liquidation == 0 ||
+			math.Abs(float64(price-liquidation))/float64(price)*100 >= float64(minDistance)
+	}
+	low := base
+	high := base + float64(brackets.MaxNotional(position.leverage()))/float64(price)
+	if !safe(low) {
+		return items_types.QuantityType(low), nil
+	}
+	for i := 0; i < maxSafeIterations && high-low > low*1e-12; i++ {
+		middle := (low + high) / 2
+		if safe(middle) {
+			low = middle
+		} else {
+			high = middle
+		}
+	}
+	return items_types.QuantityType(low), nil
+}
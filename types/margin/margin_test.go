@@ -0,0 +1,180 @@
+package margin_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	margin_types "github.com/fr0ster/go-trading-utils/types/margin"
+)
+
+const symbol = "BTCUSDT"
+
+func calculator() *margin_types.Calculator {
+	return margin_types.New(func(c *margin_types.Calculator) types.InitFunction {
+		return func() error {
+			c.SetBrackets(symbol, margin_types.Brackets{
+				{Bracket: 3, InitialLeverage: 50, NotionalFloor: 250000, NotionalCap: 3000000, MaintMarginRatio: 0.01, Cum: 1300},
+				{Bracket: 1, InitialLeverage: 125, NotionalFloor: 0, NotionalCap: 50000, MaintMarginRatio: 0.004, Cum: 0},
+				{Bracket: 2, InitialLeverage: 100, NotionalFloor: 50000, NotionalCap: 250000, MaintMarginRatio: 0.005, Cum: 50},
+			})
+			return nil
+		}
+	})
+}
+
+func TestBrackets(t *testing.T) {
+	c := calculator()
+	brackets, err := c.GetBrackets(symbol)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, brackets[0].Bracket)
+
+	maintenance, err := brackets.MaintenanceMargin(100000)
+	assert.NoError(t, err)
+	assert.InDelta(t, 450, float64(maintenance), 1e-9)
+	assert.Equal(t, items_types.ValueType(50000), brackets.MaxNotional(125))
+	assert.Equal(t, items_types.ValueType(250000), brackets.MaxNotional(100))
+	assert.Equal(t, items_types.ValueType(3000000), brackets.MaxNotional(10))
+	assert.Equal(t, items_types.ValueType(0), brackets.MaxNotional(150))
+	assert.Equal(t, 50, brackets.MaxLeverage(300000))
+
+	_, err = brackets.Find(5000000)
+	assert.Error(t, err)
+	_, err = c.GetBrackets("ETHUSDT")
+	assert.Error(t, err)
+}
+
+func TestNewInitError(t *testing.T) {
+	hook := test.NewGlobal()
+	defer hook.Reset()
+	c := margin_types.New(func(*margin_types.Calculator) types.InitFunction {
+		return func() error { return errors.New("-1003 too many requests") }
+	})
+	// Помилка завантаження рівнів видна одразу, а не як відсутні рівні при розрахунку
+	if assert.NotNil(t, hook.LastEntry()) {
+		assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+		assert.Contains(t, hook.LastEntry().Message, "-1003")
+	}
+	assert.Error(t, c.Refresh())
+}
+
+func TestLiquidationPrice(t *testing.T) {
+	c := calculator()
+	long := margin_types.Position{
+		Symbol: symbol, Quantity: 1, EntryPrice: 10000, Leverage: 10,
+		MarginType: types.IsolatedMarginType, IsolatedMargin: 1000,
+	}
+	price, err := c.GetLiquidationPrice(long, 0)
+	assert.NoError(t, err)
+	assert.InDelta(t, 9000/0.996, float64(price), 1e-6)
+
+	short := long
+	short.Quantity = -1
+	price, err = c.GetLiquidationPrice(short, 0)
+	assert.NoError(t, err)
+	assert.InDelta(t, 11000/1.004, float64(price), 1e-6)
+
+	// Рівень обирається за номіналом на ціні ліквідації, а не на ціні входу
+	large := long
+	large.Quantity, large.IsolatedMargin = 5.2, 5200
+	price, err = c.GetLiquidationPrice(large, 0)
+	assert.NoError(t, err)
+	assert.InDelta(t, 9000/0.996, float64(price), 1e-6)
+
+	// Крос маржа враховує гаманець та інші позиції
+	cross := long
+	cross.MarginType = types.CrossMarginType
+	price, err = c.GetLiquidationPrice(cross, 2000)
+	assert.NoError(t, err)
+	assert.InDelta(t, 8000/0.996, float64(price), 1e-6)
+	other := margin_types.Position{Symbol: symbol, Quantity: -1, EntryPrice: 10000, MarkPrice: 10500, Leverage: 10}
+	price, err = c.GetLiquidationPrice(cross, 2000, other)
+	assert.NoError(t, err)
+	assert.InDelta(t, 8542/0.996, float64(price), 1e-6)
+
+	// Long з гаманцем більше номіналу не ліквідується
+	price, err = c.GetLiquidationPrice(cross, 20000)
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.PriceType(0), price)
+
+	price, err = c.GetLiquidationPrice(margin_types.Position{Symbol: "ETHUSDT"}, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.PriceType(0), price)
+}
+
+func TestMarginRatio(t *testing.T) {
+	c := calculator()
+	position := margin_types.Position{
+		Symbol: symbol, Quantity: 1, EntryPrice: 10000, MarkPrice: 9500, Leverage: 10,
+		MarginType: types.IsolatedMarginType, IsolatedMargin: 1000,
+	}
+	maintenance, err := c.GetMaintenanceMargin(position)
+	assert.NoError(t, err)
+	assert.InDelta(t, 38, float64(maintenance), 1e-9)
+	ratio, err := c.GetMarginRatio(position, 0)
+	assert.NoError(t, err)
+	assert.InDelta(t, 0.076, ratio, 1e-9)
+
+	position.MarginType = types.CrossMarginType
+	ratio, err = c.GetMarginRatio(position, 400)
+	assert.NoError(t, err)
+	assert.True(t, math.IsInf(ratio, 1))
+}
+
+func TestPositionAdd(t *testing.T) {
+	position := margin_types.Position{
+		Symbol: symbol, Quantity: 1, EntryPrice: 10000, Leverage: 10,
+		MarginType: types.IsolatedMarginType, IsolatedMargin: 1000,
+	}
+	position = position.Add(types.SideTypeBuy, 1, 12000)
+	assert.Equal(t, items_types.QuantityType(2), position.Quantity)
+	assert.Equal(t, items_types.PriceType(11000), position.EntryPrice)
+	assert.Equal(t, items_types.ValueType(2200), position.IsolatedMargin)
+	assert.Equal(t, items_types.ValueType(2000), position.GetUnrealizedPnL())
+
+	position = position.Add(types.SideTypeSell, 1, 12000)
+	assert.Equal(t, items_types.ValueType(1100), position.IsolatedMargin)
+	assert.Equal(t, items_types.PriceType(11000), position.EntryPrice)
+
+	position = position.Add(types.SideTypeSell, 3, 9000)
+	assert.Equal(t, items_types.QuantityType(-2), position.Quantity)
+	assert.Equal(t, items_types.PriceType(9000), position.EntryPrice)
+	assert.Equal(t, items_types.ValueType(1800), position.IsolatedMargin)
+	assert.Equal(t, items_types.ValueType(18000), position.GetNotional())
+}
+
+func TestMaxSafeQuantity(t *testing.T) {
+	c := calculator()
+	isolated := margin_types.Position{Symbol: symbol, Leverage: 10, MarginType: types.IsolatedMarginType}
+	quantity, err := c.GetMaxSafeQuantity(isolated, types.SideTypeBuy, 10000, 1000, 5)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, float64(quantity), 1e-6)
+	quantity, err = c.GetMaxSafeQuantity(isolated, types.SideTypeBuy, 10000, 1000, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.QuantityType(0), quantity)
+
+	// Крос маржа обмежена відстанню до ліквідації раніше, ніж гаманцем
+	cross := margin_types.Position{Symbol: symbol, Leverage: 20, MarginType: types.CrossMarginType}
+	quantity, err = c.GetMaxSafeQuantity(cross, types.SideTypeBuy, 10000, 1000, 5)
+	assert.NoError(t, err)
+	assert.InDelta(t, 1000.0/538, float64(quantity), 1e-6)
+	after := cross.Add(types.SideTypeBuy, quantity, 10000)
+	price, err := c.GetLiquidationPrice(after, 1000)
+	assert.NoError(t, err)
+	assert.InDelta(t, 9500, float64(price), 1e-3)
+
+	// Закриття short завжди безпечне
+	cross.Quantity, cross.EntryPrice = -3, 10000
+	quantity, err = c.GetMaxSafeQuantity(cross, types.SideTypeBuy, 10000, 1000, 5)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, float64(quantity), 3.0)
+
+	_, err = c.GetMaxSafeQuantity(isolated, types.SideTypeBuy, 0, 1000, 5)
+	assert.Error(t, err)
+}
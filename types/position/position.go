@@ -0,0 +1,335 @@
+package position
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+)
+
+// Кількість, меншу за flatQuantity, вважаємо закритою позицією
+const flatQuantity = 1e-12
+
+type (
+	// Fill - виконання ордера, з історії TradeV3 або зі стріму користувача
+	Fill struct {
+		ID              int64
+		OrderID         int64
+		Side            types.OrderSide
+		Price           items_types.PriceType
+		Quantity        items_types.QuantityType
+		Commission      items_types.ValueType
+		CommissionAsset string
+		Time            time.Time
+	}
+	// Position - облік позиції по одній парі за виконаннями ордерів в режимі однієї позиції.
+	// Кількість додатна для long та від'ємна для short, ціна входу середня зважена.
+	// Кожне виконання враховується один раз за ID, тому історію та стрім можна подавати одночасно.
+	Position struct {
+		mutex      sync.Mutex
+		symbol     string
+		baseAsset  string
+		quoteAsset string
+		feeInBase  bool
+		fills      map[int64]*Fill
+		// Позиція перед першим виконанням історії, якщо історія починається посеред позиції
+		opening       *Fill
+		quantity      items_types.QuantityType
+		entryPrice    items_types.PriceType
+		realizedPnL   items_types.ValueType
+		fees          map[string]items_types.ValueType
+		cycleRealized items_types.ValueType
+		cycleFees     items_types.ValueType
+		updatedAt     time.Time
+		Init          types.InitFunction
+	}
+)
+
+// New створює облік позиції symbol = baseAsset + quoteAsset.
+// feeInBase - комісія в базовому активі зменшує кількість, як на споті.
+func New(
+	symbol, baseAsset, quoteAsset string,
+	feeInBase bool,
+	initCreator func(*Position) types.InitFunction) *Position {
+	this := &Position{
+		symbol:     symbol,
+		baseAsset:  baseAsset,
+		quoteAsset: quoteAsset,
+		feeInBase:  feeInBase,
+		fills:      make(map[int64]*Fill),
+		fees:       make(map[string]items_types.ValueType),
+	}
+	this.SetInit(initCreator)
+	return this
+}
+
+func (p *Position) SetInit(initCreator func(*Position) types.InitFunction) {
+	if initCreator != nil {
+		p.Init = initCreator(p)
+	}
+}
+
+// Refresh перебудовує позицію з історії угод через Init, наприклад після перезапуску
+func (p *Position) Refresh() error {
+	if p.Init == nil {
+		return errors.New("position init function is not set")
+	}
+	return p.Init()
+}
+
+func (p *Position) GetSymbol() string {
+	return p.symbol
+}
+
+// GetBaseAsset повертає базовий актив пари, кількість якого змінюють виконання
+func (p *Position) GetBaseAsset() string {
+	return p.baseAsset
+}
+
+// AddFill враховує виконання, повертає false для вже врахованого ID.
+// Виконання, старше за останнє враховане, перераховує позицію за часом.
+func (p *Position) AddFill(fill Fill) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := p.fills[fill.ID]; ok {
+		return false
+	}
+	p.fills[fill.ID] = &fill
+	if fill.Time.Before(p.updatedAt) {
+		p.recalculate()
+	} else {
+		p.apply(&fill)
+	}
+	return true
+}
+
+// Rebuild додає виконання з історії до вже відомих та перераховує позицію з початку
+func (p *Position) Rebuild(fills []Fill) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	for i := range fills {
+		fill := fills[i]
+		p.fills[fill.ID] = &fill
+	}
+	p.recalculate()
+}
+
+// RebuildFrom замінює виконання історією fills, після якої кількість на біржі дорівнює quantity.
+// Кількість перед першим виконанням історії враховується як початкова позиція, тому вікно історії
+// може починатись посеред позиції. Ціна початкової позиції - entryPrice, якщо позиція в історії
+// не закривалась, інакше ціна першого виконання. Точна ціна входу потребує історії
+// від останнього закриття позиції, див. Opening.
+func (p *Position) RebuildFrom(quantity items_types.QuantityType, entryPrice items_types.PriceType, fills []Fill) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.fills = make(map[int64]*Fill, len(fills))
+	for i := range fills {
+		fill := fills[i]
+		p.fills[fill.ID] = &fill
+	}
+	p.opening = nil
+	sorted := p.sorted()
+	if opening, flat := p.openingOf(quantity, sorted); opening != 0 {
+		p.opening = &Fill{Side: types.SideTypeBuy, Quantity: opening}
+		if opening < 0 {
+			p.opening.Side, p.opening.Quantity = types.SideTypeSell, -opening
+		}
+		if len(sorted) > 0 {
+			p.opening.Price, p.opening.Time = sorted[0].Price, sorted[0].Time
+		}
+		if !flat && entryPrice > 0 {
+			p.opening.Price = entryPrice
+		}
+	}
+	p.recalculate()
+}
+
+// Opening повертає кількість перед першим виконанням fills, якщо після них кількість дорівнює quantity,
+// та чи закривалась позиція в межах fills. Без закриття історію потрібно доповнити старшими виконаннями.
+func (p *Position) Opening(quantity items_types.QuantityType, fills []Fill) (opening items_types.QuantityType, flat bool) {
+	sorted := make([]*Fill, len(fills))
+	for i := range fills {
+		sorted[i] = &fills[i]
+	}
+	sortFills(sorted)
+	return p.openingOf(quantity, sorted)
+}
+
+// Reset видаляє всі виконання
+func (p *Position) Reset() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.fills = make(map[int64]*Fill)
+	p.opening = nil
+	p.recalculate()
+}
+
+// GetQuantity повертає кількість, додатну для long та від'ємну для short
+func (p *Position) GetQuantity() items_types.QuantityType {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.quantity
+}
+
+// GetSide повертає BUY для long, SELL для short та NONE без позиції
+func (p *Position) GetSide() types.OrderSide {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	switch {
+	case p.quantity > 0:
+		return types.SideTypeBuy
+	case p.quantity < 0:
+		return types.SideTypeSell
+	default:
+		return types.SideTypeNone
+	}
+}
+
+func (p *Position) GetEntryPrice() items_types.PriceType {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.entryPrice
+}
+
+// GetRealizedPnL повертає реалізований прибуток в котирувальному активі без комісій
+func (p *Position) GetRealizedPnL() items_types.ValueType {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.realizedPnL
+}
+
+// GetUnrealizedPnL повертає нереалізований прибуток відкритої позиції за ціною price
+func (p *Position) GetUnrealizedPnL(price items_types.PriceType) items_types.ValueType {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return items_types.ValueType(float64(price-p.entryPrice) * float64(p.quantity))
+}
+
+// GetFees повертає суму комісій по кожному активу
+func (p *Position) GetFees() map[string]items_types.ValueType {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	fees := make(map[string]items_types.ValueType, len(p.fees))
+	for asset, fee := range p.fees {
+		fees[asset] = fee
+	}
+	return fees
+}
+
+// GetBreakEvenPrice повертає ціну, за якою закриття позиції покриває комісії та збиток з моменту її відкриття.
+// Враховуються комісії в базовому та котирувальному активах, 0 без позиції.
+func (p *Position) GetBreakEvenPrice() items_types.PriceType {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.quantity == 0 {
+		return 0
+	}
+	return p.entryPrice - items_types.PriceType(float64(p.cycleRealized-p.cycleFees)/float64(p.quantity))
+}
+
+func (p *Position) GetUpdatedAt() time.Time {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.updatedAt
+}
+
+func (p *Position) recalculate() {
+	p.quantity, p.entryPrice, p.realizedPnL = 0, 0, 0
+	p.cycleRealized, p.cycleFees = 0, 0
+	p.fees = make(map[string]items_types.ValueType)
+	p.updatedAt = time.Time{}
+	if p.opening != nil {
+		p.apply(p.opening)
+	}
+	for _, fill := range p.sorted() {
+		p.apply(fill)
+	}
+}
+
+func (p *Position) sorted() []*Fill {
+	fills := make([]*Fill, 0, len(p.fills))
+	for _, fill := range p.fills {
+		fills = append(fills, fill)
+	}
+	sortFills(fills)
+	return fills
+}
+
+func sortFills(fills []*Fill) {
+	sort.Slice(fills, func(i, j int) bool {
+		if fills[i].Time.Equal(fills[j].Time) {
+			return fills[i].ID < fills[j].ID
+		}
+		return fills[i].Time.Before(fills[j].Time)
+	})
+}
+
+// openingOf проходить впорядковані виконання від останнього, віднімаючи їх від quantity
+func (p *Position) openingOf(quantity items_types.QuantityType, fills []*Fill) (opening items_types.QuantityType, flat bool) {
+	current := float64(quantity)
+	flat = math.Abs(current) < flatQuantity
+	for i := len(fills) - 1; i >= 0; i-- {
+		fill := fills[i]
+		if fill.Side == types.SideTypeSell {
+			current += float64(fill.Quantity)
+		} else {
+			current -= float64(fill.Quantity)
+		}
+		if p.feeInBase && fill.CommissionAsset == p.baseAsset {
+			current += float64(fill.Commission)
+		}
+		if math.Abs(current) < flatQuantity {
+			current, flat = 0, true
+		}
+	}
+	return items_types.QuantityType(current), flat
+}
+
+func (p *Position) apply(fill *Fill) {
+	quantity := float64(fill.Quantity)
+	if fill.Side == types.SideTypeSell {
+		quantity = -quantity
+	}
+	current := float64(p.quantity)
+	price := float64(fill.Price)
+	if current == 0 || current*quantity > 0 {
+		p.entryPrice = items_types.PriceType((float64(p.entryPrice)*math.Abs(current) + price*math.Abs(quantity)) /
+			(math.Abs(current) + math.Abs(quantity)))
+	} else {
+		closed := math.Min(math.Abs(quantity), math.Abs(current))
+		pnl := items_types.ValueType((price - float64(p.entryPrice)) * closed * math.Copysign(1, current))
+		p.realizedPnL += pnl
+		p.cycleRealized += pnl
+		if math.Abs(quantity) > math.Abs(current) {
+			// Позиція перевернулась, залишок відкриває нову за ціною виконання
+			p.entryPrice = fill.Price
+			p.cycleRealized, p.cycleFees = 0, 0
+		}
+	}
+	current += quantity
+	if fill.Commission != 0 {
+		p.fees[fill.CommissionAsset] += fill.Commission
+		switch fill.CommissionAsset {
+		case p.quoteAsset:
+			p.cycleFees += fill.Commission
+		case p.baseAsset:
+			p.cycleFees += items_types.ValueType(float64(fill.Commission) * price)
+			if p.feeInBase {
+				current -= float64(fill.Commission)
+			}
+		}
+	}
+	if math.Abs(current) < flatQuantity {
+		current = 0
+		p.entryPrice = 0
+		p.cycleRealized, p.cycleFees = 0, 0
+	}
+	p.quantity = items_types.QuantityType(current)
+	if fill.Time.After(p.updatedAt) {
+		p.updatedAt = fill.Time
+	}
+}
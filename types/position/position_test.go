@@ -0,0 +1,156 @@
+package position_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	position_types "github.com/fr0ster/go-trading-utils/types/position"
+)
+
+var start = time.UnixMilli(1700000000000)
+
+func fill(id int64, side types.OrderSide, price items_types.PriceType, quantity items_types.QuantityType, fee items_types.ValueType) position_types.Fill {
+	return position_types.Fill{
+		ID:              id,
+		Side:            side,
+		Price:           price,
+		Quantity:        quantity,
+		Commission:      fee,
+		CommissionAsset: "USDT",
+		Time:            start.Add(time.Duration(id) * time.Second),
+	}
+}
+
+func history() []position_types.Fill {
+	return []position_types.Fill{
+		fill(1, types.SideTypeBuy, 100, 1, 0.1),
+		fill(2, types.SideTypeBuy, 110, 1, 0.11),
+		fill(3, types.SideTypeSell, 120, 1, 0.12),
+		fill(4, types.SideTypeSell, 100, 3, 0.3),
+	}
+}
+
+func TestPositionLongAndShort(t *testing.T) {
+	p := position_types.New("BTCUSDT", "BTC", "USDT", false, nil)
+	fills := history()
+	assert.True(t, p.AddFill(fills[0]))
+	assert.True(t, p.AddFill(fills[1]))
+	assert.Equal(t, items_types.QuantityType(2), p.GetQuantity())
+	assert.Equal(t, items_types.PriceType(105), p.GetEntryPrice())
+	assert.InDelta(t, 105.105, float64(p.GetBreakEvenPrice()), 1e-9)
+
+	assert.True(t, p.AddFill(fills[2]))
+	assert.False(t, p.AddFill(fills[2]))
+	assert.Equal(t, items_types.QuantityType(1), p.GetQuantity())
+	assert.Equal(t, items_types.PriceType(105), p.GetEntryPrice())
+	assert.Equal(t, items_types.ValueType(15), p.GetRealizedPnL())
+	assert.Equal(t, items_types.ValueType(-5), p.GetUnrealizedPnL(100))
+	assert.InDelta(t, 90.33, float64(p.GetBreakEvenPrice()), 1e-9)
+	assert.Equal(t, types.SideTypeBuy, p.GetSide())
+
+	// Продаж більше за позицію перевертає її в short
+	assert.True(t, p.AddFill(fills[3]))
+	assert.Equal(t, items_types.QuantityType(-2), p.GetQuantity())
+	assert.Equal(t, items_types.PriceType(100), p.GetEntryPrice())
+	assert.Equal(t, items_types.ValueType(10), p.GetRealizedPnL())
+	assert.Equal(t, items_types.ValueType(20), p.GetUnrealizedPnL(90))
+	assert.InDelta(t, 0.63, float64(p.GetFees()["USDT"]), 1e-9)
+	assert.Equal(t, types.SideTypeSell, p.GetSide())
+	assert.Equal(t, start.Add(4*time.Second), p.GetUpdatedAt())
+
+	p.AddFill(fill(5, types.SideTypeBuy, 95, 2, 0))
+	assert.Equal(t, items_types.QuantityType(0), p.GetQuantity())
+	assert.Equal(t, items_types.PriceType(0), p.GetBreakEvenPrice())
+	assert.Equal(t, items_types.ValueType(20), p.GetRealizedPnL())
+	assert.Equal(t, types.SideTypeNone, p.GetSide())
+}
+
+func TestPositionRebuild(t *testing.T) {
+	fills := history()
+	p := position_types.New("BTCUSDT", "BTC", "USDT", false, func(p *position_types.Position) types.InitFunction {
+		return func() error {
+			p.Rebuild([]position_types.Fill{fills[3], fills[1], fills[0]})
+			return nil
+		}
+	})
+	assert.NoError(t, p.Refresh())
+
+	// Виконання зі стріму, що прийшло пізніше за історію, вбудовується за часом
+	assert.True(t, p.AddFill(fills[2]))
+	assert.False(t, p.AddFill(fills[0]))
+	assert.Equal(t, items_types.QuantityType(-2), p.GetQuantity())
+	assert.Equal(t, items_types.ValueType(10), p.GetRealizedPnL())
+
+	// Повторне перебудування після перезапуску дає той самий результат
+	assert.NoError(t, p.Refresh())
+	assert.Equal(t, items_types.QuantityType(-2), p.GetQuantity())
+	assert.Equal(t, items_types.PriceType(100), p.GetEntryPrice())
+	assert.Equal(t, items_types.ValueType(10), p.GetRealizedPnL())
+
+	p.Reset()
+	assert.Equal(t, items_types.QuantityType(0), p.GetQuantity())
+	assert.Empty(t, p.GetFees())
+
+	assert.Error(t, position_types.New("BTCUSDT", "BTC", "USDT", false, nil).Refresh())
+}
+
+func TestPositionFeeInBase(t *testing.T) {
+	p := position_types.New("BTCUSDT", "BTC", "USDT", true, nil)
+	p.AddFill(position_types.Fill{
+		ID: 1, Side: types.SideTypeBuy, Price: 100, Quantity: 1,
+		Commission: 0.001, CommissionAsset: "BTC", Time: start,
+	})
+	p.AddFill(position_types.Fill{
+		ID: 2, Side: types.SideTypeBuy, Price: 100, Quantity: 1,
+		Commission: 0.05, CommissionAsset: "BNB", Time: start.Add(time.Second),
+	})
+	assert.InDelta(t, 1.999, float64(p.GetQuantity()), 1e-12)
+	assert.InDelta(t, 200/1.999, float64(p.GetBreakEvenPrice()), 1e-9)
+	assert.Equal(t, map[string]items_types.ValueType{"BTC": 0.001, "BNB": 0.05}, p.GetFees())
+}
+
+func TestPositionRebuildFrom(t *testing.T) {
+	fills := history()
+	p := position_types.New("BTCUSDT", "BTC", "USDT", false, nil)
+
+	// Вся історія від закритої позиції
+	opening, flat := p.Opening(-2, fills)
+	assert.Equal(t, items_types.QuantityType(0), opening)
+	assert.True(t, flat)
+
+	// Вікно історії починається посеред позиції, відкритої раніше покупкою 3
+	window := fills[1:]
+	opening, flat = p.Opening(-1, window)
+	assert.Equal(t, items_types.QuantityType(2), opening)
+	assert.False(t, flat)
+
+	p.RebuildFrom(-1, 0, window)
+	assert.Equal(t, items_types.QuantityType(-1), p.GetQuantity())
+	assert.Equal(t, items_types.PriceType(100), p.GetEntryPrice())
+	assert.Equal(t, items_types.ValueType(-10), p.GetRealizedPnL())
+
+	// Початкова позиція зберігається при додаванні старшого виконання зі стріму
+	assert.True(t, p.AddFill(fill(0, types.SideTypeSell, 90, 1, 0)))
+	assert.Equal(t, items_types.QuantityType(-2), p.GetQuantity())
+
+	p.Reset()
+	assert.Equal(t, items_types.QuantityType(0), p.GetQuantity())
+
+	// Комісія в базовому активі зменшує кількість і враховується при проході назад
+	spot := position_types.New("BTCUSDT", "BTC", "USDT", true, nil)
+	spot.RebuildFrom(0.999, 0, []position_types.Fill{{
+		ID: 1, Side: types.SideTypeBuy, Price: 100, Quantity: 1,
+		Commission: 0.001, CommissionAsset: "BTC", Time: start,
+	}})
+	opening, flat = spot.Opening(0.999, []position_types.Fill{{
+		ID: 1, Side: types.SideTypeBuy, Quantity: 1, Commission: 0.001, CommissionAsset: "BTC",
+	}})
+	assert.Equal(t, items_types.QuantityType(0), opening)
+	assert.True(t, flat)
+	assert.InDelta(t, 0.999, float64(spot.GetQuantity()), 1e-12)
+	assert.Equal(t, items_types.PriceType(100), spot.GetEntryPrice())
+}
@@ -0,0 +1,168 @@
+package processor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatJSON ConfigFormat = "json"
+	FormatYAML ConfigFormat = "yaml"
+)
+
+type (
+	ConfigFormat string
+)
+
+// LoadConfigs читає налаштування пар з файлу .json, .yaml або .yml.
+// Файл містить одну пару або список пар, невідомі поля вважаються помилкою.
+// Для кожної пари застосовуються змінні оточення envPrefix + символ + "_" + тег env,
+// наприклад PROCESSOR_BTCUSDT_LEVERAGE, порожній envPrefix вимикає оточення.
+// Значення за замовчуванням та перевірка застосовуються після оточення,
+// тому обов'язкові поля та секрети можна не записувати у файл.
+func LoadConfigs(path string, envPrefix string) (configs []Config, err error) {
+	var format ConfigFormat
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		format = FormatJSON
+	case ".yaml", ".yml":
+		format = FormatYAML
+	default:
+		return nil, fmt.Errorf("config %v: unknown format, use .json, .yaml or .yml", path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	if configs, err = decodeConfigs(file, format); err != nil {
+		return nil, fmt.Errorf("config %v: %w", path, err)
+	}
+	if envPrefix != "" {
+		for i := range configs {
+			symbol := normalizeSymbol(configs[i].Symbol)
+			if err = configs[i].ApplyEnv(envPrefix + symbol + "_"); err != nil {
+				return nil, fmt.Errorf("config %v, pair %v: %w", path, symbol, err)
+			}
+		}
+	}
+	if err = validateConfigs(configs); err != nil {
+		return nil, fmt.Errorf("config %v: %w", path, err)
+	}
+	return
+}
+
+// LoadConfigsFromEnv читає налаштування пар тільки зі змінних оточення.
+// Список пар задається через кому в envPrefix + "SYMBOLS", поля кожної пари - як у LoadConfigs,
+// наприклад PROCESSOR_SYMBOLS=BTCUSDT,ETHUSDT та PROCESSOR_BTCUSDT_LIMIT_ON_POSITION=1000.
+func LoadConfigsFromEnv(envPrefix string) (configs []Config, err error) {
+	name := envPrefix + "SYMBOLS"
+	for _, symbol := range strings.Split(os.Getenv(name), ",") {
+		if symbol = normalizeSymbol(symbol); symbol == "" {
+			continue
+		}
+		config := Config{Symbol: symbol}
+		if err = config.ApplyEnv(envPrefix + symbol + "_"); err != nil {
+			return nil, fmt.Errorf("pair %v: %w", symbol, err)
+		}
+		// Символ пари задається списком, а не змінною пари
+		config.Symbol = symbol
+		configs = append(configs, config)
+	}
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no pairs configured in %v", name)
+	}
+	if err = validateConfigs(configs); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// ReadConfigs читає одну пару або список пар у форматі format, заповнює значення за замовчуванням
+// та перевіряє кожну пару. Помилка вказує номер пари та поле.
+func ReadConfigs(r io.Reader, format ConfigFormat) (configs []Config, err error) {
+	if configs, err = decodeConfigs(r, format); err != nil {
+		return
+	}
+	if err = validateConfigs(configs); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func decodeConfigs(r io.Reader, format ConfigFormat) (configs []Config, err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+	switch format {
+	case FormatJSON:
+		configs, err = decodeJSON(data)
+	case FormatYAML:
+		configs, err = decodeYAML(data)
+	default:
+		err = fmt.Errorf("unknown config format %v", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(configs) == 0 {
+		return nil, errors.New("no pairs configured")
+	}
+	return
+}
+
+func validateConfigs(configs []Config) error {
+	var errs []error
+	for i := range configs {
+		configs[i].SetDefaults()
+		if err := configs[i].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("pair %v (%v): %w", i, configs[i].Symbol, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
+
+func decodeJSON(data []byte) (configs []Config, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = decoder.Decode(&configs)
+		return
+	}
+	var config Config
+	if err = decoder.Decode(&config); err != nil {
+		return
+	}
+	return []Config{config}, nil
+}
+
+func decodeYAML(data []byte) (configs []Config, err error) {
+	var node yaml.Node
+	if err = yaml.Unmarshal(data, &node); err != nil || len(node.Content) == 0 {
+		return
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if node.Content[0].Kind == yaml.SequenceNode {
+		err = decoder.Decode(&configs)
+		return
+	}
+	var config Config
+	if err = decoder.Decode(&config); err != nil {
+		return
+	}
+	return []Config{config}, nil
+}
@@ -9,6 +9,12 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	account_types "github.com/fr0ster/go-trading-utils/types/account"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	kline_types "github.com/fr0ster/go-trading-utils/types/klines"
+	margin_types "github.com/fr0ster/go-trading-utils/types/margin"
+	position_types "github.com/fr0ster/go-trading-utils/types/position"
+	sizer_types "github.com/fr0ster/go-trading-utils/types/sizer"
 	symbol_types "github.com/fr0ster/go-trading-utils/types/symbol"
 	utils "github.com/fr0ster/go-trading-utils/utils"
 )
@@ -83,19 +89,32 @@ func New(
 	// CallbackRate
 	pp.SetGetterCallbackRateFunction(getCallbackRate)
 
-	func() {
-		price := pp.GetCurrentPrice()
-		limitOfTransactionLoss := pp.GetLimitOnTransaction()
-		notional := pp.GetNotional()
+	err = pp.setup(len(debug) > 0 && debug[0])
 
-		if limitOfTransactionLoss < notional {
-			err = fmt.Errorf("limit on transaction %f with price %f isn't enough for open position, we need to have at least %f",
-				limitOfTransactionLoss, price, notional)
-			return
-		}
-	}()
+	// // Ініціалізуємо об'єкт
+	// if depthsCreator != nil {
+	// 	pp.depths = depthsCreator(pp)()
+	// }
+
+	// if ordersCreator != nil {
+	// 	pp.orders = ordersCreator(pp)()
+	// }
+
+	return
+}
 
-	if len(debug) == 0 || (len(debug) > 0 && !debug[0]) {
+// setup перевіряє ліміт на транзакцію та без debug встановлює плече і тип маржі на біржі
+func (pp *Processor) setup(debug bool) (err error) {
+	price := pp.GetCurrentPrice()
+	limitOfTransactionLoss := pp.GetLimitOnTransaction()
+	notional := pp.GetNotional()
+
+	if limitOfTransactionLoss < notional {
+		err = fmt.Errorf("limit on transaction %f with price %f isn't enough for open position, we need to have at least %f",
+			limitOfTransactionLoss, price, notional)
+	}
+
+	if !debug {
 		if pp.setLeverage != nil {
 			leverage, _, _, _ := pp.SetLeverage(pp.GetLeverage())
 			if leverage != pp.GetLeverage() {
@@ -108,16 +127,6 @@ func New(
 			_ = pp.SetMarginType(pp.GetMarginType()) // Встановлюємо тип маржі, як зміна не потрібна, помилку ігноруємо
 		}
 	}
-
-	// // Ініціалізуємо об'єкт
-	// if depthsCreator != nil {
-	// 	pp.depths = depthsCreator(pp)()
-	// }
-
-	// if ordersCreator != nil {
-	// 	pp.orders = ordersCreator(pp)()
-	// }
-
 	return
 }
 
@@ -191,6 +200,57 @@ func (pp *Processor) SetGetterPositionRiskFunction(function func(*Processor) Get
 		pp.getPositionRisk = function(pp)
 	}
 }
+
+// SetAccount встановлює кеш балансів та геттери балансів з нього,
+// загальний, вільний та заблокований баланс - за базовим символом пари, цільовий - за цільовим
+func (pp *Processor) SetAccount(account *account_types.Account) {
+	if account != nil {
+		pp.account = account
+		pp.getBaseBalance, pp.getTargetBalance, pp.getFreeBalance, pp.getLockedBalance =
+			AccountBalanceGetters(account, pp.symbolInfo)
+	}
+}
+
+// AccountBalanceGetters повертає геттери балансів пари symbolInfo з кешу account для New,
+// щоб ліміти в setup рахувались від балансу рахунку ще до SetAccount
+func AccountBalanceGetters(account *account_types.Account, symbolInfo *symbol_types.Symbol) (
+	getBaseBalance GetBaseBalanceFunction,
+	getTargetBalance GetTargetBalanceFunction,
+	getFreeBalance GetFreeBalanceFunction,
+	getLockedBalance GetLockedBalanceFunction) {
+	base := accountBalance(account, string(symbolInfo.GetBaseSymbol()))
+	target := accountBalance(account, string(symbolInfo.GetTargetSymbol()))
+	getBaseBalance = func() items_types.ValueType { return base().GetTotal() }
+	getTargetBalance = func() items_types.QuantityType { return items_types.QuantityType(target().GetTotal()) }
+	getFreeBalance = func() items_types.ValueType { return base().Free }
+	getLockedBalance = func() items_types.ValueType { return base().Locked }
+	return
+}
+func (pp *Processor) SetPosition(position *position_types.Position) {
+	if position != nil {
+		pp.position = position
+	}
+}
+func (pp *Processor) SetMarginCalculator(calculator *margin_types.Calculator) {
+	if calculator != nil {
+		pp.margin = calculator
+	}
+}
+func (pp *Processor) SetSizer(sizer sizer_types.Sizer) {
+	if sizer != nil {
+		pp.sizer = sizer
+	}
+}
+func (pp *Processor) SetKlines(klines *kline_types.Klines) {
+	if klines != nil {
+		pp.klines = klines
+	}
+}
+func (pp *Processor) SetGetterTradeStatsFunction(function GetTradeStatsFunction) {
+	if function != nil {
+		pp.getTradeStats = function
+	}
+}
 func (pp *Processor) SetGetterLeverageFunction(function GetLeverageFunction) {
 	if function != nil {
 		pp.getLeverage = function
@@ -3,6 +3,9 @@ package processor
 import (
 	"math"
 
+	"github.com/sirupsen/logrus"
+
+	account_types "github.com/fr0ster/go-trading-utils/types/account"
 	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
 	symbol_types "github.com/fr0ster/go-trading-utils/types/symbol"
 )
@@ -64,6 +67,12 @@ func (pp *Processor) GetCallbackRate() items_types.PricePercentType {
 	return pp.getCallbackRate()
 }
 
+// GetAccount повертає кеш балансів, nil якщо не встановлено.
+// Обробник стріму користувача підключається до нього, щоб виконання одразу змінювали баланси.
+func (pp *Processor) GetAccount() *account_types.Account {
+	return pp.account
+}
+
 func (pp *Processor) GetBaseBalance() items_types.ValueType {
 	if pp.getBaseBalance == nil {
 		return 0
@@ -95,3 +104,13 @@ func (pp *Processor) GetCurrentPrice() items_types.PriceType {
 	}
 	return pp.getCurrentPrice()
 }
+
+func accountBalance(account *account_types.Account, asset string) func() account_types.Balance {
+	return func() account_types.Balance {
+		balance, err := account.GetBalance(asset)
+		if err != nil {
+			logrus.Errorf("Can't get account: %v", err)
+		}
+		return balance
+	}
+}
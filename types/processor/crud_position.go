@@ -7,6 +7,7 @@ import (
 	"github.com/adshao/go-binance/v2/futures"
 	"github.com/fr0ster/go-trading-utils/types"
 	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	position_types "github.com/fr0ster/go-trading-utils/types/position"
 	utils "github.com/fr0ster/go-trading-utils/utils"
 )
 
@@ -20,6 +21,19 @@ func (pp *Processor) GetPositionRisk(debug ...*futures.PositionRisk) *futures.Po
 	return nil
 }
 
+// GetPosition повертає облік позиції за виконаннями, nil якщо не встановлено
+func (pp *Processor) GetPosition() *position_types.Position {
+	return pp.position
+}
+
+// GetUnrealizedPnL повертає нереалізований прибуток позиції за поточною ціною
+func (pp *Processor) GetUnrealizedPnL() items_types.ValueType {
+	if pp.position == nil {
+		return 0
+	}
+	return pp.position.GetUnrealizedPnL(pp.GetCurrentPrice())
+}
+
 func (pp *Processor) GetLiquidationDistance(price float64, debug ...*futures.PositionRisk) (distance float64) {
 	if risk := pp.GetPositionRisk(debug...); risk != nil {
 		return math.Abs((price - utils.ConvStrToFloat64(risk.LiquidationPrice)) / utils.ConvStrToFloat64(risk.LiquidationPrice))
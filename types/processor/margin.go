@@ -0,0 +1,80 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/futures"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	margin_types "github.com/fr0ster/go-trading-utils/types/margin"
+	utils "github.com/fr0ster/go-trading-utils/utils"
+)
+
+func (pp *Processor) GetMarginCalculator() *margin_types.Calculator {
+	return pp.margin
+}
+
+// GetMarginPosition повертає поточну позицію для калькулятора маржі з ризику позиції,
+// плече та тип маржі беруться з процесора, якщо ризику немає
+func (pp *Processor) GetMarginPosition(debug ...*futures.PositionRisk) (position margin_types.Position) {
+	position = margin_types.Position{
+		Symbol:     pp.GetSymbol(),
+		Leverage:   pp.GetLeverage(),
+		MarginType: pp.GetMarginType(),
+	}
+	if position.MarginType == "" {
+		position.MarginType = types.CrossMarginType
+	}
+	if risk := pp.GetPositionRisk(debug...); risk != nil {
+		position.Quantity = items_types.QuantityType(utils.ConvStrToFloat64(risk.PositionAmt))
+		position.EntryPrice = items_types.PriceType(utils.ConvStrToFloat64(risk.EntryPrice))
+		position.MarkPrice = items_types.PriceType(utils.ConvStrToFloat64(risk.MarkPrice))
+		position.IsolatedMargin = items_types.ValueType(utils.ConvStrToFloat64(risk.IsolatedWallet))
+		if leverage := int(utils.ConvStrToFloat64(risk.Leverage)); leverage > 0 {
+			position.Leverage = leverage
+		}
+		if strings.EqualFold(risk.MarginType, string(types.IsolatedMarginType)) {
+			position.MarginType = types.IsolatedMarginType
+		}
+	}
+	return
+}
+
+// CalcLiquidationPrice повертає ціну ліквідації позиції після виконання quantity на side за ціною price,
+// для крос маржі гаманцем вважається GetBaseBalance, інші позиції рахунку не враховуються
+func (pp *Processor) CalcLiquidationPrice(
+	side types.OrderSide,
+	quantity items_types.QuantityType,
+	price items_types.PriceType,
+	debug ...*futures.PositionRisk) (items_types.PriceType, error) {
+	if pp.margin == nil {
+		return 0, fmt.Errorf("margin calculator is not set")
+	}
+	position := pp.GetMarginPosition(debug...).Add(side, quantity, price)
+	return pp.margin.GetLiquidationPrice(position, pp.GetBaseBalance())
+}
+
+// CalcMaxSafeQuantity повертає найбільшу кількість на side за ціною price, округлену до кроку,
+// з якою ціна ліквідації буде не ближче minDistance відсотків від price.
+// Для ізольованої маржі нова маржа береться з GetFreeBalance, для крос - з GetBaseBalance.
+func (pp *Processor) CalcMaxSafeQuantity(
+	side types.OrderSide,
+	price items_types.PriceType,
+	minDistance items_types.PricePercentType,
+	debug ...*futures.PositionRisk) (items_types.QuantityType, error) {
+	if pp.margin == nil {
+		return 0, fmt.Errorf("margin calculator is not set")
+	}
+	position := pp.GetMarginPosition(debug...)
+	wallet := pp.GetBaseBalance()
+	if position.MarginType == types.IsolatedMarginType {
+		wallet = pp.GetFreeBalance()
+	}
+	quantity, err := pp.margin.GetMaxSafeQuantity(position, side, price, wallet, minDistance)
+	if err != nil {
+		return 0, err
+	}
+	return pp.FloorQuantity(quantity), nil
+}
@@ -0,0 +1,321 @@
+package processor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	account_types "github.com/fr0ster/go-trading-utils/types/account"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	kline_types "github.com/fr0ster/go-trading-utils/types/klines"
+	margin_types "github.com/fr0ster/go-trading-utils/types/margin"
+	position_types "github.com/fr0ster/go-trading-utils/types/position"
+	sizer_types "github.com/fr0ster/go-trading-utils/types/sizer"
+	symbol_types "github.com/fr0ster/go-trading-utils/types/symbol"
+)
+
+const (
+	defaultDegree = 3
+	maxLeverage   = 125
+)
+
+type (
+	// Config - декларативні налаштування Processor для однієї пари.
+	// Назви полів у файлах збігаються з тегами json/yaml, у змінних оточення - з тегом env після префікса.
+	Config struct {
+		Symbol string `json:"symbol" yaml:"symbol" env:"SYMBOL"`
+		// Ступінь B-дерев, 0 - за замовчуванням
+		Degree int `json:"degree,omitempty" yaml:"degree,omitempty" env:"DEGREE"`
+		// Ліміт на позицію в базовій валюті
+		LimitOnPosition items_types.ValueType `json:"limitOnPosition" yaml:"limitOnPosition" env:"LIMIT_ON_POSITION"`
+		// Ліміт на транзакцію у відсотках від ліміту на позицію
+		LimitOnTransaction items_types.ValuePercentType `json:"limitOnTransaction" yaml:"limitOnTransaction" env:"LIMIT_ON_TRANSACTION"`
+		// Межі ціни у відсотках, 0 - 100 / плече
+		UpAndLowBound items_types.PricePercentType    `json:"upAndLowBound,omitempty" yaml:"upAndLowBound,omitempty" env:"UP_AND_LOW_BOUND"`
+		DeltaPrice    items_types.PricePercentType    `json:"deltaPrice,omitempty" yaml:"deltaPrice,omitempty" env:"DELTA_PRICE"`
+		DeltaQuantity items_types.QuantityPercentType `json:"deltaQuantity,omitempty" yaml:"deltaQuantity,omitempty" env:"DELTA_QUANTITY"`
+		CallbackRate  items_types.PricePercentType    `json:"callbackRate,omitempty" yaml:"callbackRate,omitempty" env:"CALLBACK_RATE"`
+		// Плече та тип маржі тільки для ф'ючерсів, 0 та "" - не встановлювати
+		Leverage   int              `json:"leverage,omitempty" yaml:"leverage,omitempty" env:"LEVERAGE"`
+		MarginType types.MarginType `json:"marginType,omitempty" yaml:"marginType,omitempty" env:"MARGIN_TYPE"`
+		// Не змінювати плече та тип маржі на біржі
+		Debug bool `json:"debug,omitempty" yaml:"debug,omitempty" env:"DEBUG"`
+		// Модель розміру позиції та її параметри, "" - не використовувати
+		SizerType        sizer_types.Type             `json:"sizer,omitempty" yaml:"sizer,omitempty" env:"SIZER"`
+		SizerNotional    items_types.ValueType        `json:"sizerNotional,omitempty" yaml:"sizerNotional,omitempty" env:"SIZER_NOTIONAL"`
+		SizerRiskPercent items_types.ValuePercentType `json:"sizerRiskPercent,omitempty" yaml:"sizerRiskPercent,omitempty" env:"SIZER_RISK_PERCENT"`
+		ATRPeriod        int                          `json:"atrPeriod,omitempty" yaml:"atrPeriod,omitempty" env:"ATR_PERIOD"`
+		ATRMultiplier    float64                      `json:"atrMultiplier,omitempty" yaml:"atrMultiplier,omitempty" env:"ATR_MULTIPLIER"`
+		KellyFraction    float64                      `json:"kellyFraction,omitempty" yaml:"kellyFraction,omitempty" env:"KELLY_FRACTION"`
+	}
+	// ConfigError - помилка в конкретному полі Config
+	ConfigError struct {
+		Field  string
+		Value  interface{}
+		Reason string
+	}
+	// Option змінює Processor, створений NewFromConfig, до перевірки лімітів та налаштування біржі
+	Option func(pp *Processor)
+)
+
+var symbolPattern = regexp.MustCompile(`^[A-Z0-9]+$`)
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid %v %v: %v", e.Field, e.Value, e.Reason)
+}
+
+// SetDefaults заповнює незадані поля значеннями за замовчуванням
+func (c *Config) SetDefaults() {
+	if c.Degree == 0 {
+		c.Degree = defaultDegree
+	}
+	c.Symbol = normalizeSymbol(c.Symbol)
+	c.MarginType = types.MarginType(strings.ToUpper(string(c.MarginType)))
+	c.SizerType = sizer_types.Type(strings.ToUpper(strings.TrimSpace(string(c.SizerType))))
+	sizer := c.GetSizerConfig()
+	sizer.SetDefaults()
+	c.ATRPeriod, c.ATRMultiplier, c.KellyFraction = sizer.ATRPeriod, sizer.ATRMultiplier, sizer.KellyFraction
+}
+
+// GetSizerConfig повертає параметри моделі розміру позиції
+func (c *Config) GetSizerConfig() sizer_types.Config {
+	return sizer_types.Config{
+		Type:          c.SizerType,
+		Notional:      c.SizerNotional,
+		RiskPercent:   c.SizerRiskPercent,
+		ATRPeriod:     c.ATRPeriod,
+		ATRMultiplier: c.ATRMultiplier,
+		KellyFraction: c.KellyFraction,
+	}
+}
+
+// Validate перевіряє всі поля та повертає помилки ConfigError, об'єднані errors.Join
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(field string, value interface{}, reason string) {
+		errs = append(errs, &ConfigError{Field: field, Value: value, Reason: reason})
+	}
+	if !symbolPattern.MatchString(c.Symbol) {
+		fail("symbol", strconv.Quote(c.Symbol), "must be non-empty uppercase letters and digits")
+	}
+	if c.Degree < 2 {
+		fail("degree", c.Degree, "must be at least 2")
+	}
+	if c.LimitOnPosition <= 0 {
+		fail("limitOnPosition", c.LimitOnPosition, "must be positive")
+	}
+	if c.LimitOnTransaction <= 0 || c.LimitOnTransaction > 100 {
+		fail("limitOnTransaction", c.LimitOnTransaction, "must be in (0, 100] percent")
+	}
+	if c.UpAndLowBound < 0 || c.UpAndLowBound >= 100 {
+		fail("upAndLowBound", c.UpAndLowBound, "must be in [0, 100) percent")
+	}
+	if c.DeltaPrice < 0 || c.DeltaPrice >= 100 {
+		fail("deltaPrice", c.DeltaPrice, "must be in [0, 100) percent")
+	}
+	if c.DeltaQuantity < 0 {
+		fail("deltaQuantity", c.DeltaQuantity, "must not be negative")
+	}
+	if c.CallbackRate < 0 || c.CallbackRate > 5 {
+		fail("callbackRate", c.CallbackRate, "must be in [0, 5] percent")
+	}
+	if c.Leverage < 0 || c.Leverage > maxLeverage {
+		fail("leverage", c.Leverage, fmt.Sprintf("must be in [0, %v]", maxLeverage))
+	}
+	switch c.MarginType {
+	case "", types.CrossMarginType, types.IsolatedMarginType:
+	default:
+		fail("marginType", strconv.Quote(string(c.MarginType)),
+			fmt.Sprintf("must be %v or %v", types.CrossMarginType, types.IsolatedMarginType))
+	}
+	sizer := c.GetSizerConfig()
+	if err := sizer.Validate(); err != nil {
+		fail("sizer", strconv.Quote(string(c.SizerType)), err.Error())
+	}
+	return errors.Join(errs...)
+}
+
+// ApplyEnv перезаписує поля значеннями змінних оточення prefix + тег env, наприклад BTCUSDT_LEVERAGE
+func (c *Config) ApplyEnv(prefix string) error {
+	value := reflect.ValueOf(c).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := prefix + field.Tag.Get("env")
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+		target := value.Field(i)
+		switch target.Kind() {
+		case reflect.String:
+			target.SetString(raw)
+		case reflect.Int:
+			parsed, err := strconv.Atoi(raw)
+			if err != nil {
+				return &ConfigError{Field: name, Value: strconv.Quote(raw), Reason: "must be an integer"}
+			}
+			target.SetInt(int64(parsed))
+		case reflect.Float64:
+			parsed, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return &ConfigError{Field: name, Value: strconv.Quote(raw), Reason: "must be a number"}
+			}
+			target.SetFloat(parsed)
+		case reflect.Bool:
+			parsed, err := strconv.ParseBool(raw)
+			if err != nil {
+				return &ConfigError{Field: name, Value: strconv.Quote(raw), Reason: "must be true or false"}
+			}
+			target.SetBool(parsed)
+		}
+	}
+	return nil
+}
+
+// NewFromConfig створює Processor з config після SetDefaults та Validate.
+// Ліміти та відсотки з config стають геттерами, options можуть їх перевизначити.
+func NewFromConfig(config Config, symbolInfo *symbol_types.Symbol, options ...Option) (pp *Processor, err error) {
+	config.SetDefaults()
+	if err = config.Validate(); err != nil {
+		return nil, fmt.Errorf("processor %v config: %w", config.Symbol, err)
+	}
+	if symbolInfo == nil {
+		return nil, fmt.Errorf("processor %v: symbol info is nil", config.Symbol)
+	}
+	pp = &Processor{
+		symbolInfo: symbolInfo,
+		symbol:     config.Symbol,
+		stop:       make(chan struct{}),
+		degree:     config.Degree,
+		timeOut:    1 * time.Hour,
+	}
+	pp.SetGetterLimitOnPositionFunction(func() items_types.ValueType { return config.LimitOnPosition })
+	pp.SetGetterLimitOnTransactionFunction(func() items_types.ValuePercentType { return config.LimitOnTransaction })
+	pp.SetGetterUpAndLowBoundFunction(func() items_types.PricePercentType { return config.UpAndLowBound })
+	pp.SetGetterDeltaPriceFunction(func() items_types.PricePercentType { return config.DeltaPrice })
+	pp.SetGetterDeltaQuantityFunction(func() items_types.QuantityPercentType { return config.DeltaQuantity })
+	pp.SetGetterCallbackRateFunction(func() items_types.PricePercentType { return config.CallbackRate })
+	if config.Leverage > 0 {
+		pp.SetGetterLeverageFunction(func() int { return config.Leverage })
+	}
+	if config.MarginType != "" {
+		pp.SetGetterMarginTypeFunction(func() types.MarginType { return config.MarginType })
+	}
+	for _, option := range options {
+		option(pp)
+	}
+	if pp.sizer == nil && config.SizerType != "" {
+		if pp.sizer, err = sizer_types.New(config.GetSizerConfig(), pp.klines, pp.getTradeStats); err != nil {
+			return nil, fmt.Errorf("processor %v: %w", config.Symbol, err)
+		}
+	}
+	err = pp.setup(config.Debug)
+	return
+}
+
+func WithStop(stop chan struct{}) Option {
+	return func(pp *Processor) {
+		if stop != nil {
+			pp.stop = stop
+		}
+	}
+}
+
+func WithBaseBalance(function GetBaseBalanceFunction) Option {
+	return func(pp *Processor) { pp.SetGetterBaseBalanceFunction(function) }
+}
+
+func WithTargetBalance(function GetTargetBalanceFunction) Option {
+	return func(pp *Processor) { pp.SetGetterTargetBalanceFunction(function) }
+}
+
+func WithFreeBalance(function GetFreeBalanceFunction) Option {
+	return func(pp *Processor) { pp.SetGetterFreeBalanceFunction(function) }
+}
+
+func WithLockedBalance(function GetLockedBalanceFunction) Option {
+	return func(pp *Processor) { pp.SetGetterLockedBalanceFunction(function) }
+}
+
+// WithAccount встановлює кеш балансів замість створеного адаптером біржі, наприклад спільний для кількох пар
+func WithAccount(account *account_types.Account) Option {
+	return func(pp *Processor) { pp.SetAccount(account) }
+}
+
+func WithCurrentPrice(function GetCurrentPriceFunction) Option {
+	return func(pp *Processor) { pp.SetGetterCurrentPriceFunction(function) }
+}
+
+func WithPositionRisk(function func(*Processor) GetPositionRiskFunction) Option {
+	return func(pp *Processor) { pp.SetGetterPositionRiskFunction(function) }
+}
+
+func WithPosition(position *position_types.Position) Option {
+	return func(pp *Processor) { pp.SetPosition(position) }
+}
+
+func WithMarginCalculator(calculator *margin_types.Calculator) Option {
+	return func(pp *Processor) { pp.SetMarginCalculator(calculator) }
+}
+
+func WithSizer(sizer sizer_types.Sizer) Option {
+	return func(pp *Processor) { pp.SetSizer(sizer) }
+}
+
+func WithKlines(klines *kline_types.Klines) Option {
+	return func(pp *Processor) { pp.SetKlines(klines) }
+}
+
+func WithTradeStats(function GetTradeStatsFunction) Option {
+	return func(pp *Processor) { pp.SetGetterTradeStatsFunction(function) }
+}
+
+func WithLeverage(function GetLeverageFunction) Option {
+	return func(pp *Processor) { pp.SetGetterLeverageFunction(function) }
+}
+
+func WithSetLeverage(function func(*Processor) SetLeverageFunction) Option {
+	return func(pp *Processor) { pp.SetSetterLeverageFunction(function) }
+}
+
+func WithMarginType(function GetMarginTypeFunction) Option {
+	return func(pp *Processor) { pp.SetGetterMarginTypeFunction(function) }
+}
+
+func WithSetMarginType(function func(*Processor) SetMarginTypeFunction) Option {
+	return func(pp *Processor) { pp.SetSetterMarginTypeFunction(function) }
+}
+
+func WithSetPositionMargin(function func(*Processor) SetPositionMarginFunction) Option {
+	return func(pp *Processor) { pp.SetSetterPositionMarginFunction(function) }
+}
+
+func WithDeltaPrice(function GetDeltaPriceFunction) Option {
+	return func(pp *Processor) { pp.SetGetterDeltaPriceFunction(function) }
+}
+
+func WithDeltaQuantity(function GetDeltaQuantityFunction) Option {
+	return func(pp *Processor) { pp.SetGetterDeltaQuantityFunction(function) }
+}
+
+func WithLimitOnPosition(function GetLimitOnPositionFunction) Option {
+	return func(pp *Processor) { pp.SetGetterLimitOnPositionFunction(function) }
+}
+
+func WithLimitOnTransaction(function GetLimitOnTransactionFunction) Option {
+	return func(pp *Processor) { pp.SetGetterLimitOnTransactionFunction(function) }
+}
+
+func WithUpAndLowBound(function GetUpAndLowBoundFunction) Option {
+	return func(pp *Processor) { pp.SetGetterUpAndLowBoundFunction(function) }
+}
+
+func WithCallbackRate(function GetCallbackRateFunction) Option {
+	return func(pp *Processor) { pp.SetGetterCallbackRateFunction(function) }
+}
@@ -1,6 +1,10 @@
 package processor_test
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -8,13 +12,16 @@ import (
 
 	"github.com/fr0ster/go-trading-utils/types"
 
+	account_types "github.com/fr0ster/go-trading-utils/types/account"
 	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
 	asks_types "github.com/fr0ster/go-trading-utils/types/depths/asks"
 	bids_types "github.com/fr0ster/go-trading-utils/types/depths/bids"
 	depths_types "github.com/fr0ster/go-trading-utils/types/depths/depths"
 	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
 	exchange_types "github.com/fr0ster/go-trading-utils/types/exchangeinfo"
+	margin_types "github.com/fr0ster/go-trading-utils/types/margin"
 	processor "github.com/fr0ster/go-trading-utils/types/processor"
+	sizer_types "github.com/fr0ster/go-trading-utils/types/sizer"
 	symbol_types "github.com/fr0ster/go-trading-utils/types/symbol"
 	symbols_types "github.com/fr0ster/go-trading-utils/types/symbols"
 	utils "github.com/fr0ster/go-trading-utils/utils"
@@ -410,6 +417,20 @@ func TestRoundPrice(t *testing.T) {
 	for _, price := range prices {
 		assert.Equal(t, price.result, pp.RoundPrice(price.price))
 	}
+	assert.Equal(t, items_types.PriceType(67100.2), pp.CeilPrice(67100.11))
+	assert.Equal(t, items_types.QuantityType(0.29), pp.FloorQuantity(0.29))
+	assert.Equal(t, "67100.2", pp.FormatPrice(67100.15))
+	assert.Equal(t, "0.123", pp.FormatQuantity(0.1239))
+
+	// Великі значення, що з кроком не вміщуються в decimal, округлюються через float64 без паніки
+	small, err := getSpotProcessor(
+		"SHIBUSDT", "SHIB", "USDT", baseBalance, 1e12, 0.00001,
+		limitOnPosition, limitOnTransaction, upAndLowBound, 5, 0.00000001, 0.00000001)
+	assert.Nil(t, err)
+	assert.Equal(t, items_types.QuantityType(2e11), small.FloorQuantity(2e11))
+	assert.Equal(t, items_types.ValueType(2e11), small.RoundValue(2e11))
+	assert.Equal(t, "200000000000.00000000", small.FormatQuantity(2e11))
+	assert.Equal(t, "200000000000.00000000", small.FormatPrice(2e11))
 }
 
 func TestGetQuantityByUPnL(t *testing.T) {
@@ -578,3 +599,219 @@ func TestGetQuantityAndLoss(t *testing.T) {
 	loss := pp.PossibleLoss(quantity, items_types.DeltaPriceType(price*items_types.PriceType(percentLiquidation/100)))
 	assert.Equal(t, items_types.ValueType(5), loss)
 }
+
+func TestConfigValidate(t *testing.T) {
+	config := processor.Config{Symbol: " btcusdt", LimitOnPosition: 1000, LimitOnTransaction: 10, MarginType: "isolated"}
+	config.SetDefaults()
+	assert.Nil(t, config.Validate())
+	assert.Equal(t, "BTCUSDT", config.Symbol)
+	assert.Equal(t, 3, config.Degree)
+	assert.Equal(t, types.IsolatedMarginType, config.MarginType)
+
+	config.LimitOnTransaction = 150
+	config.Leverage = 200
+	err := config.Validate()
+	var configErr *processor.ConfigError
+	assert.True(t, errors.As(err, &configErr))
+	assert.Equal(t, "limitOnTransaction", configErr.Field)
+	assert.Contains(t, err.Error(), "invalid leverage 200")
+}
+
+func TestReadConfigs(t *testing.T) {
+	configs, err := processor.ReadConfigs(strings.NewReader(`
+- symbol: BTCUSDT
+  limitOnPosition: 1000
+  limitOnTransaction: 10
+  leverage: 20
+  marginType: CROSS
+- symbol: ETHUSDT
+  limitOnPosition: 500
+  limitOnTransaction: 20
+`), processor.FormatYAML)
+	assert.Nil(t, err)
+	assert.Len(t, configs, 2)
+	assert.Equal(t, 20, configs[0].Leverage)
+	assert.Equal(t, items_types.ValueType(500), configs[1].LimitOnPosition)
+
+	configs, err = processor.ReadConfigs(strings.NewReader(
+		`{"symbol": "BTCUSDT", "limitOnPosition": 1000, "limitOnTransaction": 10}`), processor.FormatJSON)
+	assert.Nil(t, err)
+	assert.Len(t, configs, 1)
+
+	// Помилка в назві поля не проходить мовчки
+	_, err = processor.ReadConfigs(strings.NewReader(
+		`{"symbol": "BTCUSDT", "limitOnPositon": 1000, "limitOnTransaction": 10}`), processor.FormatJSON)
+	assert.ErrorContains(t, err, "limitOnPositon")
+	_, err = processor.ReadConfigs(strings.NewReader(`[{"symbol": "BTCUSDT", "limitOnTransaction": 10}]`), processor.FormatJSON)
+	assert.ErrorContains(t, err, "pair 0 (BTCUSDT): invalid limitOnPosition 0")
+}
+
+func TestLoadConfigsWithEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pairs.yml")
+	assert.Nil(t, os.WriteFile(path, []byte("symbol: BTCUSDT\nlimitOnPosition: 1000\nlimitOnTransaction: 10\n"), 0o600))
+	t.Setenv("PROCESSOR_BTCUSDT_LEVERAGE", "15")
+	t.Setenv("PROCESSOR_BTCUSDT_DEBUG", "true")
+	configs, err := processor.LoadConfigs(path, "PROCESSOR_")
+	assert.Nil(t, err)
+	assert.Equal(t, 15, configs[0].Leverage)
+	assert.True(t, configs[0].Debug)
+
+	t.Setenv("PROCESSOR_BTCUSDT_LEVERAGE", "x")
+	_, err = processor.LoadConfigs(path, "PROCESSOR_")
+	assert.ErrorContains(t, err, "PROCESSOR_BTCUSDT_LEVERAGE")
+	_, err = processor.LoadConfigs(filepath.Join(t.TempDir(), "pairs.toml"), "")
+	assert.Error(t, err)
+
+	// Обов'язкове поле, якого немає у файлі, задається оточенням
+	assert.Nil(t, os.WriteFile(path, []byte("symbol: ethusdt\nlimitOnTransaction: 10\n"), 0o600))
+	_, err = processor.LoadConfigs(path, "")
+	assert.ErrorContains(t, err, "invalid limitOnPosition 0")
+	t.Setenv("PROCESSOR_ETHUSDT_LIMIT_ON_POSITION", "500")
+	configs, err = processor.LoadConfigs(path, "PROCESSOR_")
+	assert.Nil(t, err)
+	assert.Equal(t, "ETHUSDT", configs[0].Symbol)
+	assert.Equal(t, items_types.ValueType(500), configs[0].LimitOnPosition)
+}
+
+func TestLoadConfigsFromEnv(t *testing.T) {
+	_, err := processor.LoadConfigsFromEnv("PROCESSOR_")
+	assert.ErrorContains(t, err, "PROCESSOR_SYMBOLS")
+
+	t.Setenv("PROCESSOR_SYMBOLS", "btcusdt, ETHUSDT")
+	t.Setenv("PROCESSOR_BTCUSDT_LIMIT_ON_POSITION", "1000")
+	t.Setenv("PROCESSOR_BTCUSDT_LIMIT_ON_TRANSACTION", "10")
+	t.Setenv("PROCESSOR_BTCUSDT_LEVERAGE", "20")
+	t.Setenv("PROCESSOR_ETHUSDT_LIMIT_ON_TRANSACTION", "10")
+	_, err = processor.LoadConfigsFromEnv("PROCESSOR_")
+	assert.ErrorContains(t, err, "pair 1 (ETHUSDT): invalid limitOnPosition 0")
+
+	t.Setenv("PROCESSOR_ETHUSDT_LIMIT_ON_POSITION", "500")
+	configs, err := processor.LoadConfigsFromEnv("PROCESSOR_")
+	assert.Nil(t, err)
+	assert.Len(t, configs, 2)
+	assert.Equal(t, "BTCUSDT", configs[0].Symbol)
+	assert.Equal(t, 20, configs[0].Leverage)
+	assert.Equal(t, 3, configs[0].Degree)
+	assert.Equal(t, items_types.ValueType(500), configs[1].LimitOnPosition)
+}
+
+func TestNewFromConfig(t *testing.T) {
+	symbolInfo := symbol_types.New(
+		"BTCUSDT", 5, 0.001, 1000000, 0.001, 0.1, 100000, 100,
+		symbol_types.QuoteAsset("USDT"), symbol_types.BaseAsset("BTC"), false, nil, nil)
+	leverage := 0
+	pp, err := processor.NewFromConfig(
+		processor.Config{Symbol: "BTCUSDT", LimitOnPosition: 1000, LimitOnTransaction: 10, Leverage: 20, DeltaPrice: 1},
+		symbolInfo,
+		processor.WithFreeBalance(func() items_types.ValueType { return 2000 }),
+		processor.WithCurrentPrice(func() items_types.PriceType { return 60000 }),
+		processor.WithSetLeverage(func(pp *processor.Processor) processor.SetLeverageFunction {
+			return func(value int) (int, string, string, error) {
+				leverage = value
+				return value, "", pp.GetSymbol(), nil
+			}
+		}),
+		processor.WithDeltaPrice(func() items_types.PricePercentType { return 2 }))
+	assert.Nil(t, err)
+	assert.Equal(t, 20, leverage)
+	assert.Equal(t, items_types.ValueType(100), pp.GetLimitOnTransaction())
+	// Опція перевизначає значення з config
+	assert.Equal(t, items_types.PricePercentType(2), pp.GetDeltaPrice())
+
+	_, err = processor.NewFromConfig(processor.Config{Symbol: "BTCUSDT"}, symbolInfo)
+	assert.ErrorContains(t, err, "limitOnPosition")
+}
+
+func TestNewFromConfigWithAccount(t *testing.T) {
+	symbolInfo := symbol_types.New(
+		"BTCUSDT", 5, 0.001, 1000000, 0.001, 0.1, 100000, 100,
+		symbol_types.QuoteAsset("USDT"), symbol_types.BaseAsset("BTC"), false, nil, nil)
+	account := account_types.New(0, func(a *account_types.Account) types.InitFunction {
+		return func() error {
+			a.SetBalances([]account_types.Balance{{Asset: "USDT", Free: 1000, Locked: 200}, {Asset: "BTC", Free: 0.5}})
+			return nil
+		}
+	})
+	pp, err := processor.NewFromConfig(
+		processor.Config{Symbol: "BTCUSDT", LimitOnPosition: 1000, LimitOnTransaction: 10, Debug: true},
+		symbolInfo,
+		processor.WithAccount(account))
+	assert.Nil(t, err)
+	assert.Same(t, account, pp.GetAccount())
+	assert.Equal(t, items_types.ValueType(1200), pp.GetBaseBalance())
+	assert.Equal(t, items_types.QuantityType(0.5), pp.GetTargetBalance())
+	assert.Equal(t, items_types.ValueType(1000), pp.GetFreeBalance())
+	assert.Equal(t, items_types.ValueType(200), pp.GetLockedBalance())
+
+	// Подія стріму одразу видна в геттерах процесора
+	pp.GetAccount().UpdateBalance("USDT", 900, 300, time.Now())
+	assert.Equal(t, items_types.ValueType(900), pp.GetFreeBalance())
+	assert.Equal(t, items_types.ValueType(300), pp.GetLockedBalance())
+}
+
+func TestCalcLiquidationPrice(t *testing.T) {
+	symbolInfo := symbol_types.New(
+		"BTCUSDT", 5, 0.001, 1000000, 0.001, 0.1, 100000, 100,
+		symbol_types.QuoteAsset("USDT"), symbol_types.BaseAsset("BTC"), false, nil, nil)
+	calculator := margin_types.New(nil)
+	calculator.SetBrackets("BTCUSDT", margin_types.Brackets{
+		{Bracket: 1, InitialLeverage: 125, NotionalCap: 50000, MaintMarginRatio: 0.004},
+		{Bracket: 2, InitialLeverage: 100, NotionalFloor: 50000, NotionalCap: 250000, MaintMarginRatio: 0.005, Cum: 50},
+	})
+	pp, err := processor.NewFromConfig(
+		processor.Config{Symbol: "BTCUSDT", LimitOnPosition: 1000, LimitOnTransaction: 10, Leverage: 10, MarginType: "ISOLATED", Debug: true},
+		symbolInfo,
+		processor.WithBaseBalance(func() items_types.ValueType { return 5000 }),
+		processor.WithFreeBalance(func() items_types.ValueType { return 1000 }))
+	assert.Nil(t, err)
+	_, err = pp.CalcLiquidationPrice(types.SideTypeBuy, 1, 10000)
+	assert.Error(t, err)
+
+	pp.SetMarginCalculator(calculator)
+	risk := &futures.PositionRisk{PositionAmt: "0", MarginType: "isolated", Leverage: "10"}
+	price, err := pp.CalcLiquidationPrice(types.SideTypeBuy, 1, 10000, risk)
+	assert.Nil(t, err)
+	assert.InDelta(t, 9000/0.996, float64(price), 1e-6)
+
+	quantity, err := pp.CalcMaxSafeQuantity(types.SideTypeBuy, 10000, 5, risk)
+	assert.Nil(t, err)
+	assert.InDelta(t, 1, float64(quantity), 0.0011)
+}
+
+func TestCalcQuantityWithSizer(t *testing.T) {
+	symbolInfo := symbol_types.New(
+		"BTCUSDT", 100, 0.001, 10, 0.001, 0.1, 100000, 100,
+		symbol_types.QuoteAsset("USDT"), symbol_types.BaseAsset("BTC"), false, nil, nil)
+	config := processor.Config{
+		Symbol: "BTCUSDT", LimitOnPosition: 1000, LimitOnTransaction: 10,
+		SizerType: "max_loss", SizerRiskPercent: 1,
+	}
+	pp, err := processor.NewFromConfig(config, symbolInfo,
+		processor.WithBaseBalance(func() items_types.ValueType { return 10000 }),
+		processor.WithFreeBalance(func() items_types.ValueType { return 10000 }))
+	assert.Nil(t, err)
+	quantity, err := pp.CalcQuantity(types.SideTypeBuy, 50000, 49300)
+	assert.Nil(t, err)
+	assert.InDelta(t, 0.142, float64(quantity), 1e-12)
+	// Кількість менша за мінімальну пари
+	_, err = pp.CalcQuantity(types.SideTypeSell, 50000, 200000)
+	assert.ErrorIs(t, err, sizer_types.ErrTooSmall)
+
+	// Модель з опції має перевагу над config
+	pp, err = processor.NewFromConfig(config, symbolInfo,
+		processor.WithBaseBalance(func() items_types.ValueType { return 10000 }),
+		processor.WithFreeBalance(func() items_types.ValueType { return 10000 }),
+		processor.WithSizer(&sizer_types.FixedNotional{Notional: 1000}))
+	assert.Nil(t, err)
+	quantity, err = pp.CalcQuantity(types.SideTypeBuy, 50000, 0)
+	assert.Nil(t, err)
+	assert.InDelta(t, 0.02, float64(quantity), 1e-12)
+
+	config.SizerType = sizer_types.VolatilityType
+	_, err = processor.NewFromConfig(config, symbolInfo)
+	assert.ErrorContains(t, err, "klines")
+
+	config.SizerType = "unknown"
+	_, err = processor.NewFromConfig(config, symbolInfo)
+	assert.ErrorContains(t, err, "sizer")
+}
@@ -0,0 +1,45 @@
+package processor
+
+import (
+	"fmt"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	sizer_types "github.com/fr0ster/go-trading-utils/types/sizer"
+)
+
+func (pp *Processor) GetSizer() sizer_types.Sizer {
+	return pp.sizer
+}
+
+// GetSizerLimits повертає обмеження пари на кількість та номінал
+func (pp *Processor) GetSizerLimits() sizer_types.Limits {
+	return sizer_types.Limits{
+		MinQty:      pp.GetMinQty(),
+		MaxQty:      pp.GetMaxQty(),
+		StepSize:    pp.symbolInfo.GetStepSize(),
+		MinNotional: pp.GetNotional(),
+	}
+}
+
+// CalcQuantity рахує кількість для ордера на side за ціною price моделлю розміру позиції,
+// капіталом вважається GetBaseBalance, stopPrice потрібна для MAX_LOSS.
+// Результат округлено до кроку та обмежено мінімумами і максимумом пари.
+func (pp *Processor) CalcQuantity(
+	side types.OrderSide,
+	price items_types.PriceType,
+	stopPrice items_types.PriceType) (quantity items_types.QuantityType, err error) {
+	if pp.sizer == nil {
+		return 0, fmt.Errorf("sizer is not set")
+	}
+	quantity, err = pp.sizer.Size(sizer_types.Request{
+		Side:      side,
+		Price:     price,
+		StopPrice: stopPrice,
+		Equity:    pp.GetBaseBalance(),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return pp.GetSizerLimits().Apply(quantity, price)
+}
@@ -6,11 +6,16 @@ import (
 	"github.com/adshao/go-binance/v2/futures"
 
 	"github.com/fr0ster/go-trading-utils/types"
+	account_types "github.com/fr0ster/go-trading-utils/types/account"
 	depth_types "github.com/fr0ster/go-trading-utils/types/depths"
 	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
 
 	// exchange_types "github.com/fr0ster/go-trading-utils/types/exchangeinfo"
+	kline_types "github.com/fr0ster/go-trading-utils/types/klines"
+	margin_types "github.com/fr0ster/go-trading-utils/types/margin"
 	orders_types "github.com/fr0ster/go-trading-utils/types/orders"
+	position_types "github.com/fr0ster/go-trading-utils/types/position"
+	sizer_types "github.com/fr0ster/go-trading-utils/types/sizer"
 	symbol_types "github.com/fr0ster/go-trading-utils/types/symbol"
 )
 
@@ -42,7 +47,9 @@ type (
 	GetUpAndLowBoundFunction func() items_types.PricePercentType
 
 	GetCallbackRateFunction func() items_types.PricePercentType
-	Processor               struct {
+
+	GetTradeStatsFunction func() sizer_types.Stats
+	Processor             struct {
 		// Налаштування та обмеження, реалізація
 		orderTypes map[futures.OrderType]bool
 		degree     int
@@ -61,8 +68,18 @@ type (
 		getFreeBalance   GetFreeBalanceFunction
 		getLockedBalance GetLockedBalanceFunction
 		getCurrentPrice  GetCurrentPriceFunction
+		// Кеш балансів, з якого читають геттери балансів
+		account *account_types.Account
 
 		getPositionRisk GetPositionRiskFunction
+		// Облік позиції за виконаннями ордерів
+		position *position_types.Position
+		// Калькулятор ліквідації за рівнями номіналу
+		margin *margin_types.Calculator
+		// Модель розміру позиції та дані для неї
+		sizer         sizer_types.Sizer
+		klines        *kline_types.Klines
+		getTradeStats GetTradeStatsFunction
 
 		getLeverage GetLeverageFunction
 		setLeverage SetLeverageFunction
@@ -2,69 +2,81 @@ package processor
 
 import (
 	"math"
+	"strconv"
 
 	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	"github.com/fr0ster/go-trading-utils/utils/decimal"
 )
 
-func (pp *Processor) CeilValue(value items_types.ValueType) items_types.ValueType {
-	coefficient := math.Pow10(pp.GetTickSizeExp())
-	step := math.Ceil(float64(value) * coefficient)
+// Округлення виконується в десятковій арифметиці, щоб 0.29 з кроком 0.01 лишалось 0.29, а не 0.28
 
-	return items_types.ValueType(float64(step) / coefficient)
+func (pp *Processor) CeilValue(value items_types.ValueType) items_types.ValueType {
+	return items_types.ValueType(items_types.RoundToStep(float64(value), pp.valueStep(), decimal.RoundCeil))
 }
 
 func (pp *Processor) FloorValue(value items_types.ValueType) items_types.ValueType {
-	coefficient := math.Pow10(pp.GetTickSizeExp())
-	step := math.Floor(float64(value) * coefficient)
-
-	return items_types.ValueType(float64(step) / coefficient)
+	return items_types.ValueType(items_types.RoundToStep(float64(value), pp.valueStep(), decimal.RoundFloor))
 }
 
 func (pp *Processor) RoundValue(value items_types.ValueType) items_types.ValueType {
-	coefficient := math.Pow10(pp.GetTickSizeExp())
-	step := math.Round(float64(value) * coefficient)
-
-	return items_types.ValueType(float64(step) / coefficient)
+	return items_types.ValueType(items_types.RoundToStep(float64(value), pp.valueStep(), decimal.RoundHalfUp))
 }
 
 func (pp *Processor) CeilPrice(price items_types.PriceType) items_types.PriceType {
-	coefficient := math.Pow10(pp.GetTickSizeExp())
-	step := math.Ceil(float64(price) * coefficient)
-
-	return items_types.PriceType(float64(step) / coefficient)
+	return items_types.PriceType(items_types.RoundToStep(float64(price), pp.GetTickSizeDecimal(), decimal.RoundCeil))
 }
 
 func (pp *Processor) FloorPrice(price items_types.PriceType) items_types.PriceType {
-	coefficient := math.Pow10(pp.GetTickSizeExp())
-	step := math.Floor(float64(price) * coefficient)
-
-	return items_types.PriceType(float64(step) / coefficient)
+	return items_types.PriceType(items_types.RoundToStep(float64(price), pp.GetTickSizeDecimal(), decimal.RoundFloor))
 }
 
 func (pp *Processor) RoundPrice(price items_types.PriceType) items_types.PriceType {
-	coefficient := math.Pow10(pp.GetTickSizeExp())
-	step := math.Round(float64(price) * coefficient)
-
-	return items_types.PriceType(float64(step) / coefficient)
+	return items_types.PriceType(items_types.RoundToStep(float64(price), pp.GetTickSizeDecimal(), decimal.RoundHalfUp))
 }
 
 func (pp *Processor) CeilQuantity(quantity items_types.QuantityType) items_types.QuantityType {
-	coefficient := math.Pow10(pp.GetStepSizeExp())
-	step := math.Ceil(float64(quantity) * coefficient)
-
-	return items_types.QuantityType(float64(step) / coefficient)
+	return items_types.QuantityType(items_types.RoundToStep(float64(quantity), pp.GetStepSizeDecimal(), decimal.RoundCeil))
 }
 
 func (pp *Processor) FloorQuantity(quantity items_types.QuantityType) items_types.QuantityType {
-	coefficient := math.Pow10(pp.GetStepSizeExp())
-	step := math.Floor(float64(quantity) * coefficient)
-
-	return items_types.QuantityType(float64(step) / coefficient)
+	return items_types.QuantityType(items_types.RoundToStep(float64(quantity), pp.GetStepSizeDecimal(), decimal.RoundFloor))
 }
 
 func (pp *Processor) RoundQuantity(quantity items_types.QuantityType) items_types.QuantityType {
-	coefficient := math.Pow10(pp.GetStepSizeExp())
-	step := math.Round(float64(quantity) * coefficient)
+	return items_types.QuantityType(items_types.RoundToStep(float64(quantity), pp.GetStepSizeDecimal(), decimal.RoundHalfUp))
+}
+
+// GetTickSizeDecimal повертає tickSize як точне десяткове число
+func (pp *Processor) GetTickSizeDecimal() decimal.Decimal {
+	return pp.symbolInfo.GetTickSize().Decimal()
+}
+
+// GetStepSizeDecimal повертає stepSize як точне десяткове число
+func (pp *Processor) GetStepSizeDecimal() decimal.Decimal {
+	return pp.symbolInfo.GetStepSize().Decimal()
+}
+
+// FormatPrice повертає ціну, округлену до tickSize, рядком для запиту до біржі
+func (pp *Processor) FormatPrice(price items_types.PriceType) string {
+	return formatToStep(float64(price), pp.GetTickSizeDecimal(), decimal.RoundHalfUp)
+}
+
+// FormatQuantity повертає кількість, округлену вниз до stepSize, рядком для запиту до біржі
+func (pp *Processor) FormatQuantity(quantity items_types.QuantityType) string {
+	return formatToStep(float64(quantity), pp.GetStepSizeDecimal(), decimal.RoundFloor)
+}
+
+// Вартість округлюється до тієї ж кількості знаків, що й ціна
+func (pp *Processor) valueStep() decimal.Decimal {
+	step, _ := decimal.NewFromFloat(math.Pow10(-pp.GetTickSizeExp()))
+	return step
+}
 
-	return items_types.QuantityType(float64(step) / coefficient)
+func formatToStep(value float64, step decimal.Decimal, mode decimal.RoundingMode) string {
+	if d, err := decimal.NewFromFloat(value); err == nil {
+		if d, err = d.TryRoundToStep(step, mode); err == nil {
+			return d.String()
+		}
+	}
+	return strconv.FormatFloat(items_types.RoundToStep(value, step, mode), 'f', int(step.Scale()), 64)
 }
@@ -0,0 +1,155 @@
+package sizer
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	kline_types "github.com/fr0ster/go-trading-utils/types/klines"
+	"github.com/fr0ster/go-trading-utils/utils/decimal"
+)
+
+const (
+	FixedNotionalType   Type = "FIXED_NOTIONAL"
+	FixedFractionalType Type = "FIXED_FRACTIONAL"
+	VolatilityType      Type = "VOLATILITY"
+	KellyType           Type = "KELLY"
+	MaxLossType         Type = "MAX_LOSS"
+
+	DefaultATRPeriod     = 14
+	DefaultKellyFraction = 0.5
+)
+
+var ErrTooSmall = errors.New("quantity is below symbol limits")
+
+type (
+	Type string
+	// Request - дані для розрахунку розміру позиції.
+	// Equity - капітал, від якого рахується ризик, StopPrice потрібна тільки для MAX_LOSS.
+	Request struct {
+		Side      types.OrderSide
+		Price     items_types.PriceType
+		StopPrice items_types.PriceType
+		Equity    items_types.ValueType
+	}
+	// Sizer повертає кількість без округлення, обмеження пари застосовує Limits
+	Sizer interface {
+		Size(request Request) (items_types.QuantityType, error)
+	}
+	// Limits - обмеження пари на кількість та номінал ордера
+	Limits struct {
+		MinQty      items_types.QuantityType
+		MaxQty      items_types.QuantityType
+		StepSize    items_types.QuantityType
+		MinNotional items_types.ValueType
+	}
+	// Config - вибір та параметри моделі розміру позиції
+	Config struct {
+		Type Type
+		// Номінал для FIXED_NOTIONAL
+		Notional items_types.ValueType
+		// Частка капіталу для FIXED_FRACTIONAL, ризик на угоду для VOLATILITY та MAX_LOSS, межа для KELLY
+		RiskPercent items_types.ValuePercentType
+		// Період ATR та відстань стопу в ATR для VOLATILITY
+		ATRPeriod     int
+		ATRMultiplier float64
+		// Частка від повного критерію Келлі
+		KellyFraction float64
+	}
+)
+
+// Apply округлює кількість вниз до кроку та обмежує максимумом.
+// Кількість менша за мінімальну або з номіналом меншим за мінімальний повертає ErrTooSmall.
+func (l Limits) Apply(quantity items_types.QuantityType, price items_types.PriceType) (items_types.QuantityType, error) {
+	if l.MaxQty > 0 && quantity > l.MaxQty {
+		quantity = l.MaxQty
+	}
+	if l.StepSize > 0 {
+		// Округлення в decimal, як Processor.FloorQuantity, щоб кількість збігалась з ордером
+		quantity = items_types.QuantityType(items_types.RoundToStep(float64(quantity), l.StepSize.Decimal(), decimal.RoundFloor))
+	}
+	if quantity <= 0 || quantity < l.MinQty {
+		return 0, fmt.Errorf("%w: quantity %v, min quantity %v", ErrTooSmall, quantity, l.MinQty)
+	}
+	if notional := items_types.ValueType(quantity) * items_types.ValueType(price); notional < l.MinNotional {
+		return 0, fmt.Errorf("%w: notional %v, min notional %v", ErrTooSmall, notional, l.MinNotional)
+	}
+	return quantity, nil
+}
+
+// SetDefaults заповнює незадані параметри значеннями за замовчуванням
+func (c *Config) SetDefaults() {
+	switch c.Type {
+	case VolatilityType:
+		if c.ATRPeriod == 0 {
+			c.ATRPeriod = DefaultATRPeriod
+		}
+		if c.ATRMultiplier == 0 {
+			c.ATRMultiplier = 1
+		}
+	case KellyType:
+		if c.KellyFraction == 0 {
+			c.KellyFraction = DefaultKellyFraction
+		}
+	}
+}
+
+// Validate перевіряє параметри обраної моделі, порожній Type - модель не задана
+func (c *Config) Validate() error {
+	switch c.Type {
+	case "":
+		return nil
+	case FixedNotionalType:
+		if c.Notional <= 0 {
+			return fmt.Errorf("notional %v must be positive", c.Notional)
+		}
+		return nil
+	case FixedFractionalType, VolatilityType, KellyType, MaxLossType:
+	default:
+		return fmt.Errorf("unknown type %v, use %v, %v, %v, %v or %v",
+			c.Type, FixedNotionalType, FixedFractionalType, VolatilityType, KellyType, MaxLossType)
+	}
+	if c.RiskPercent <= 0 || c.RiskPercent > 100 {
+		return fmt.Errorf("risk percent %v must be in (0, 100]", c.RiskPercent)
+	}
+	if c.Type == VolatilityType && (c.ATRPeriod < 1 || c.ATRMultiplier <= 0) {
+		return fmt.Errorf("ATR period %v and multiplier %v must be positive", c.ATRPeriod, c.ATRMultiplier)
+	}
+	if c.Type == KellyType && (c.KellyFraction <= 0 || c.KellyFraction > 1) {
+		return fmt.Errorf("kelly fraction %v must be in (0, 1]", c.KellyFraction)
+	}
+	return nil
+}
+
+// New створює модель з config. VOLATILITY потребує klines, KELLY - stats, для інших моделей вони можуть бути nil.
+func New(config Config, klines *kline_types.Klines, stats func() Stats) (Sizer, error) {
+	config.SetDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	switch config.Type {
+	case FixedNotionalType:
+		return &FixedNotional{Notional: config.Notional}, nil
+	case FixedFractionalType:
+		return &FixedFractional{Percent: config.RiskPercent}, nil
+	case VolatilityType:
+		if klines == nil {
+			return nil, fmt.Errorf("sizer %v: klines are not set", config.Type)
+		}
+		return &Volatility{
+			ATR:         KlinesATR(klines, config.ATRPeriod),
+			RiskPercent: config.RiskPercent,
+			Multiplier:  config.ATRMultiplier,
+		}, nil
+	case KellyType:
+		if stats == nil {
+			return nil, fmt.Errorf("sizer %v: trade stats are not set", config.Type)
+		}
+		return &Kelly{Stats: stats, Fraction: config.KellyFraction, MaxPercent: config.RiskPercent}, nil
+	case MaxLossType:
+		return &MaxLoss{RiskPercent: config.RiskPercent}, nil
+	default:
+		return nil, errors.New("sizer type is not set")
+	}
+}
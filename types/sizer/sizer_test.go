@@ -0,0 +1,127 @@
+package sizer_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr0ster/go-trading-utils/types"
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	kline_types "github.com/fr0ster/go-trading-utils/types/klines"
+	sizer_types "github.com/fr0ster/go-trading-utils/types/sizer"
+)
+
+var (
+	limits  = sizer_types.Limits{MinQty: 0.001, MaxQty: 10, StepSize: 0.001, MinNotional: 100}
+	request = sizer_types.Request{Side: types.SideTypeBuy, Price: 50000, Equity: 10000}
+)
+
+func size(t *testing.T, sizer sizer_types.Sizer, request sizer_types.Request) items_types.QuantityType {
+	quantity, err := sizer.Size(request)
+	assert.NoError(t, err)
+	quantity, err = limits.Apply(quantity, request.Price)
+	assert.NoError(t, err)
+	return quantity
+}
+
+func TestSizers(t *testing.T) {
+	assert.InDelta(t, 0.02, float64(size(t, &sizer_types.FixedNotional{Notional: 1000}, request)), 1e-12)
+	assert.InDelta(t, 0.02, float64(size(t, &sizer_types.FixedFractional{Percent: 10}, request)), 1e-12)
+
+	volatility := &sizer_types.Volatility{
+		ATR:         func() items_types.PriceType { return 500 },
+		RiskPercent: 1,
+		Multiplier:  2,
+	}
+	assert.InDelta(t, 0.1, float64(size(t, volatility, request)), 1e-12)
+
+	stats := sizer_types.StatsFromPnL(30, 30, -10, 0, 30, -20)
+	assert.Equal(t, sizer_types.Stats{Wins: 3, Losses: 2, AvgWin: 30, AvgLoss: 15}, stats)
+	assert.InDelta(t, 0.4, stats.Kelly(), 1e-12)
+	kelly := &sizer_types.Kelly{Stats: func() sizer_types.Stats { return stats }, Fraction: 0.5, MaxPercent: 25}
+	assert.InDelta(t, 0.04, float64(size(t, kelly, request)), 1e-12)
+	kelly.MaxPercent = 10
+	assert.InDelta(t, 0.02, float64(size(t, kelly, request)), 1e-12)
+	kelly.Stats = func() sizer_types.Stats { return sizer_types.Stats{Wins: 1, Losses: 3, AvgWin: 10, AvgLoss: 10} }
+	_, err := kelly.Size(request)
+	assert.Error(t, err)
+
+	withStop := request
+	withStop.StopPrice = 49000
+	assert.InDelta(t, 0.1, float64(size(t, &sizer_types.MaxLoss{RiskPercent: 1}, withStop)), 1e-12)
+	_, err = (&sizer_types.MaxLoss{RiskPercent: 1}).Size(request)
+	assert.Error(t, err)
+
+	_, err = (&sizer_types.FixedNotional{Notional: 1000}).Size(sizer_types.Request{})
+	assert.Error(t, err)
+}
+
+func TestLimits(t *testing.T) {
+	quantity, err := limits.Apply(0.0234567, 50000)
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.QuantityType(0.023), quantity)
+	quantity, err = limits.Apply(0.3, 50000)
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.QuantityType(0.3), quantity)
+	// Кількість округлюється в decimal без артефактів float64, як в Processor.FloorQuantity
+	quantity, err = limits.Apply(0.1+0.2, 50000)
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.QuantityType(0.3), quantity)
+	quantity, err = (sizer_types.Limits{StepSize: 0.1}).Apply(0.7, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.QuantityType(0.7), quantity)
+	quantity, err = limits.Apply(20, 50000)
+	assert.NoError(t, err)
+	assert.Equal(t, items_types.QuantityType(10), quantity)
+
+	_, err = limits.Apply(0.001, 50000)
+	assert.True(t, errors.Is(err, sizer_types.ErrTooSmall))
+	_, err = limits.Apply(0.0005, 500000)
+	assert.True(t, errors.Is(err, sizer_types.ErrTooSmall))
+}
+
+func TestATR(t *testing.T) {
+	klines := kline_types.New(make(chan struct{}), 3, kline_types.KlineStreamInterval("1m"), "BTCUSDT", nil, nil)
+	for i, bar := range [][3]string{{"11", "9", "10"}, {"12", "10", "11"}, {"11.5", "9.5", "10"}, {"14", "12", "13"}} {
+		klines.SetKline(&kline_types.Kline{
+			OpenTime:  int64(i) * 60000,
+			CloseTime: int64(i)*60000 + 59999,
+			High:      bar[0],
+			Low:       bar[1],
+			Close:     bar[2],
+		})
+	}
+	assert.InDelta(t, 8.0/3, float64(sizer_types.ATR(klines, 3)), 1e-12)
+	assert.Equal(t, items_types.PriceType(0), sizer_types.ATR(klines, 4))
+
+	sizer, err := sizer_types.New(sizer_types.Config{Type: sizer_types.VolatilityType, RiskPercent: 1, ATRPeriod: 3}, klines, nil)
+	assert.NoError(t, err)
+	quantity, err := sizer.Size(request)
+	assert.NoError(t, err)
+	assert.InDelta(t, 100/(8.0/3), float64(quantity), 1e-9)
+}
+
+func TestNew(t *testing.T) {
+	sizer, err := sizer_types.New(sizer_types.Config{Type: sizer_types.FixedNotionalType, Notional: 1000}, nil, nil)
+	assert.NoError(t, err)
+	assert.IsType(t, &sizer_types.FixedNotional{}, sizer)
+
+	sizer, err = sizer_types.New(sizer_types.Config{Type: sizer_types.KellyType, RiskPercent: 10},
+		nil, func() sizer_types.Stats { return sizer_types.Stats{} })
+	assert.NoError(t, err)
+	assert.Equal(t, sizer_types.DefaultKellyFraction, sizer.(*sizer_types.Kelly).Fraction)
+
+	for _, config := range []sizer_types.Config{
+		{},
+		{Type: "MARTINGALE"},
+		{Type: sizer_types.FixedNotionalType},
+		{Type: sizer_types.MaxLossType, RiskPercent: 150},
+		{Type: sizer_types.KellyType, RiskPercent: 10, KellyFraction: 2},
+		{Type: sizer_types.VolatilityType, RiskPercent: 1},
+		{Type: sizer_types.KellyType, RiskPercent: 10},
+	} {
+		_, err = sizer_types.New(config, nil, nil)
+		assert.Error(t, err, config.Type)
+	}
+}
@@ -0,0 +1,173 @@
+package sizer
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/google/btree"
+
+	items_types "github.com/fr0ster/go-trading-utils/types/depths/items"
+	kline_types "github.com/fr0ster/go-trading-utils/types/klines"
+	"github.com/fr0ster/go-trading-utils/utils"
+)
+
+type (
+	// FixedNotional - однаковий номінал на кожну угоду
+	FixedNotional struct {
+		Notional items_types.ValueType
+	}
+	// FixedFractional - фіксована частка капіталу
+	FixedFractional struct {
+		Percent items_types.ValuePercentType
+	}
+	// Volatility - ризик RiskPercent капіталу на стоп у Multiplier * ATR
+	Volatility struct {
+		ATR         func() items_types.PriceType
+		RiskPercent items_types.ValuePercentType
+		Multiplier  float64
+	}
+	// Kelly - частка Fraction від критерію Келлі за статистикою угод, не більше MaxPercent капіталу
+	Kelly struct {
+		Stats      func() Stats
+		Fraction   float64
+		MaxPercent items_types.ValuePercentType
+	}
+	// MaxLoss - збиток при спрацюванні стопу не більше RiskPercent капіталу
+	MaxLoss struct {
+		RiskPercent items_types.ValuePercentType
+	}
+	// Stats - статистика закритих угод для критерію Келлі, AvgLoss додатний
+	Stats struct {
+		Wins    int
+		Losses  int
+		AvgWin  items_types.ValueType
+		AvgLoss items_types.ValueType
+	}
+)
+
+func (s *FixedNotional) Size(request Request) (items_types.QuantityType, error) {
+	if err := checkPrice(request.Price); err != nil {
+		return 0, err
+	}
+	return items_types.QuantityType(s.Notional) / items_types.QuantityType(request.Price), nil
+}
+
+func (s *FixedFractional) Size(request Request) (items_types.QuantityType, error) {
+	if err := checkPrice(request.Price); err != nil {
+		return 0, err
+	}
+	return fraction(request, s.Percent), nil
+}
+
+func (s *Volatility) Size(request Request) (items_types.QuantityType, error) {
+	if err := checkPrice(request.Price); err != nil {
+		return 0, err
+	}
+	atr := s.ATR()
+	if atr <= 0 {
+		return 0, fmt.Errorf("ATR %v must be positive", atr)
+	}
+	risk := float64(request.Equity) * float64(s.RiskPercent) / 100
+	return items_types.QuantityType(risk / (float64(atr) * s.Multiplier)), nil
+}
+
+func (s *Kelly) Size(request Request) (items_types.QuantityType, error) {
+	if err := checkPrice(request.Price); err != nil {
+		return 0, err
+	}
+	percent := s.Stats().Kelly() * s.Fraction * 100
+	if percent <= 0 {
+		return 0, fmt.Errorf("kelly fraction %v has no edge", percent/100)
+	}
+	if s.MaxPercent > 0 {
+		percent = math.Min(percent, float64(s.MaxPercent))
+	}
+	return fraction(request, items_types.ValuePercentType(percent)), nil
+}
+
+func (s *MaxLoss) Size(request Request) (items_types.QuantityType, error) {
+	if err := checkPrice(request.Price); err != nil {
+		return 0, err
+	}
+	distance := math.Abs(float64(request.Price - request.StopPrice))
+	if request.StopPrice <= 0 || distance == 0 {
+		return 0, fmt.Errorf("stop price %v must be positive and differ from price %v", request.StopPrice, request.Price)
+	}
+	risk := float64(request.Equity) * float64(s.RiskPercent) / 100
+	return items_types.QuantityType(risk / distance), nil
+}
+
+// Kelly повертає частку капіталу W - (1 - W) / R, де W - частка прибуткових угод, R - AvgWin / AvgLoss
+func (s Stats) Kelly() float64 {
+	total := s.Wins + s.Losses
+	if total == 0 || s.AvgWin <= 0 {
+		return 0
+	}
+	win := float64(s.Wins) / float64(total)
+	if s.AvgLoss <= 0 {
+		return win
+	}
+	return win - (1-win)/float64(s.AvgWin/s.AvgLoss)
+}
+
+// StatsFromPnL рахує статистику за прибутком закритих угод, нульові угоди пропускаються
+func StatsFromPnL(pnls ...items_types.ValueType) (stats Stats) {
+	var wins, losses items_types.ValueType
+	for _, pnl := range pnls {
+		switch {
+		case pnl > 0:
+			stats.Wins++
+			wins += pnl
+		case pnl < 0:
+			stats.Losses++
+			losses -= pnl
+		}
+	}
+	if stats.Wins > 0 {
+		stats.AvgWin = wins / items_types.ValueType(stats.Wins)
+	}
+	if stats.Losses > 0 {
+		stats.AvgLoss = losses / items_types.ValueType(stats.Losses)
+	}
+	return
+}
+
+// ATR повертає середній істинний діапазон за останні period свічок, 0 якщо свічок недостатньо
+func ATR(klines *kline_types.Klines, period int) items_types.PriceType {
+	var highs, lows, closes []float64
+	klines.Lock()
+	klines.Ascend(func(i btree.Item) bool {
+		kline := i.(*kline_types.Kline)
+		highs = append(highs, utils.ConvStrToFloat64(kline.High))
+		lows = append(lows, utils.ConvStrToFloat64(kline.Low))
+		closes = append(closes, utils.ConvStrToFloat64(kline.Close))
+		return true
+	})
+	klines.Unlock()
+	if period < 1 || len(closes) < period+1 {
+		return 0
+	}
+	var sum float64
+	for i := len(closes) - period; i < len(closes); i++ {
+		sum += math.Max(highs[i]-lows[i], math.Max(math.Abs(highs[i]-closes[i-1]), math.Abs(lows[i]-closes[i-1])))
+	}
+	return items_types.PriceType(sum / float64(period))
+}
+
+// KlinesATR повертає функцію ATR для Volatility, яка читає свічки на момент виклику
+func KlinesATR(klines *kline_types.Klines, period int) func() items_types.PriceType {
+	return func() items_types.PriceType {
+		return ATR(klines, period)
+	}
+}
+
+func fraction(request Request, percent items_types.ValuePercentType) items_types.QuantityType {
+	return items_types.QuantityType(float64(request.Equity) * float64(percent) / 100 / float64(request.Price))
+}
+
+func checkPrice(price items_types.PriceType) error {
+	if price <= 0 {
+		return fmt.Errorf("price %v must be positive", price)
+	}
+	return nil
+}
@@ -9,7 +9,9 @@ import (
 )
 
 type (
-	AggTrades struct {
+	// TradeHandler викликається для кожної нової угоди під блокуванням AggTrades
+	TradeHandler func(trade *AggTrade)
+	AggTrades    struct {
 		symbol           string
 		tree             *btree.BTree
 		mu               *sync.Mutex
@@ -19,6 +21,7 @@ type (
 		isStartedStream  bool
 		startTradeStream types.StreamFunction
 		Init             types.InitFunction
+		handlers         []TradeHandler
 	}
 )
 
@@ -62,6 +65,9 @@ func (at *AggTrades) Update(val btree.Item) {
 	old := at.Get(id)
 	if old == nil {
 		at.Set(val)
+		for _, handler := range at.handlers {
+			handler(val.(*AggTrade))
+		}
 	} else {
 		at.Set(&AggTrade{
 			AggTradeID:       id,
@@ -76,6 +82,14 @@ func (at *AggTrades) Update(val btree.Item) {
 	}
 }
 
+// AddTradeHandler додає обробник нових угод, повтори вже відомих угод обробник не отримує.
+// Виклик повинен виконуватись під блокуванням AggTrades.
+func (at *AggTrades) AddTradeHandler(handler TradeHandler) {
+	if handler != nil {
+		at.handlers = append(at.handlers, handler)
+	}
+}
+
 func (at *AggTrades) Delete(id int64) {
 	at.tree.Delete(&AggTrade{AggTradeID: id})
 }
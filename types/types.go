@@ -44,6 +44,8 @@ const (
 	SideTypeBuy  OrderSide = "BUY"
 	SideTypeSell OrderSide = "SELL"
 	SideTypeNone OrderSide = "NONE"
+	// Виконання ордера в executionReport та ORDER_TRADE_UPDATE
+	OrderExecutionTypeTrade OrderExecutionType = "TRADE"
 	// SpotAccountType is a constant for spot account type.
 	// SPOT/MARGIN/ISOLATED_MARGIN/USDT_FUTURE/COIN_FUTURE
 	SpotAccountType           AccountType = "SPOT"
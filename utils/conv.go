@@ -3,6 +3,8 @@ package utils
 import (
 	"fmt"
 	"strconv"
+
+	"github.com/fr0ster/go-trading-utils/utils/decimal"
 )
 
 func ConvStrToFloat64(s string) float64 {
@@ -17,7 +19,15 @@ func ConvFloat64ToStrDefault(f float64) string {
 	return strconv.FormatFloat(f, 'f', 8, 64)
 }
 
+// ConvFloat64ToStr округлює через десяткове представлення, тому 0.285 з prec 2 дає 0.29, а не 0.28
 func ConvFloat64ToStr(f float64, prec int) string {
+	if prec >= 0 && prec <= decimal.MaxScale {
+		if d, err := decimal.NewFromFloat(f); err == nil {
+			if d, err = d.TryRescale(int32(prec), decimal.RoundHalfUp); err == nil {
+				return d.StringFixed(int32(prec))
+			}
+		}
+	}
 	return strconv.FormatFloat(f, 'f', prec, 64)
 }
 
@@ -52,6 +52,10 @@ func TestConvFloat64ToStr(t *testing.T) {
 		{10.5, 2, "10.50"},
 		{-5.2, 1, "-5.2"},
 		{0, 0, "0"},
+		{0.285, 2, "0.29"},
+		{1.005, 2, "1.01"},
+		// Поза діапазоном decimal форматується через float64
+		{2e11, 8, "200000000000.00000000"},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,530 @@
+package decimal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Максимальна кількість знаків після коми
+	MaxScale = 18
+)
+
+const (
+	// Округлення до найближчого, половина - від нуля
+	RoundHalfUp RoundingMode = iota
+	// Округлення вниз, як math.Floor
+	RoundFloor
+	// Округлення вгору, як math.Ceil
+	RoundCeil
+)
+
+var (
+	ErrOverflow       = errors.New("decimal overflow")
+	ErrSyntax         = errors.New("invalid decimal syntax")
+	ErrDivisionByZero = errors.New("decimal division by zero")
+	Zero              = Decimal{}
+
+	pow10 = [MaxScale + 1]int64{
+		1, 10, 100, 1000, 10000, 100000, 1000000, 10000000, 100000000, 1000000000,
+		10000000000, 100000000000, 1000000000000, 10000000000000, 100000000000000,
+		1000000000000000, 10000000000000000, 100000000000000000, 1000000000000000000,
+	}
+)
+
+type (
+	RoundingMode int
+	// Decimal - точне десяткове число value * 10^-scale.
+	// Діапазон обмежений int64 для value, при переповненні арифметика панікує з ErrOverflow,
+	// варіанти Try* повертають помилку замість паніки.
+	Decimal struct {
+		value int64
+		scale int32
+	}
+)
+
+// New створює число value * 10^-scale
+func New(value int64, scale int32) Decimal {
+	if scale < 0 || scale > MaxScale {
+		panic(fmt.Errorf("decimal scale %v out of range [0, %v]", scale, MaxScale))
+	}
+	return Decimal{value: value, scale: scale}
+}
+
+// NewFromInt створює ціле число
+func NewFromInt(value int64) Decimal {
+	return Decimal{value: value}
+}
+
+// Parse розбирає рядок виду "-123.4500", в тому числі рядки цін та кількостей з API Binance
+func Parse(s string) (d Decimal, err error) {
+	str := s
+	negative := false
+	if len(str) > 0 && (str[0] == '-' || str[0] == '+') {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+	intPart, fracPart, hasPoint := strings.Cut(str, ".")
+	if intPart == "" && fracPart == "" || hasPoint && strings.Contains(fracPart, ".") {
+		return Zero, fmt.Errorf("%w: %q", ErrSyntax, s)
+	}
+	// Незначущі нулі в кінці дробової частини не впливають на значення
+	fracPart = strings.TrimRight(fracPart, "0")
+	if len(fracPart) > MaxScale {
+		return Zero, fmt.Errorf("%w: %q has more than %v decimal places", ErrOverflow, s, MaxScale)
+	}
+	var value uint64
+	for _, digits := range []string{intPart, fracPart} {
+		for _, c := range digits {
+			if c < '0' || c > '9' {
+				return Zero, fmt.Errorf("%w: %q", ErrSyntax, s)
+			}
+			if value > (math.MaxInt64-uint64(c-'0'))/10 {
+				return Zero, fmt.Errorf("%w: %q", ErrOverflow, s)
+			}
+			value = value*10 + uint64(c-'0')
+		}
+	}
+	d = Decimal{value: int64(value), scale: int32(len(fracPart))}
+	if negative {
+		d.value = -d.value
+	}
+	return
+}
+
+// MustParse - Parse, що панікує при помилці, для констант
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// NewFromFloat перетворює float64 в найкоротше десяткове представлення, яке дає те саме float64,
+// тобто 0.1 стає рівно 0.1, а не 0.1000000000000000055511151231257827
+func NewFromFloat(f float64) (d Decimal, err error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Zero, fmt.Errorf("%w: %v", ErrSyntax, f)
+	}
+	s := strconv.FormatFloat(f, 'f', -1, 64)
+	if _, frac, ok := strings.Cut(s, "."); ok && len(frac) > MaxScale {
+		s = strconv.FormatFloat(f, 'f', MaxScale, 64)
+	}
+	return Parse(s)
+}
+
+// ScaledFromFloat - те саме, що NewFromFloat(f).Scaled(scale), але без алокацій,
+// для ключів дерева стакану на гарячому шляху
+func ScaledFromFloat(f float64, scale int32) (value int64, ok bool) {
+	if math.IsNaN(f) || math.IsInf(f, 0) || scale < 0 || scale > MaxScale {
+		return 0, false
+	}
+	var buffer [64]byte
+	// Буфера вистачає для всіх значень, крім найменших денормалізованих
+	digits := strconv.AppendFloat(buffer[:0], math.Abs(f), 'f', -1, 64)
+	var result uint64
+	fraction := int32(-1)
+	for _, c := range digits {
+		if c == '.' {
+			fraction = 0
+			continue
+		}
+		if fraction == scale {
+			// Перша відкинута цифра визначає округлення половини від нуля
+			if c >= '5' {
+				result++
+			}
+			break
+		}
+		if result > (math.MaxInt64-uint64(c-'0'))/10 {
+			return 0, false
+		}
+		result = result*10 + uint64(c-'0')
+		if fraction >= 0 {
+			fraction++
+		}
+	}
+	for fraction = max(fraction, 0); fraction < scale; fraction++ {
+		if result > math.MaxInt64/10 {
+			return 0, false
+		}
+		result *= 10
+	}
+	if result > math.MaxInt64 {
+		return 0, false
+	}
+	value = int64(result)
+	if f < 0 {
+		value = -value
+	}
+	return value, true
+}
+
+// Float64 - перетворення на межі з кодом, який працює з float64
+func (d Decimal) Float64() float64 {
+	if d.value < 1<<53 && d.value > -(1<<53) {
+		// Обидва операнди точні, тому ділення дає найближче float64
+		return float64(d.value) / float64(pow10[d.scale])
+	}
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String повертає число без зайвих нулів в кінці
+func (d Decimal) String() string {
+	return d.normalize().format()
+}
+
+// StringFixed повертає число рівно з scale знаками після коми, округленими RoundHalfUp
+func (d Decimal) StringFixed(scale int32) string {
+	return d.Rescale(scale, RoundHalfUp).format()
+}
+
+func (d Decimal) format() string {
+	negative := d.value < 0
+	digits := strconv.FormatUint(abs(d.value), 10)
+	if d.scale > 0 {
+		if len(digits) <= int(d.scale) {
+			digits = strings.Repeat("0", int(d.scale)-len(digits)+1) + digits
+		}
+		point := len(digits) - int(d.scale)
+		digits = digits[:point] + "." + digits[point:]
+	}
+	if negative {
+		return "-" + digits
+	}
+	return digits
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON приймає як рядок, так і число, null залишає значення без змін
+func (d *Decimal) UnmarshalJSON(data []byte) (err error) {
+	if string(data) == "null" {
+		return nil
+	}
+	s := strings.Trim(string(data), `"`)
+	*d, err = Parse(s)
+	return
+}
+
+// Scale повертає кількість знаків після коми
+func (d Decimal) Scale() int32 {
+	return d.scale
+}
+
+func (d Decimal) IsZero() bool {
+	return d.value == 0
+}
+
+// Sign повертає -1, 0 або 1
+func (d Decimal) Sign() int {
+	switch {
+	case d.value < 0:
+		return -1
+	case d.value > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Cmp повертає -1, якщо d < o, 0, якщо d == o, та 1, якщо d > o
+func (d Decimal) Cmp(o Decimal) int {
+	if x, y, _, ok := align(d, o); ok {
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		default:
+			return 0
+		}
+	}
+	return d.bigRat().Cmp(o.bigRat())
+}
+
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Cmp(o) == 0
+}
+
+func (d Decimal) LessThan(o Decimal) bool {
+	return d.Cmp(o) < 0
+}
+
+func (d Decimal) GreaterThan(o Decimal) bool {
+	return d.Cmp(o) > 0
+}
+
+func (d Decimal) Neg() Decimal {
+	if d.value == math.MinInt64 {
+		panic(ErrOverflow)
+	}
+	return Decimal{value: -d.value, scale: d.scale}
+}
+
+func (d Decimal) Abs() Decimal {
+	if d.value < 0 {
+		return d.Neg()
+	}
+	return d
+}
+
+func (d Decimal) Add(o Decimal) Decimal {
+	return must(d.TryAdd(o))
+}
+
+// TryAdd - Add, що повертає ErrOverflow замість паніки
+func (d Decimal) TryAdd(o Decimal) (Decimal, error) {
+	x, y, scale, ok := align(d, o)
+	if !ok {
+		return Zero, ErrOverflow
+	}
+	sum := x + y
+	if (sum > x) != (y > 0) {
+		return Zero, ErrOverflow
+	}
+	return Decimal{value: sum, scale: scale}, nil
+}
+
+func (d Decimal) Sub(o Decimal) Decimal {
+	return d.Add(o.Neg())
+}
+
+// Mul - точний добуток, якщо він вміщується в MaxScale знаків, інакше округлений RoundHalfUp
+func (d Decimal) Mul(o Decimal) Decimal {
+	scale := min(d.scale+o.scale, MaxScale)
+	product := new(big.Int).Mul(big.NewInt(d.value), big.NewInt(o.value))
+	return fromBig(product, d.scale+o.scale, scale, RoundHalfUp)
+}
+
+// Div ділить з точністю scale знаків після коми
+func (d Decimal) Div(o Decimal, scale int32, mode RoundingMode) Decimal {
+	return must(d.TryDiv(o, scale, mode))
+}
+
+// TryDiv - Div, що повертає помилку замість паніки при діленні на нуль,
+// scale поза [0, MaxScale] або переповненні
+func (d Decimal) TryDiv(o Decimal, scale int32, mode RoundingMode) (Decimal, error) {
+	if scale < 0 || scale > MaxScale {
+		return Zero, fmt.Errorf("decimal scale %v out of range [0, %v]", scale, MaxScale)
+	}
+	if o.value == 0 {
+		return Zero, ErrDivisionByZero
+	}
+	// d/o = (d.value * 10^(o.scale + scale)) / (o.value * 10^d.scale) * 10^-scale
+	numerator := new(big.Int).Mul(big.NewInt(d.value), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(o.scale+scale)), nil))
+	denominator := new(big.Int).Mul(big.NewInt(o.value), big.NewInt(pow10[d.scale]))
+	value, ok := divRoundBig(numerator, denominator, mode)
+	if !ok {
+		return Zero, ErrOverflow
+	}
+	return Decimal{value: value, scale: scale}, nil
+}
+
+// Rescale змінює кількість знаків після коми з округленням
+func (d Decimal) Rescale(scale int32, mode RoundingMode) Decimal {
+	return must(d.TryRescale(scale, mode))
+}
+
+// TryRescale - Rescale, що повертає помилку замість паніки
+func (d Decimal) TryRescale(scale int32, mode RoundingMode) (Decimal, error) {
+	if scale < 0 || scale > MaxScale {
+		return Zero, fmt.Errorf("decimal scale %v out of range [0, %v]", scale, MaxScale)
+	}
+	if scale >= d.scale {
+		value, ok := mulPow10(d.value, scale-d.scale)
+		if !ok {
+			return Zero, ErrOverflow
+		}
+		return Decimal{value: value, scale: scale}, nil
+	}
+	return Decimal{value: divRound(d.value, pow10[d.scale-scale], mode), scale: scale}, nil
+}
+
+// Scaled повертає число, округлене RoundHalfUp до scale знаків, як ціле value * 10^scale,
+// ok == false, якщо результат не вміщується в int64
+func (d Decimal) Scaled(scale int32) (value int64, ok bool) {
+	if scale < 0 || scale > MaxScale {
+		return 0, false
+	}
+	if scale >= d.scale {
+		return mulPow10(d.value, scale-d.scale)
+	}
+	return divRound(d.value, pow10[d.scale-scale], RoundHalfUp), true
+}
+
+// RoundToStep округлює до кратного step (tickSize ціни або stepSize кількості)
+func (d Decimal) RoundToStep(step Decimal, mode RoundingMode) Decimal {
+	return must(d.TryRoundToStep(step, mode))
+}
+
+// TryRoundToStep - RoundToStep, що повертає ErrOverflow замість паніки,
+// наприклад для 2e11 з кроком 1e-8, яке не вміщується в int64
+func (d Decimal) TryRoundToStep(step Decimal, mode RoundingMode) (Decimal, error) {
+	if step.value <= 0 {
+		return d, nil
+	}
+	x, y, scale, ok := align(d, step)
+	if !ok {
+		return Zero, ErrOverflow
+	}
+	steps := divRound(x, y, mode)
+	if steps != 0 && (steps*y)/steps != y {
+		return Zero, ErrOverflow
+	}
+	return Decimal{value: steps * y, scale: scale}, nil
+}
+
+// FloorToStep - найбільше кратне step, що не більше за d
+func (d Decimal) FloorToStep(step Decimal) Decimal {
+	return d.RoundToStep(step, RoundFloor)
+}
+
+// CeilToStep - найменше кратне step, що не менше за d
+func (d Decimal) CeilToStep(step Decimal) Decimal {
+	return d.RoundToStep(step, RoundCeil)
+}
+
+// Steps повертає кількість цілих кроків step в d (для ціни - кількість тіків)
+func (d Decimal) Steps(step Decimal) int64 {
+	if step.value <= 0 {
+		return 0
+	}
+	x, y, _, ok := align(d, step)
+	if !ok {
+		panic(ErrOverflow)
+	}
+	return divRound(x, y, RoundFloor)
+}
+
+// IsMultipleOf - чи кратне d кроку step, як вимагають фільтри PRICE_FILTER та LOT_SIZE
+func (d Decimal) IsMultipleOf(step Decimal) bool {
+	if step.value <= 0 {
+		return true
+	}
+	x, y, _, ok := align(d, step)
+	return ok && x%y == 0
+}
+
+func (d Decimal) normalize() Decimal {
+	for d.scale > 0 && d.value%10 == 0 {
+		d.value /= 10
+		d.scale--
+	}
+	return d
+}
+
+func (d Decimal) bigRat() *big.Rat {
+	return new(big.Rat).SetFrac(big.NewInt(d.value), big.NewInt(pow10[d.scale]))
+}
+
+// align приводить обидва числа до спільної точності
+func align(a, b Decimal) (x, y int64, scale int32, ok bool) {
+	scale = max(a.scale, b.scale)
+	if x, ok = mulPow10(a.value, scale-a.scale); !ok {
+		return
+	}
+	y, ok = mulPow10(b.value, scale-b.scale)
+	return
+}
+
+func mulPow10(value int64, exp int32) (int64, bool) {
+	if exp == 0 || value == 0 {
+		return value, true
+	}
+	multiplier := pow10[exp]
+	if value > math.MaxInt64/multiplier || value < math.MinInt64/multiplier {
+		return 0, false
+	}
+	return value * multiplier, true
+}
+
+func divRound(value, divisor int64, mode RoundingMode) int64 {
+	quotient, remainder := value/divisor, value%divisor
+	if remainder == 0 {
+		return quotient
+	}
+	negative := (remainder < 0) != (divisor < 0)
+	switch mode {
+	case RoundFloor:
+		if negative {
+			quotient--
+		}
+	case RoundCeil:
+		if !negative {
+			quotient++
+		}
+	default:
+		if abs(remainder) >= abs(divisor)-abs(remainder) {
+			if negative {
+				quotient--
+			} else {
+				quotient++
+			}
+		}
+	}
+	return quotient
+}
+
+func divRoundBig(numerator, denominator *big.Int, mode RoundingMode) (int64, bool) {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if remainder.Sign() != 0 {
+		negative := remainder.Sign() != denominator.Sign()
+		switch mode {
+		case RoundFloor:
+			if negative {
+				quotient.Sub(quotient, big.NewInt(1))
+			}
+		case RoundCeil:
+			if !negative {
+				quotient.Add(quotient, big.NewInt(1))
+			}
+		default:
+			twice := new(big.Int).Abs(remainder)
+			twice.Lsh(twice, 1)
+			if twice.Cmp(new(big.Int).Abs(denominator)) >= 0 {
+				if negative {
+					quotient.Sub(quotient, big.NewInt(1))
+				} else {
+					quotient.Add(quotient, big.NewInt(1))
+				}
+			}
+		}
+	}
+	if !quotient.IsInt64() {
+		return 0, false
+	}
+	return quotient.Int64(), true
+}
+
+func fromBig(value *big.Int, fromScale, toScale int32, mode RoundingMode) Decimal {
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fromScale-toScale)), nil)
+	quotient, ok := divRoundBig(value, divisor, mode)
+	if !ok {
+		panic(ErrOverflow)
+	}
+	return Decimal{value: quotient, scale: toScale}
+}
+
+func must(d Decimal, err error) Decimal {
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func abs(value int64) uint64 {
+	if value < 0 {
+		return uint64(-value)
+	}
+	return uint64(value)
+}
@@ -0,0 +1,162 @@
+package decimal_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/fr0ster/go-trading-utils/utils/decimal"
+)
+
+func TestParseAndString(t *testing.T) {
+	tests := []struct {
+		input  string
+		output string
+		scale  int32
+	}{
+		{"67100.10000000", "67100.1", 1},
+		{"0.00000001", "0.00000001", 8},
+		{"-12.50", "-12.5", 1},
+		{"100", "100", 0},
+		{"+.5", "0.5", 1},
+		{"3.", "3", 0},
+	}
+	for _, test := range tests {
+		d, err := decimal.Parse(test.input)
+		assert.Nil(t, err, test.input)
+		assert.Equal(t, test.output, d.String())
+		assert.Equal(t, test.scale, d.Scale())
+	}
+	for _, input := range []string{"", ".", "1.2.3", "1e5", "abc", "99999999999999999999"} {
+		_, err := decimal.Parse(input)
+		assert.NotNil(t, err, input)
+	}
+	assert.Equal(t, "1.50", decimal.MustParse("1.5").StringFixed(2))
+	assert.Equal(t, "0.29", decimal.MustParse("0.285").StringFixed(2))
+	assert.Equal(t, "-0.29", decimal.MustParse("-0.285").StringFixed(2))
+}
+
+func TestFloatConversion(t *testing.T) {
+	a, b := 0.1, 0.2
+	d, err := decimal.NewFromFloat(a + b)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.30000000000000004", d.String())
+	d, err = decimal.NewFromFloat(0.29)
+	assert.Nil(t, err)
+	assert.Equal(t, "0.29", d.String())
+	assert.Equal(t, 0.29, d.Float64())
+	_, err = decimal.NewFromFloat(1e30)
+	assert.NotNil(t, err)
+}
+
+func TestScaled(t *testing.T) {
+	value, ok := decimal.MustParse("67100.123456785").Scaled(8)
+	assert.True(t, ok)
+	assert.Equal(t, int64(6710012345679), value)
+	value, ok = decimal.MustParse("-1.5").Scaled(0)
+	assert.True(t, ok)
+	assert.Equal(t, int64(-2), value)
+	_, ok = decimal.MustParse("100000000000").Scaled(8)
+	assert.False(t, ok)
+
+	// Без алокацій дає те саме, що NewFromFloat + Scaled
+	for _, f := range []float64{0, 0.1 + 0.2, 0.29, 1.1, 67100.1, 0.000000015, 0.000000005, 123456789.123456789, -2.675, 1e10, 5e-324} {
+		d, err := decimal.NewFromFloat(f)
+		assert.Nil(t, err)
+		expected, expectedOk := d.Scaled(8)
+		value, ok := decimal.ScaledFromFloat(f, 8)
+		assert.Equal(t, expectedOk, ok, f)
+		assert.Equal(t, expected, value, f)
+	}
+	_, ok = decimal.ScaledFromFloat(1e30, 8)
+	assert.False(t, ok)
+	_, ok = decimal.ScaledFromFloat(math.NaN(), 8)
+	assert.False(t, ok)
+	assert.Zero(t, testing.AllocsPerRun(100, func() { decimal.ScaledFromFloat(67100.1, 8) }))
+}
+
+func TestArithmetic(t *testing.T) {
+	a := decimal.MustParse("0.1")
+	b := decimal.MustParse("0.2")
+	assert.True(t, a.Add(b).Equal(decimal.MustParse("0.3")))
+	assert.True(t, a.Sub(b).Equal(decimal.MustParse("-0.1")))
+	assert.Equal(t, "0.02", a.Mul(b).String())
+	assert.Equal(t, "8.64", decimal.MustParse("2.88").Mul(decimal.MustParse("3")).String())
+	assert.Equal(t, "0.125", decimal.MustParse("0.25").Mul(decimal.MustParse("0.5")).String())
+	// Добуток округлюється до MaxScale знаків
+	assert.Equal(t, "0.000000000000000001", decimal.MustParse("0.000000001").Mul(decimal.MustParse("0.0000000005")).String())
+	assert.Equal(t, "0.333", decimal.NewFromInt(1).Div(decimal.NewFromInt(3), 3, decimal.RoundHalfUp).String())
+	assert.Equal(t, "0.667", decimal.NewFromInt(2).Div(decimal.NewFromInt(3), 3, decimal.RoundCeil).String())
+	assert.Equal(t, "-0.667", decimal.NewFromInt(-2).Div(decimal.NewFromInt(3), 3, decimal.RoundFloor).String())
+	assert.Equal(t, -1, a.Cmp(b))
+	assert.True(t, decimal.MustParse("1.10").Equal(decimal.MustParse("1.1")))
+	assert.Panics(t, func() { decimal.New(9223372036854775807, 0).Add(decimal.NewFromInt(1)) })
+}
+
+func TestStepRounding(t *testing.T) {
+	tick := decimal.MustParse("0.1")
+	price := decimal.MustParse("67100.19")
+	assert.Equal(t, "67100.1", price.FloorToStep(tick).String())
+	assert.Equal(t, "67100.2", price.CeilToStep(tick).String())
+	assert.Equal(t, "67100.2", price.RoundToStep(tick, decimal.RoundHalfUp).String())
+	assert.Equal(t, "-67100.2", price.Neg().FloorToStep(tick).String())
+
+	// Крок, що не є степенем десяти
+	step := decimal.MustParse("0.5")
+	assert.Equal(t, "1.5", decimal.MustParse("1.74").FloorToStep(step).String())
+	assert.Equal(t, "2", decimal.MustParse("1.75").RoundToStep(step, decimal.RoundHalfUp).String())
+	assert.Equal(t, int64(3), decimal.MustParse("1.74").Steps(step))
+	assert.True(t, decimal.MustParse("2.5").IsMultipleOf(step))
+	assert.False(t, decimal.MustParse("2.51").IsMultipleOf(step))
+
+	// 0.29 * 100 у float64 дає 28.999999999999996, а кількість має лишитись 0.29
+	quantity, _ := decimal.NewFromFloat(0.29)
+	assert.Equal(t, "0.29", quantity.FloorToStep(decimal.MustParse("0.01")).String())
+}
+
+func TestOverflow(t *testing.T) {
+	large, err := decimal.NewFromFloat(2e11)
+	assert.NoError(t, err)
+	step := decimal.MustParse("0.00000001")
+	// 2e11 з кроком 1e-8 - це 2e19 кроків, більше за int64
+	_, err = large.TryRoundToStep(step, decimal.RoundHalfUp)
+	assert.ErrorIs(t, err, decimal.ErrOverflow)
+	_, err = large.TryRescale(8, decimal.RoundFloor)
+	assert.ErrorIs(t, err, decimal.ErrOverflow)
+	_, err = decimal.New(math.MaxInt64, 0).TryAdd(decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, decimal.ErrOverflow)
+	assert.Panics(t, func() { large.RoundToStep(step, decimal.RoundHalfUp) })
+
+	rounded, err := large.TryRoundToStep(decimal.MustParse("0.01"), decimal.RoundHalfUp)
+	assert.NoError(t, err)
+	assert.Equal(t, "200000000000", rounded.String())
+
+	_, err = decimal.NewFromInt(1).TryDiv(decimal.Zero, 2, decimal.RoundHalfUp)
+	assert.ErrorIs(t, err, decimal.ErrDivisionByZero)
+	_, err = decimal.NewFromInt(1).TryDiv(decimal.NewFromInt(3), decimal.MaxScale+1, decimal.RoundHalfUp)
+	assert.Error(t, err)
+	_, err = decimal.NewFromInt(1).TryDiv(decimal.NewFromInt(3), -1, decimal.RoundHalfUp)
+	assert.Error(t, err)
+	_, err = large.TryDiv(decimal.MustParse("0.000001"), 8, decimal.RoundHalfUp)
+	assert.ErrorIs(t, err, decimal.ErrOverflow)
+	assert.Panics(t, func() { decimal.NewFromInt(1).Div(decimal.NewFromInt(3), -1, decimal.RoundHalfUp) })
+}
+
+func TestJSON(t *testing.T) {
+	var value struct {
+		Price    decimal.Decimal `json:"price"`
+		Quantity decimal.Decimal `json:"quantity"`
+	}
+	assert.Nil(t, json.Unmarshal([]byte(`{"price":"67100.10","quantity":0.5}`), &value))
+	assert.Equal(t, "67100.1", value.Price.String())
+	assert.Equal(t, "0.5", value.Quantity.String())
+	data, err := json.Marshal(value)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"price":"67100.1","quantity":"0.5"}`, string(data))
+
+	// null не змінює значення
+	assert.Nil(t, json.Unmarshal([]byte(`{"price":null}`), &value))
+	assert.Equal(t, "67100.1", value.Price.String())
+}